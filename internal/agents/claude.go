@@ -1,13 +1,10 @@
 package agents
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -32,7 +29,7 @@ func (c *claudeAgent) Version() (string, error) {
 	return claudeVersion(c.ctx)
 }
 
-func (c *claudeAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, _ RunOptions) RunResults {
+func (c *claudeAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, opts RunOptions) RunResults {
 	trimmedInstructions := strings.TrimSpace(instructions)
 	if trimmedInstructions == "" {
 		return RunResults{Err: errors.New("instructions are required for claude")}
@@ -60,20 +57,11 @@ func (c *claudeAgent) Run(cwd string, llm LLMDefinition, session string, instruc
 
 	envOverride := thinkingEnvOverride(llm.ReasoningLevel)
 
-	var outputBytes []byte
-	var err error
-	if c.printer != nil {
-		outputBytes, err = c.printer.RunCommandStreaming(c.ctx, cwd, "claude", args...)
-	} else {
-		cmd := exec.CommandContext(c.ctx, "claude", args...)
-		cmd.Dir = cwd
-		if len(envOverride) > 0 {
-			cmd.Env = append(os.Environ(), envOverride...)
-		}
-		outputBytes, err = cmd.CombinedOutput()
-	}
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, append(envOverride, opts.Env...), "claude", args...)
 
-	transcript, usage, parsedSession, totalCost := parseClaudeOutput(outputBytes, model)
+	// Parse only stdout (the stream-json output); the transcript keeps stderr too.
+	_, usage, parsedSession, totalCost := parseClaudeOutput(out.Stdout, model)
+	transcript := string(out.Combined)
 
 	res := RunResults{
 		Transcript:             transcript,
@@ -83,6 +71,7 @@ func (c *claudeAgent) Run(cwd string, llm LLMDefinition, session string, instruc
 		OutputTokens:           usage.outputTokens,
 		Session:                session,
 		Cost:                   totalCost,
+		FirstOutputSeconds:     out.FirstOutput.Seconds(),
 	}
 	if res.Session == "" && parsedSession != "" {
 		res.Session = parsedSession
@@ -101,10 +90,7 @@ type claudeUsage struct {
 }
 
 func parseClaudeOutput(raw []byte, desiredModel string) (string, claudeUsage, string, float64) {
-	reader := bytes.NewReader(raw)
-	scanner := bufio.NewScanner(reader)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
+	scanner := newLineScanner(raw)
 
 	var usage claudeUsage
 	var session string
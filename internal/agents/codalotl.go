@@ -57,25 +57,18 @@ func (c *codalotlAgent) Run(cwd string, llm LLMDefinition, session string, instr
 
 	args := codalotlExecArgs(model, trimmedInstructions, opts)
 
-	var outputBytes []byte
-	var err error
-	if c.printer != nil {
-		outputBytes, err = c.printer.RunCommandStreaming(c.ctx, cwd, "codalotl", args...)
-	} else {
-		cmd := exec.CommandContext(c.ctx, "codalotl", args...)
-		cmd.Dir = cwd
-		outputBytes, err = cmd.CombinedOutput()
-	}
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, opts.Env, "codalotl", args...)
 
-	transcript, usage := parseCodalotlOutput(outputBytes)
-	cost := calculateCodexCost(model, usage.inputTokens, usage.cachedInputTokens, usage.outputTokens)
+	transcript, usage := parseCodalotlOutput(out.Combined)
+	cost := calculateCodexCost(llm, usage.inputTokens, usage.cachedInputTokens, usage.outputTokens)
 	res := RunResults{
-		Transcript:        transcript,
-		InputTokens:       usage.inputTokens,
-		CachedInputTokens: usage.cachedInputTokens,
-		OutputTokens:      usage.outputTokens,
-		Cost:              cost,
-		Session:           "",
+		Transcript:         transcript,
+		InputTokens:        usage.inputTokens,
+		CachedInputTokens:  usage.cachedInputTokens,
+		OutputTokens:       usage.outputTokens,
+		Cost:               cost,
+		Session:            "",
+		FirstOutputSeconds: out.FirstOutput.Seconds(),
 	}
 	if err != nil {
 		res.Err = err
@@ -1,8 +1,6 @@
 package agents
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -48,7 +46,7 @@ func (c *codexAgent) Version() (string, error) {
 	return codexVersion(c.ctx)
 }
 
-func (c *codexAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, _ RunOptions) RunResults {
+func (c *codexAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, opts RunOptions) RunResults {
 	trimmedInstructions := strings.TrimSpace(instructions)
 	if trimmedInstructions == "" {
 		return RunResults{Err: errors.New("instructions are required for codex")}
@@ -77,30 +75,25 @@ func (c *codexAgent) Run(cwd string, llm LLMDefinition, session string, instruct
 
 	scaleDuration := codexScaleDuration(c.ctx, cwd)
 
-	var outputBytes []byte
-	var err error
-	if c.printer != nil {
-		outputBytes, err = c.printer.RunCommandStreaming(c.ctx, cwd, "codex", args...)
-	} else {
-		cmd := exec.CommandContext(c.ctx, "codex", args...)
-		cmd.Dir = cwd
-		outputBytes, err = cmd.CombinedOutput()
-	}
-	transcript, usage, threadID := parseCodexOutput(outputBytes)
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, opts.Env, "codex", args...)
+	// Parse only stdout (the --json stream); the transcript keeps stderr too.
+	_, usage, threadID := parseCodexOutput(out.Stdout)
+	transcript := string(out.Combined)
 	nonCachedInputTokens := usage.inputTokens - usage.cachedTokens
 	if nonCachedInputTokens < 0 {
 		nonCachedInputTokens = 0
 	}
-	cost := calculateCodexCost(llm.Model, nonCachedInputTokens, usage.cachedTokens, usage.outputTokens)
+	cost := calculateCodexCost(llm, nonCachedInputTokens, usage.cachedTokens, usage.outputTokens)
 
 	result := RunResults{
-		Transcript:        transcript,
-		InputTokens:       nonCachedInputTokens,
-		CachedInputTokens: usage.cachedTokens,
-		OutputTokens:      usage.outputTokens,
-		Cost:              cost,
-		ScaleDuration:     scaleDuration,
-		Session:           session,
+		Transcript:         transcript,
+		InputTokens:        nonCachedInputTokens,
+		CachedInputTokens:  usage.cachedTokens,
+		OutputTokens:       usage.outputTokens,
+		Cost:               cost,
+		ScaleDuration:      scaleDuration,
+		Session:            session,
+		FirstOutputSeconds: out.FirstOutput.Seconds(),
 	}
 	if session == "" && threadID != "" {
 		result.Session = threadID
@@ -138,13 +131,10 @@ type codexUsage struct {
 }
 
 func parseCodexOutput(raw []byte) (string, codexUsage, string) {
-	reader := bytes.NewReader(raw)
-	scanner := bufio.NewScanner(reader)
-	// Allow long JSON lines.
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
+	scanner := newLineScanner(raw)
 
 	var usage codexUsage
+	bestFields := 0
 	var threadID string
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -158,13 +148,44 @@ func parseCodexOutput(raw []byte) (string, codexUsage, string) {
 		if threadID == "" {
 			threadID = extractThreadID(parsed)
 		}
-		if u, ok := parsed["usage"]; ok {
-			updateUsage(&usage, u)
+		for _, u := range codexUsageCandidates(parsed) {
+			// Later events report cumulative usage, so the last of the most complete shape wins.
+			if candidate, fields := parseUsage(u); fields > 0 && fields >= bestFields {
+				usage, bestFields = candidate, fields
+			}
 		}
 	}
 	return string(raw), usage, threadID
 }
 
+// codexUsageCandidates returns the token usage objects in a codex event. Codex versions have reported usage as:
+//   - {"type":"turn.completed","usage":{...}}
+//   - {"msg":{"type":"token_count","input_tokens":...}} (older exec --json)
+//   - {"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{...}}}} (and under "msg")
+func codexUsageCandidates(payload map[string]any) []any {
+	var out []any
+	add := func(m map[string]any) {
+		if u, ok := m["usage"]; ok {
+			out = append(out, u)
+		}
+		if info, ok := m["info"].(map[string]any); ok {
+			if u, ok := info["total_token_usage"]; ok {
+				out = append(out, u)
+			}
+		}
+		if typ, _ := m["type"].(string); typ == "token_count" {
+			out = append(out, m)
+		}
+	}
+	add(payload)
+	for _, key := range []string{"msg", "payload"} {
+		if inner, ok := payload[key].(map[string]any); ok {
+			add(inner)
+		}
+	}
+	return out
+}
+
 func extractThreadID(payload map[string]any) string {
 	if t, ok := payload["thread_id"].(string); ok && strings.TrimSpace(t) != "" {
 		return strings.TrimSpace(t)
@@ -178,20 +199,27 @@ func extractThreadID(payload map[string]any) string {
 	return ""
 }
 
-func updateUsage(target *codexUsage, raw any) {
+// parseUsage reads a codex usage object, returning the number of recognized fields found.
+func parseUsage(raw any) (codexUsage, int) {
+	var usage codexUsage
 	m, ok := raw.(map[string]any)
 	if !ok {
-		return
+		return usage, 0
 	}
+	fields := 0
 	if val, ok := asInt(m["input_tokens"]); ok {
-		target.inputTokens = val
+		usage.inputTokens = val
+		fields++
 	}
 	if val, ok := asInt(m["cached_input_tokens"]); ok {
-		target.cachedTokens = val
+		usage.cachedTokens = val
+		fields++
 	}
 	if val, ok := asInt(m["output_tokens"]); ok {
-		target.outputTokens = val
+		usage.outputTokens = val
+		fields++
 	}
+	return usage, fields
 }
 
 func asInt(val any) (int, bool) {
@@ -257,42 +285,94 @@ func parseCodexVersion(output string) string {
 	return ""
 }
 
-type codexPricing struct {
-	inputCostPerToken       float64
-	cachedInputCostPerToken float64
-	outputCostPerToken      float64
-}
-
-func codexPricingForModel(model string) codexPricing {
+// codexPricingForModel returns the built-in pricing for an OpenAI model.
+func codexPricingForModel(model string) Pricing {
 	trimmed := strings.TrimSpace(model)
 	switch {
 	case strings.HasPrefix(trimmed, "gpt-5.2"):
-		return codexPricing{
-			inputCostPerToken:       codexGPT52InputCostPerToken,
-			cachedInputCostPerToken: codexGPT52CachedInputCostPerToken,
-			outputCostPerToken:      codexGPT52OutputCostPerToken,
+		return Pricing{
+			InputPerToken:       codexGPT52InputCostPerToken,
+			CachedInputPerToken: codexGPT52CachedInputCostPerToken,
+			OutputPerToken:      codexGPT52OutputCostPerToken,
 		}
 	case strings.HasPrefix(trimmed, "gpt-5.1"):
-		return codexPricing{
-			inputCostPerToken:       codexGPT51InputCostPerToken,
-			cachedInputCostPerToken: codexGPT51CachedInputCostPerToken,
-			outputCostPerToken:      codexGPT51OutputCostPerToken,
+		return Pricing{
+			InputPerToken:       codexGPT51InputCostPerToken,
+			CachedInputPerToken: codexGPT51CachedInputCostPerToken,
+			OutputPerToken:      codexGPT51OutputCostPerToken,
 		}
 	default:
-		return codexPricing{
-			inputCostPerToken:       codexLegacyInputCostPerToken,
-			cachedInputCostPerToken: codexLegacyCachedInputCostPerToken,
-			outputCostPerToken:      codexLegacyOutputCostPerToken,
+		return Pricing{
+			InputPerToken:       codexLegacyInputCostPerToken,
+			CachedInputPerToken: codexLegacyCachedInputCostPerToken,
+			OutputPerToken:      codexLegacyOutputCostPerToken,
 		}
 	}
 }
 
-func calculateCodexCost(model string, nonCached, cached, output int) float64 {
-	return calculateCodexCostForPricing(codexPricingForModel(model), nonCached, cached, output)
+// pricing returns l's per-token pricing: its llms.yml costs where set, and the built-in table's for l.Model otherwise.
+func (l LLMDefinition) pricing() Pricing {
+	p := codexPricingForModel(l.Model)
+	if l.InputCost != nil {
+		p.InputPerToken = *l.InputCost / 1_000_000
+	}
+	if l.CachedInputCost != nil {
+		p.CachedInputPerToken = *l.CachedInputCost / 1_000_000
+	}
+	if l.OutputCost != nil {
+		p.OutputPerToken = *l.OutputCost / 1_000_000
+	}
+	return p
+}
+
+// hasPricing reports whether llms.yml sets any of l's costs.
+func (l LLMDefinition) hasPricing() bool {
+	return l.InputCost != nil || l.CachedInputCost != nil || l.OutputCost != nil
+}
+
+// validatePricing returns an error if any of l's costs is negative.
+func (l LLMDefinition) validatePricing() error {
+	costs := []struct {
+		key  string
+		cost *float64
+	}{{"input-cost", l.InputCost}, {"cached-input-cost", l.CachedInputCost}, {"output-cost", l.OutputCost}}
+	for _, c := range costs {
+		if c.cost != nil && *c.cost < 0 {
+			return fmt.Errorf("%s must be >= 0, got %v", c.key, *c.cost)
+		}
+	}
+	return nil
+}
+
+// Pricing is per-token USD pricing for an LLM. Input is for non-cached input tokens.
+type Pricing struct {
+	InputPerToken       float64
+	CachedInputPerToken float64
+	OutputPerToken      float64
+}
+
+// Cost returns the cost of usage at p.
+func (p Pricing) Cost(nonCached, cached, output int) float64 {
+	input, cachedInput, out := p.Breakdown(nonCached, cached, output)
+	return input + cachedInput + out
+}
+
+// Breakdown returns the cost of usage at p split by token type: non-cached input, cached input, and output.
+func (p Pricing) Breakdown(nonCached, cached, output int) (float64, float64, float64) {
+	return float64(nonCached) * p.InputPerToken, float64(cached) * p.CachedInputPerToken, float64(output) * p.OutputPerToken
+}
+
+// PricingFor returns the known pricing for the LLM named llmName, if any: LLMs with costs in llms.yml, and OpenAI gpt-5
+// family models, which have built-in pricing (the same pricing codex uses to compute cost).
+func (r *Registry) PricingFor(llmName string) (Pricing, bool) {
+	llm, ok := r.LLM(llmName)
+	if !ok || (!llm.hasPricing() && !strings.HasPrefix(strings.TrimSpace(llm.Model), "gpt-5")) {
+		return Pricing{}, false
+	}
+	return llm.pricing(), true
 }
 
-func calculateCodexCostForPricing(pricing codexPricing, nonCached, cached, output int) float64 {
-	return float64(nonCached)*pricing.inputCostPerToken +
-		float64(cached)*pricing.cachedInputCostPerToken +
-		float64(output)*pricing.outputCostPerToken
+// calculateCodexCost prices token usage for llm, preferring its llms.yml costs (see LLMDefinition.pricing).
+func calculateCodexCost(llm LLMDefinition, nonCached, cached, output int) float64 {
+	return llm.pricing().Cost(nonCached, cached, output)
 }
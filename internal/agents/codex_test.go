@@ -1,10 +1,13 @@
 package agents
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/output"
 )
 
 func TestParseCodexOutput_RawTranscriptAndMetadata(t *testing.T) {
@@ -22,6 +25,54 @@ func TestParseCodexOutput_RawTranscriptAndMetadata(t *testing.T) {
 	require.Equal(t, 7, usage.outputTokens)
 }
 
+func TestParseCodexOutput_EventShapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  codexUsage
+	}{
+		{
+			name: "turnCompleted",
+			lines: []string{
+				`{"type":"thread.started","thread_id":"t1"}`,
+				`{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":40,"output_tokens":9}}`,
+			},
+			want: codexUsage{inputTokens: 100, cachedTokens: 40, outputTokens: 9},
+		},
+		{
+			name: "legacyTokenCountMsg",
+			lines: []string{
+				`{"id":"0","msg":{"type":"token_count","input_tokens":50,"cached_input_tokens":10,"output_tokens":5,"total_tokens":55}}`,
+				`{"id":"0","msg":{"type":"agent_message","message":"done"}}`,
+			},
+			want: codexUsage{inputTokens: 50, cachedTokens: 10, outputTokens: 5},
+		},
+		{
+			name: "tokenCountTotalUsage",
+			lines: []string{
+				`{"type":"event_msg","payload":{"type":"token_count","info":null}}`,
+				`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":10,"cached_input_tokens":2,"output_tokens":1},"last_token_usage":{"input_tokens":10,"cached_input_tokens":2,"output_tokens":1}}}}`,
+				`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":30,"cached_input_tokens":12,"output_tokens":4},"last_token_usage":{"input_tokens":20,"cached_input_tokens":10,"output_tokens":3}}}}`,
+			},
+			want: codexUsage{inputTokens: 30, cachedTokens: 12, outputTokens: 4},
+		},
+		{
+			name: "prefersMostComplete",
+			lines: []string{
+				`{"type":"turn.completed","usage":{"input_tokens":70,"cached_input_tokens":20,"output_tokens":8}}`,
+				`{"type":"item.completed","usage":{"output_tokens":1}}`,
+			},
+			want: codexUsage{inputTokens: 70, cachedTokens: 20, outputTokens: 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, usage, _ := parseCodexOutput([]byte(strings.Join(tt.lines, "\n")))
+			require.Equal(t, tt.want, usage)
+		})
+	}
+}
+
 func TestParseCodexOutput_RawWhenNonJSON(t *testing.T) {
 	raw := "some non json line"
 
@@ -39,7 +90,7 @@ func TestCalculateCodexCost(t *testing.T) {
 	cached := 1_000_000
 	output := 500_000
 
-	cost := calculateCodexCost("gpt-5.1-codex", nonCached, cached, output)
+	cost := calculateCodexCost(LLMDefinition{Model: "gpt-5.1-codex"}, nonCached, cached, output)
 
 	require.InDelta(t, 7.43, cost, 1e-6)
 }
@@ -49,18 +100,72 @@ func TestCalculateCodexCost_GPT52(t *testing.T) {
 	cached := 1_000_000
 	output := 500_000
 
-	cost := calculateCodexCost("gpt-5.2", nonCached, cached, output)
+	cost := calculateCodexCost(LLMDefinition{Model: "gpt-5.2"}, nonCached, cached, output)
 
 	require.InDelta(t, 10.68, cost, 1e-6)
 }
 
 func TestCalculateCodexCostZero(t *testing.T) {
-	cost := calculateCodexCost("gpt-5.1-codex", 0, 0, 0)
+	cost := calculateCodexCost(LLMDefinition{Model: "gpt-5.1-codex"}, 0, 0, 0)
 	require.Zero(t, cost)
 }
 
+func TestCalculateCodexCost_LLMsYmlPricing(t *testing.T) {
+	cost := func(v float64) *float64 { return &v }
+
+	// All costs set: the built-in table isn't used.
+	llm := LLMDefinition{Model: "gpt-5.2", InputCost: cost(1), CachedInputCost: cost(0.5), OutputCost: cost(4)}
+	require.InDelta(t, 2+0.5+2, calculateCodexCost(llm, 2_000_000, 1_000_000, 500_000), 1e-6)
+
+	// Unset costs fall back to the built-in table (gpt-5.1: $0.13/M cached input, $10/M output).
+	llm = LLMDefinition{Model: "gpt-5.1-codex", InputCost: cost(0)}
+	require.InDelta(t, 0.13+5, calculateCodexCost(llm, 2_000_000, 1_000_000, 500_000), 1e-6)
+}
+
 func TestCodexScaleDurationFromLoginStatusOutput(t *testing.T) {
 	require.InDelta(t, 1.8, codexScaleDurationFromLoginStatusOutput("Logged in using ChatGPT\n"), 1e-9)
 	require.Zero(t, codexScaleDurationFromLoginStatusOutput("Not logged in\n"))
 	require.Zero(t, codexScaleDurationFromLoginStatusOutput(""))
 }
+
+func TestRegistryPricingFor(t *testing.T) {
+	inputCost, outputCost := 3.0, 15.0
+	reg := &Registry{LLMs: map[string]LLMDefinition{
+		"gpt-5.2-high": {Name: "gpt-5.2-high", Model: "gpt-5.2"},
+		"sonnet":       {Name: "sonnet", Model: "claude-sonnet-4-5"},
+		"priced":       {Name: "priced", Model: "some-model", InputCost: &inputCost, OutputCost: &outputCost},
+	}}
+
+	p, ok := reg.PricingFor("gpt-5.2-high")
+	require.True(t, ok)
+	require.InDelta(t, calculateCodexCost(LLMDefinition{Model: "gpt-5.2"}, 10, 20, 30), p.Cost(10, 20, 30), 1e-12)
+
+	_, ok = reg.PricingFor("sonnet")
+	require.False(t, ok)
+	p, ok = reg.PricingFor("priced")
+	require.True(t, ok)
+	require.InDelta(t, 3e-6, p.InputPerToken, 1e-15)
+	require.InDelta(t, 15e-6, p.OutputPerToken, 1e-15)
+	_, ok = reg.PricingFor("missing")
+	require.False(t, ok)
+}
+
+func TestRunHarnessCommandKeepsStderrOutOfStdout(t *testing.T) {
+	// stderr noise arrives in the middle of the JSON line.
+	script := `printf '{"thread_id":"t1","usage":{"input_tokens":5,'; sleep 0.1; printf 'progress 50%%' >&2; sleep 0.1; printf '"cached_input_tokens":1,"output_tokens":2}}\n'`
+	out, err := runHarnessCommand(context.Background(), output.NewPrinter(nil), t.TempDir(), nil, "sh", "-c", script)
+	require.NoError(t, err)
+	require.Equal(t, "progress 50%", string(out.Stderr))
+	require.Contains(t, string(out.Combined), "progress 50%")
+
+	_, usage, thread := parseCodexOutput(out.Stdout)
+	require.Equal(t, "t1", thread)
+	require.Equal(t, 5, usage.inputTokens)
+	require.Equal(t, 1, usage.cachedTokens)
+	require.Equal(t, 2, usage.outputTokens)
+
+	// Combined keeps stdout contiguous (stderr follows it), so it parses too.
+	require.Equal(t, string(out.Stdout)+string(out.Stderr), string(out.Combined))
+	_, usage, _ = parseCodexOutput(out.Combined)
+	require.Equal(t, 5, usage.inputTokens)
+}
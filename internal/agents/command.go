@@ -0,0 +1,193 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/codalotl/goagentbench/internal/output"
+)
+
+// commandAgent is the generic harness for agents.yml entries with a command template, for agent CLIs without a built-in
+// harness.
+type commandAgent struct {
+	ctx     context.Context
+	printer *output.Printer
+	def     Definition
+}
+
+func newCommandAgent(ctx context.Context, def Definition, printer *output.Printer) Agent {
+	return &commandAgent{
+		ctx:     ctx,
+		printer: printer,
+		def:     def,
+	}
+}
+
+// Version returns the version listed in agents.yml: a command agent has no way to report its installed version.
+func (c *commandAgent) Version() (string, error) {
+	return c.def.Version, nil
+}
+
+func (c *commandAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, opts RunOptions) RunResults {
+	trimmedInstructions := strings.TrimSpace(instructions)
+	if trimmedInstructions == "" {
+		return RunResults{Err: fmt.Errorf("instructions are required for %s", c.def.Name)}
+	}
+	argv, err := c.def.commandArgs(commandTemplateData{
+		Model:          strings.TrimSpace(llm.Model),
+		ReasoningLevel: llm.ReasoningLevel,
+		Instructions:   trimmedInstructions,
+		Session:        strings.TrimSpace(session),
+		Package:        strings.TrimSpace(opts.Package),
+	})
+	if err != nil {
+		return RunResults{Err: err}
+	}
+
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, opts.Env, argv[0], argv[1:]...)
+
+	// The regex was checked at registry load, so a compile error can't happen here.
+	usage, _ := parseCommandUsage(c.def.UsageRegex, out.Combined)
+	res := RunResults{
+		Transcript:             string(out.Combined),
+		InputTokens:            usage.inputTokens,
+		CachedInputTokens:      usage.cachedInputTokens,
+		WriteCachedInputTokens: usage.writeCachedInputTokens,
+		OutputTokens:           usage.outputTokens,
+		Cost:                   usage.cost,
+		Session:                session,
+		FirstOutputSeconds:     out.FirstOutput.Seconds(),
+	}
+	if err != nil {
+		res.Err = err
+	}
+	return res
+}
+
+// commandTemplateData is what a command template can reference (ex: "{{.Model}}").
+type commandTemplateData struct {
+	Model          string
+	ReasoningLevel string
+	Instructions   string
+	// Session is the session to resume, when a stage or retry continues a run ("" otherwise).
+	Session string
+	Package string
+}
+
+// commandArgs renders d's command template: each element is a text/template, and the first is the program to run.
+func (d Definition) commandArgs(data commandTemplateData) ([]string, error) {
+	if len(d.Command) == 0 {
+		return nil, errors.New("command is empty")
+	}
+	argv := make([]string, 0, len(d.Command))
+	for i, arg := range d.Command {
+		tmpl, err := template.New(fmt.Sprintf("command[%d]", i)).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		argv = append(argv, b.String())
+	}
+	if strings.TrimSpace(argv[0]) == "" {
+		return nil, errors.New("command's program (its first element) is empty")
+	}
+	return argv, nil
+}
+
+// validateCommand returns an error if d's command or usage-regex is invalid. An agent with a built-in harness can't
+// set either.
+func (d Definition) validateCommand() error {
+	if len(d.Command) == 0 {
+		if d.UsageRegex != "" {
+			return errors.New("usage-regex requires command")
+		}
+		return nil
+	}
+	if _, ok := buildAgent(context.Background(), Definition{Name: d.Name}, nil); ok {
+		return fmt.Errorf("command can't be set for %q, which has a built-in harness", d.Name)
+	}
+	if _, err := d.commandArgs(commandTemplateData{}); err != nil {
+		return fmt.Errorf("command: %w", err)
+	}
+	if _, err := compileUsageRegex(d.UsageRegex); err != nil {
+		return fmt.Errorf("usage-regex: %w", err)
+	}
+	return nil
+}
+
+// usageRegexGroups are the named groups a usage-regex may capture.
+var usageRegexGroups = []string{"input", "cached_input", "write_cached_input", "output", "cost"}
+
+// compileUsageRegex compiles a usage-regex, which must capture at least one of usageRegexGroups and no other named
+// groups. An empty pattern is nil.
+func compileUsageRegex(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	known := 0
+	for _, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(usageRegexGroups, name) {
+			return nil, fmt.Errorf("unknown group %q (expected %s)", name, strings.Join(usageRegexGroups, ", "))
+		}
+		known++
+	}
+	if known == 0 {
+		return nil, fmt.Errorf("no named groups (expected some of %s)", strings.Join(usageRegexGroups, ", "))
+	}
+	return re, nil
+}
+
+type commandUsage struct {
+	inputTokens            int
+	cachedInputTokens      int
+	writeCachedInputTokens int
+	outputTokens           int
+	cost                   float64
+}
+
+// parseCommandUsage returns the usage captured by pattern's last match in raw (like codalotl, the final usage line
+// wins). Groups that are missing or don't parse are 0.
+func parseCommandUsage(pattern string, raw []byte) (commandUsage, error) {
+	re, err := compileUsageRegex(pattern)
+	if err != nil || re == nil {
+		return commandUsage{}, err
+	}
+	matches := re.FindAllSubmatch(raw, -1)
+	if len(matches) == 0 {
+		return commandUsage{}, nil
+	}
+	m := matches[len(matches)-1]
+	var usage commandUsage
+	for i, name := range re.SubexpNames() {
+		value := strings.TrimSpace(string(m[i]))
+		switch name {
+		case "input":
+			usage.inputTokens, _ = strconv.Atoi(value)
+		case "cached_input":
+			usage.cachedInputTokens, _ = strconv.Atoi(value)
+		case "write_cached_input":
+			usage.writeCachedInputTokens, _ = strconv.Atoi(value)
+		case "output":
+			usage.outputTokens, _ = strconv.Atoi(value)
+		case "cost":
+			usage.cost, _ = strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/output"
+)
+
+func TestCommandAgentRun(t *testing.T) {
+	def := Definition{
+		Name:       "mytool",
+		Version:    "1.2.3",
+		Command:    []string{"sh", "-c", `echo "model=$1 prompt=$2"; echo "usage: in=10 cached=4 out=5 cost=\$0.25"; echo "usage: in=100 cached=40 out=50 cost=\$2.5"`, "sh", "{{.Model}}", "{{.Instructions}}"},
+		UsageRegex: `usage: in=(?P<input>\d+) cached=(?P<cached_input>\d+) out=(?P<output>\d+) cost=(?P<cost>\S+)`,
+	}
+	agent, ok := buildAgent(context.Background(), def, output.NewPrinter(nil))
+	require.True(t, ok)
+
+	version, err := agent.Version()
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", version)
+
+	res := agent.Run(t.TempDir(), LLMDefinition{Model: "m1"}, "", "  do it  ", RunOptions{})
+	require.NoError(t, res.Err)
+	require.Contains(t, res.Transcript, "model=m1 prompt=do it\n")
+	// The last usage line wins.
+	require.Equal(t, 100, res.InputTokens)
+	require.Equal(t, 40, res.CachedInputTokens)
+	require.Equal(t, 50, res.OutputTokens)
+	require.InDelta(t, 2.5, res.Cost, 1e-9)
+
+	res = agent.Run(t.TempDir(), LLMDefinition{Model: "m1"}, "", " ", RunOptions{})
+	require.EqualError(t, res.Err, "instructions are required for mytool")
+}
+
+func TestCommandArgs(t *testing.T) {
+	def := Definition{Command: []string{"mytool", "exec", "--model={{.Model}}", "{{if .Session}}--resume={{.Session}}{{end}}", "{{.Instructions}}"}}
+	argv, err := def.commandArgs(commandTemplateData{Model: "m", Instructions: "hi", Session: "s1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"mytool", "exec", "--model=m", "--resume=s1", "hi"}, argv)
+
+	_, err = Definition{Command: []string{"{{.Model}}"}}.commandArgs(commandTemplateData{})
+	require.EqualError(t, err, "command's program (its first element) is empty")
+}
+
+func TestDefinitionValidateCommand(t *testing.T) {
+	require.NoError(t, Definition{Name: "codex"}.validateCommand())
+	require.NoError(t, Definition{Name: "mytool", Command: []string{"mytool", "{{.Instructions}}"}, UsageRegex: `(?P<output>\d+)`}.validateCommand())
+
+	tests := []struct {
+		def     Definition
+		wantErr string
+	}{
+		{Definition{Name: "codex", Command: []string{"codex"}}, `command can't be set for "codex", which has a built-in harness`},
+		{Definition{Name: "mytool", UsageRegex: `(?P<input>\d+)`}, "usage-regex requires command"},
+		{Definition{Name: "mytool", Command: []string{"mytool", "{{.Modle}}"}}, "command: "},
+		{Definition{Name: "mytool", Command: []string{"mytool", "{{.Model"}}, "command: "},
+		{Definition{Name: "mytool", Command: []string{"mytool"}, UsageRegex: `(?P<tokens>\d+)`}, `usage-regex: unknown group "tokens"`},
+		{Definition{Name: "mytool", Command: []string{"mytool"}, UsageRegex: `tokens=\d+`}, "usage-regex: no named groups"},
+	}
+	for _, tc := range tests {
+		require.ErrorContains(t, tc.def.validateCommand(), tc.wantErr)
+	}
+}
+
+func TestLoadRegistry_CommandAgent(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, filepath.Join(root, "agents.yml"), "agents:\n  - name: mytool\n    version: 1.0.0\n    supports-llms: [llm-a]\n    command: [mytool, exec, \"--model={{.Model}}\", \"{{.Instructions}}\"]\n")
+	writeRegistryFile(t, filepath.Join(root, "llms.yml"), "llms:\n  - name: llm-a\n    model: m\n")
+
+	reg, err := LoadRegistry(root)
+	require.NoError(t, err)
+	def, ok := reg.Agent("mytool")
+	require.True(t, ok)
+	require.Equal(t, []string{"mytool", "exec", "--model={{.Model}}", "{{.Instructions}}"}, def.Command)
+
+	writeRegistryFile(t, filepath.Join(root, "agents.yml"), "agents:\n  - name: mytool\n    command: [mytool]\n    usage-regex: \"(\"\n")
+	_, err = LoadRegistry(root)
+	require.ErrorContains(t, err, `agent "mytool" in `)
+	require.ErrorContains(t, err, "usage-regex: error parsing regexp")
+}
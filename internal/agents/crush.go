@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/codalotl/goagentbench/internal/output"
 )
@@ -45,7 +46,7 @@ func (c *crushAgent) Version() (string, error) {
 	return crushVersion(c.ctx)
 }
 
-func (c *crushAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, _ RunOptions) RunResults {
+func (c *crushAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, opts RunOptions) RunResults {
 	trimmedInstructions := strings.TrimSpace(instructions)
 	if trimmedInstructions == "" {
 		return RunResults{Err: errors.New("instructions are required for crush")}
@@ -75,24 +76,20 @@ func (c *crushAgent) Run(cwd string, llm LLMDefinition, session string, instruct
 
 	// NOTE: -y/--yolo doesn't work. It seems run automatically enables auto-approve mode.
 	args := []string{"-D", dataDir, "run", "-q", trimmedInstructions}
-	var outputBytes []byte
-	var err error
-	if c.printer != nil {
-		outputBytes, err = c.printer.RunCommandStreaming(c.ctx, cwd, "crush", args...)
-	} else {
-		cmd := exec.CommandContext(c.ctx, "crush", args...)
-		cmd.Dir = cwd
-		outputBytes, err = cmd.CombinedOutput()
-	}
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, opts.Env, "crush", args...)
 
-	inputTokens, outputTokens, cost := crushReadLatestSessionUsage(c.ctx, cwd)
+	inputTokens, outputTokens, cost, usageErr := crushReadLatestSessionUsage(c.ctx, cwd)
+	if usageErr != nil && c.printer != nil {
+		_ = c.printer.Appf("Warning: could not read crush usage: %v", usageErr)
+	}
 
 	res := RunResults{
-		Transcript:   string(outputBytes),
-		InputTokens:  inputTokens,
-		OutputTokens: outputTokens,
-		Cost:         cost,
-		Session:      "",
+		Transcript:         string(out.Combined),
+		InputTokens:        inputTokens,
+		OutputTokens:       outputTokens,
+		Cost:               cost,
+		Session:            "",
+		FirstOutputSeconds: out.FirstOutput.Seconds(),
 	}
 	if err != nil {
 		res.Err = err
@@ -167,24 +164,49 @@ func writeCrushConfig(cwd, provider, model, reasoning string) error {
 	return os.WriteFile(path, b, 0o644)
 }
 
-func crushReadLatestSessionUsage(ctx context.Context, cwd string) (int, int, float64) {
+// crushSQLiteQuery runs query against the Crush db with a busy timeout, returning the CSV output. It's a var for tests.
+var crushSQLiteQuery = func(ctx context.Context, dbPath, query string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sqlite3", "-cmd", ".timeout 2000", "-csv", dbPath, query)
+	return cmd.CombinedOutput()
+}
+
+// crushUsageRetryDelays are the waits before each retry when the Crush db is locked or busy.
+var crushUsageRetryDelays = []time.Duration{200 * time.Millisecond, 500 * time.Millisecond, time.Second}
+
+// crushReadLatestSessionUsage reads the token usage and cost of the most recently updated session in the Crush db in cwd.
+// A missing db or an empty sessions table is no usage (zeros and a nil error). Locked/busy errors are retried with
+// backoff; the error is non-nil if the db still can't be read.
+func crushReadLatestSessionUsage(ctx context.Context, cwd string) (int, int, float64, error) {
 	dbPath := filepath.Join(cwd, ".crush", "crush.db")
 	if _, err := os.Stat(dbPath); err != nil {
-		return 0, 0, 0
+		return 0, 0, 0, nil
 	}
 
 	query := "select prompt_tokens, completion_tokens, cost from sessions order by updated_at desc limit 1;"
-	cmd := exec.CommandContext(ctx, "sqlite3", "-csv", dbPath, query)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return 0, 0, 0
+	for attempt := 0; ; attempt++ {
+		out, err := crushSQLiteQuery(ctx, dbPath, query)
+		if err == nil {
+			input, output, cost, ok := parseCrushUsageCSV(string(out))
+			if !ok {
+				return 0, 0, 0, nil
+			}
+			return input, output, cost, nil
+		}
+		err = fmt.Errorf("query %s: %w: %s", dbPath, err, strings.TrimSpace(string(out)))
+		if !isSQLiteBusy(string(out)) || attempt >= len(crushUsageRetryDelays) {
+			return 0, 0, 0, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, 0, 0, err
+		case <-time.After(crushUsageRetryDelays[attempt]):
+		}
 	}
+}
 
-	input, output, cost, ok := parseCrushUsageCSV(string(out))
-	if !ok {
-		return 0, 0, 0
-	}
-	return input, output, cost
+func isSQLiteBusy(output string) bool {
+	lower := strings.ToLower(output)
+	return strings.Contains(lower, "database is locked") || strings.Contains(lower, "database is busy") || strings.Contains(lower, "sqlite_busy")
 }
 
 func parseCrushUsageCSV(raw string) (int, int, float64, bool) {
@@ -1,10 +1,13 @@
 package agents
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -111,3 +114,55 @@ func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+func TestCrushReadLatestSessionUsageRetriesWhenLocked(t *testing.T) {
+	cwd := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(cwd, ".crush"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cwd, ".crush", "crush.db"), nil, 0o644))
+
+	origQuery, origDelays := crushSQLiteQuery, crushUsageRetryDelays
+	t.Cleanup(func() { crushSQLiteQuery, crushUsageRetryDelays = origQuery, origDelays })
+	crushUsageRetryDelays = []time.Duration{0, 0}
+
+	type response struct {
+		out string
+		err error
+	}
+	var responses []response
+	calls := 0
+	crushSQLiteQuery = func(ctx context.Context, dbPath, query string) ([]byte, error) {
+		r := responses[calls]
+		calls++
+		return []byte(r.out), r.err
+	}
+	exitErr := errors.New("exit status 5")
+	locked := response{"Runtime error near line 1: database is locked (5)", exitErr}
+
+	// Transient lock, then success.
+	responses, calls = []response{locked, {"10,20,0.5\n", nil}}, 0
+	in, out, cost, err := crushReadLatestSessionUsage(context.Background(), cwd)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.Equal(t, 10, in)
+	require.Equal(t, 20, out)
+	require.InDelta(t, 0.5, cost, 1e-9)
+
+	// No sessions yet is no usage, not an error.
+	responses, calls = []response{{"", nil}}, 0
+	in, out, _, err = crushReadLatestSessionUsage(context.Background(), cwd)
+	require.NoError(t, err)
+	require.Zero(t, in)
+	require.Zero(t, out)
+
+	// Still locked after all retries.
+	responses, calls = []response{locked, locked, locked}, 0
+	_, _, _, err = crushReadLatestSessionUsage(context.Background(), cwd)
+	require.ErrorContains(t, err, "database is locked")
+	require.Equal(t, 3, calls)
+
+	// Other errors are not retried.
+	responses, calls = []response{{"Error: no such table: sessions", exitErr}}, 0
+	_, _, _, err = crushReadLatestSessionUsage(context.Background(), cwd)
+	require.ErrorContains(t, err, "no such table")
+	require.Equal(t, 1, calls)
+}
@@ -1,8 +1,6 @@
 package agents
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -29,7 +27,7 @@ func (c *cursorAgent) Version() (string, error) {
 	return cursorAgentVersion(c.ctx)
 }
 
-func (c *cursorAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, _ RunOptions) RunResults {
+func (c *cursorAgent) Run(cwd string, llm LLMDefinition, session string, instructions string, opts RunOptions) RunResults {
 	trimmedInstructions := strings.TrimSpace(instructions)
 	if trimmedInstructions == "" {
 		return RunResults{Err: errors.New("instructions are required for cursor-agent")}
@@ -54,20 +52,18 @@ func (c *cursorAgent) Run(cwd string, llm LLMDefinition, session string, instruc
 	}
 	args = append(args, trimmedInstructions)
 
-	var outputBytes []byte
-	var err error
-	if c.printer != nil {
-		outputBytes, err = c.printer.RunCommandStreaming(c.ctx, cwd, "cursor-agent", args...)
-	} else {
-		cmd := exec.CommandContext(c.ctx, "cursor-agent", args...)
-		cmd.Dir = cwd
-		outputBytes, err = cmd.CombinedOutput()
-	}
-	transcript, parsedSession := parseCursorAgentOutput(outputBytes)
+	out, err := runHarnessCommand(c.ctx, c.printer, cwd, opts.Env, "cursor-agent", args...)
+	transcript, usage, parsedSession := parseCursorAgentOutput(out.Combined)
 
+	// cursor-agent doesn't report cost; report estimates it from the tokens if the model has pricing.
 	res := RunResults{
-		Transcript: transcript,
-		Session:    session,
+		Transcript:             transcript,
+		InputTokens:            usage.inputTokens,
+		CachedInputTokens:      usage.cacheReadTokens,
+		WriteCachedInputTokens: usage.cacheWriteTokens,
+		OutputTokens:           usage.outputTokens,
+		Session:                session,
+		FirstOutputSeconds:     out.FirstOutput.Seconds(),
 	}
 	if res.Session == "" && parsedSession != "" {
 		res.Session = parsedSession
@@ -78,13 +74,22 @@ func (c *cursorAgent) Run(cwd string, llm LLMDefinition, session string, instruc
 	return res
 }
 
-func parseCursorAgentOutput(raw []byte) (string, string) {
-	reader := bytes.NewReader(raw)
-	scanner := bufio.NewScanner(reader)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
+type cursorUsage struct {
+	inputTokens      int
+	cacheReadTokens  int
+	cacheWriteTokens int
+	outputTokens     int
+}
+
+// parseCursorAgentOutput returns the transcript, token usage, and session id from cursor-agent's stream-json output.
+// Usage comes from the final "result" event when it has one, since that covers the whole run; otherwise it's the sum of
+// the per-message usage objects seen (top-level or in an assistant message), each of which covers one turn.
+func parseCursorAgentOutput(raw []byte) (string, cursorUsage, string) {
+	scanner := newLineScanner(raw)
 
+	var usage cursorUsage
 	var session string
+	var usageFromResult bool
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
@@ -97,8 +102,55 @@ func parseCursorAgentOutput(raw []byte) (string, string) {
 		if session == "" {
 			session = extractCursorSessionID(payload)
 		}
+		if usageFromResult {
+			continue
+		}
+		u, ok := payload["usage"].(map[string]any)
+		if !ok {
+			if msg, isMap := payload["message"].(map[string]any); isMap {
+				u, ok = msg["usage"].(map[string]any)
+			}
+		}
+		if !ok {
+			continue
+		}
+		if typ, _ := payload["type"].(string); typ == "result" {
+			usage = parseCursorUsage(u)
+			usageFromResult = true
+			continue
+		}
+		usage = usage.add(parseCursorUsage(u))
+	}
+	return string(raw), usage, session
+}
+
+// add returns the sum of u and other.
+func (u cursorUsage) add(other cursorUsage) cursorUsage {
+	return cursorUsage{
+		inputTokens:      u.inputTokens + other.inputTokens,
+		cacheReadTokens:  u.cacheReadTokens + other.cacheReadTokens,
+		cacheWriteTokens: u.cacheWriteTokens + other.cacheWriteTokens,
+		outputTokens:     u.outputTokens + other.outputTokens,
+	}
+}
+
+// parseCursorUsage reads a usage object, which may use claude-style snake_case keys (ex: input_tokens,
+// cache_read_input_tokens) or camelCase ones (ex: inputTokens, cacheReadTokens).
+func parseCursorUsage(m map[string]any) cursorUsage {
+	first := func(keys ...string) int {
+		for _, key := range keys {
+			if val, ok := asInt(m[key]); ok {
+				return val
+			}
+		}
+		return 0
+	}
+	return cursorUsage{
+		inputTokens:      first("input_tokens", "inputTokens"),
+		cacheReadTokens:  first("cache_read_input_tokens", "cacheReadInputTokens", "cacheReadTokens"),
+		cacheWriteTokens: first("cache_creation_input_tokens", "cache_write_input_tokens", "cacheCreationInputTokens", "cacheWriteInputTokens", "cacheWriteTokens"),
+		outputTokens:     first("output_tokens", "outputTokens"),
 	}
-	return string(raw), session
 }
 
 func extractCursorSessionID(payload map[string]any) string {
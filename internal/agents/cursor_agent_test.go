@@ -15,19 +15,41 @@ func TestParseCursorAgentOutput_ExtractsSessionAndKeepsRawTranscript(t *testing.
 		`{"type":"thinking","subtype":"delta","text":" user","session_id":"6e9b8a14-d612-4674-a9f1-c712061927ef","timestamp_ms":1765042350670}`,
 	}, "\n")
 
-	transcript, session := parseCursorAgentOutput([]byte(raw))
+	transcript, usage, session := parseCursorAgentOutput([]byte(raw))
 
 	require.Equal(t, raw, transcript)
 	require.Equal(t, "6e9b8a14-d612-4674-a9f1-c712061927ef", session)
+	require.Equal(t, cursorUsage{}, usage)
+}
+
+func TestParseCursorAgentOutput_Usage(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"system","subtype":"init","session_id":"s1","model":"Claude 4.5 Sonnet"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Looking"}],"usage":{"input_tokens":900,"cache_read_input_tokens":100,"output_tokens":20}},"session_id":"s1"}`,
+		`{"type":"tool_call","subtype":"completed","call_id":"c1","session_id":"s1"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":1200,"cache_read_input_tokens":800,"cache_creation_input_tokens":50,"output_tokens":75}},"session_id":"s1"}`,
+		`{"type":"result","subtype":"success","is_error":false,"duration_ms":5234,"result":"Done","session_id":"s1","usage":{"inputTokens":2100,"outputTokens":95,"cacheReadTokens":900,"cacheWriteTokens":50}}`,
+		`not json`,
+	}, "\n")
+
+	_, usage, session := parseCursorAgentOutput([]byte(raw))
+
+	require.Equal(t, "s1", session)
+	require.Equal(t, cursorUsage{inputTokens: 2100, cacheReadTokens: 900, cacheWriteTokens: 50, outputTokens: 95}, usage)
+
+	// Without a result event, the per-message usage is summed.
+	_, usage, _ = parseCursorAgentOutput([]byte(strings.Join(strings.Split(raw, "\n")[:4], "\n")))
+	require.Equal(t, cursorUsage{inputTokens: 2100, cacheReadTokens: 900, cacheWriteTokens: 50, outputTokens: 95}, usage)
 }
 
 func TestParseCursorAgentOutput_FallbackSessionEmpty(t *testing.T) {
 	raw := "some plain output line"
 
-	transcript, session := parseCursorAgentOutput([]byte(raw))
+	transcript, usage, session := parseCursorAgentOutput([]byte(raw))
 
 	require.Equal(t, raw, transcript)
 	require.Empty(t, session)
+	require.Equal(t, cursorUsage{}, usage)
 }
 
 func TestParseCursorAgentVersion(t *testing.T) {
@@ -0,0 +1,53 @@
+package agents
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvVarMaxLineBytes overrides the longest agent output line (in bytes) that the output parsers read.
+const EnvVarMaxLineBytes = "GOAGENTBENCH_MAX_LINE_BYTES"
+
+// defaultMaxLineBytes is large enough for JSON events that embed big tool results.
+const defaultMaxLineBytes = 16 * 1024 * 1024
+
+// maxLineBytes returns GOAGENTBENCH_MAX_LINE_BYTES if it is a positive integer, else defaultMaxLineBytes.
+func maxLineBytes() int {
+	if v := strings.TrimSpace(os.Getenv(EnvVarMaxLineBytes)); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultMaxLineBytes
+}
+
+// newLineScanner returns a scanner over the lines of raw, as used by the agents' JSON output parsers. Lines longer than
+// maxLineBytes are skipped instead of stopping the scan with bufio.ErrTooLong, so one huge event doesn't lose the
+// usage and session data after it.
+func newLineScanner(raw []byte) *bufio.Scanner {
+	limit := maxLineBytes()
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, min(64*1024, limit)), limit)
+	skipping := false
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if skipping {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				return len(data), nil, nil
+			}
+			skipping = false
+			return i + 1, nil, nil
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance == 0 && token == nil && err == nil && len(data) >= limit {
+			// The buffer is full without a newline: drop the rest of this line.
+			skipping = true
+			return len(data), nil, nil
+		}
+		return advance, token, err
+	})
+	return scanner
+}
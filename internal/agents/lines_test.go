@@ -0,0 +1,36 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputSkipsOversizedLines(t *testing.T) {
+	t.Setenv(EnvVarMaxLineBytes, "1024")
+	huge := `{"type":"item.completed","item":{"output":"` + strings.Repeat("x", 4096) + `"}}`
+	raw := strings.Join([]string{
+		`{"thread_id":"thread-123"}`,
+		huge,
+		`{"type":"turn.completed","usage":{"input_tokens":12,"cached_input_tokens":3,"output_tokens":7}}`,
+	}, "\n")
+
+	transcript, usage, thread := parseCodexOutput([]byte(raw))
+	require.Equal(t, raw, transcript)
+	require.Equal(t, "thread-123", thread)
+	require.Equal(t, codexUsage{inputTokens: 12, cachedTokens: 3, outputTokens: 7}, usage)
+
+	// The session id only appears after the oversized line.
+	_, _, session := parseCursorAgentOutput([]byte(huge + "\n" + huge + "\n" + `{"session_id":"sess-1"}`))
+	require.Equal(t, "sess-1", session)
+}
+
+func TestMaxLineBytes(t *testing.T) {
+	t.Setenv(EnvVarMaxLineBytes, "")
+	require.Equal(t, defaultMaxLineBytes, maxLineBytes())
+	t.Setenv(EnvVarMaxLineBytes, "2048")
+	require.Equal(t, 2048, maxLineBytes())
+	t.Setenv(EnvVarMaxLineBytes, "lots")
+	require.Equal(t, defaultMaxLineBytes, maxLineBytes())
+}
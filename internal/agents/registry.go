@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -13,6 +14,12 @@ type Definition struct {
 	Name         string   `yaml:"name"`
 	Version      string   `yaml:"version"`
 	SupportsLLMs []string `yaml:"supports-llms"`
+	// Command, for agents without a built-in harness, is the argv to run the agent with (the generic command harness).
+	// Each element is a text/template over .Model, .ReasoningLevel, .Instructions, .Session, and .Package.
+	Command []string `yaml:"command"`
+	// UsageRegex optionally extracts token usage from a command agent's output: its last match's named groups input,
+	// cached_input, write_cached_input, output, and cost are recorded.
+	UsageRegex string `yaml:"usage-regex"`
 }
 
 type LLMDefinition struct {
@@ -20,6 +27,31 @@ type LLMDefinition struct {
 	Model          string            `yaml:"model"`
 	ReasoningLevel string            `yaml:"reasoning-level"`
 	PerAgent       map[string]string `yaml:"per-agent"`
+	// DisplayName is an optional friendly name that report shows instead of Name.
+	DisplayName string `yaml:"display-name"`
+	// InputCost (non-cached), CachedInputCost, and OutputCost are optional USD prices per million tokens. Each one set
+	// replaces the built-in codex pricing table's price when computing and estimating cost.
+	InputCost       *float64 `yaml:"input-cost"`
+	CachedInputCost *float64 `yaml:"cached-input-cost"`
+	OutputCost      *float64 `yaml:"output-cost"`
+
+	// ReasoningOverride is set when ReasoningLevel was replaced at run time (ex: --reasoning); never read from yml.
+	ReasoningOverride bool `yaml:"-"`
+	// AdHoc is set for LLMs loaded from an extra models file that aren't in llms.yml. Any agent may use them.
+	AdHoc bool `yaml:"-"`
+}
+
+// ReasoningLevels lists the accepted values for an LLM's reasoning-level (and the --reasoning override).
+var ReasoningLevels = []string{"low", "medium", "high", "xhigh"}
+
+// ValidateReasoningLevel returns an error unless level is one of ReasoningLevels.
+func ValidateReasoningLevel(level string) error {
+	for _, l := range ReasoningLevels {
+		if level == l {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown reasoning level %q (expected one of %s)", level, strings.Join(ReasoningLevels, ", "))
 }
 
 type registryFile struct {
@@ -35,7 +67,9 @@ type Registry struct {
 	LLMs   map[string]LLMDefinition
 }
 
-func LoadRegistry(root string) (*Registry, error) {
+// LoadRegistry loads agents.yml and llms.yml from root. Each of extraLLMPaths (ex: from --models-file) is an llms.yml-shaped
+// file whose entries are merged in order, replacing same-named LLMs.
+func LoadRegistry(root string, extraLLMPaths ...string) (*Registry, error) {
 	agentPath := filepath.Join(root, "agents.yml")
 	llmPath := filepath.Join(root, "llms.yml")
 	var af registryFile
@@ -54,14 +88,38 @@ func LoadRegistry(root string) (*Registry, error) {
 		if a.Name == "" {
 			return nil, fmt.Errorf("agent with empty name in %s", agentPath)
 		}
+		if err := a.validateCommand(); err != nil {
+			return nil, fmt.Errorf("agent %q in %s: %w", a.Name, agentPath, err)
+		}
 		reg.Agents[a.Name] = a
 	}
 	for _, l := range lf.LLMs {
 		if l.Name == "" {
 			return nil, fmt.Errorf("llm with empty name in %s", llmPath)
 		}
+		if err := l.validatePricing(); err != nil {
+			return nil, fmt.Errorf("llm %q in %s: %w", l.Name, llmPath, err)
+		}
 		reg.LLMs[l.Name] = l
 	}
+	for _, path := range extraLLMPaths {
+		var extra llmFile
+		if err := readYAML(path, &extra); err != nil {
+			return nil, err
+		}
+		for _, l := range extra.LLMs {
+			if l.Name == "" {
+				return nil, fmt.Errorf("llm with empty name in %s", path)
+			}
+			if err := l.validatePricing(); err != nil {
+				return nil, fmt.Errorf("llm %q in %s: %w", l.Name, path, err)
+			}
+			if _, ok := reg.LLMs[l.Name]; !ok {
+				l.AdHoc = true
+			}
+			reg.LLMs[l.Name] = l
+		}
+	}
 	return reg, nil
 }
 
@@ -83,6 +141,34 @@ func (r *Registry) LLM(name string) (LLMDefinition, bool) {
 	return l, ok
 }
 
+// AgentNames returns the names of the registry's agents, sorted.
+func (r *Registry) AgentNames() []string {
+	names := make([]string, 0, len(r.Agents))
+	for name := range r.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SupportedLLMs returns the LLMs agentName's supports-llms lists, in order (the first is its default), resolved for the
+// agent (so Model has any per-agent override applied). Entries missing from llms.yml are returned in missing instead.
+func (r *Registry) SupportedLLMs(agentName string) (llms []LLMDefinition, missing []string) {
+	agent, ok := r.Agent(agentName)
+	if !ok {
+		return nil, nil
+	}
+	for _, name := range agent.SupportsLLMs {
+		llm, ok := r.LLM(name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		llms = append(llms, llm.resolvedForAgent(agentName))
+	}
+	return llms, missing
+}
+
 // ValidateAgentModel ensures the agent and model exist and the model is supported.
 func (r *Registry) ValidateAgentModel(agentName, model string) (Definition, *LLMDefinition, error) {
 	agent, ok := r.Agent(agentName)
@@ -105,6 +191,10 @@ func (r *Registry) ValidateAgentModel(agentName, model string) (Definition, *LLM
 	if !ok {
 		return Definition{}, nil, fmt.Errorf("unknown model %q", model)
 	}
+	if llm.AdHoc {
+		resolved := llm.resolvedForAgent(agentName)
+		return agent, &resolved, nil
+	}
 	for _, m := range agent.SupportsLLMs {
 		if m == model {
 			resolved := llm.resolvedForAgent(agentName)
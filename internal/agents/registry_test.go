@@ -1,6 +1,8 @@
 package agents
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -64,3 +66,128 @@ func TestValidateAgentModel_PerAgentOverride(t *testing.T) {
 	require.NotNil(t, llm)
 	require.Equal(t, "agent1-model", llm.Model)
 }
+
+func TestSupportedLLMs(t *testing.T) {
+	reg := &Registry{
+		Agents: map[string]Definition{
+			"agent2": {Name: "agent2"},
+			"agent1": {Name: "agent1", SupportsLLMs: []string{"llm-b", "llm-gone", "llm-a"}},
+		},
+		LLMs: map[string]LLMDefinition{
+			"llm-a": {Name: "llm-a", Model: "model-a", PerAgent: map[string]string{"agent1": "agent1-model-a"}},
+			"llm-b": {Name: "llm-b", Model: "model-b"},
+		},
+	}
+	require.Equal(t, []string{"agent1", "agent2"}, reg.AgentNames())
+
+	llms, missing := reg.SupportedLLMs("agent1")
+	require.Len(t, llms, 2)
+	require.Equal(t, "llm-b", llms[0].Name)
+	require.Equal(t, "model-b", llms[0].Model)
+	require.Equal(t, "llm-a", llms[1].Name)
+	require.Equal(t, "agent1-model-a", llms[1].Model)
+	require.Equal(t, []string{"llm-gone"}, missing)
+
+	llms, missing = reg.SupportedLLMs("agent2")
+	require.Empty(t, llms)
+	require.Empty(t, missing)
+}
+
+func TestValidateReasoningLevel(t *testing.T) {
+	for _, level := range ReasoningLevels {
+		require.NoError(t, ValidateReasoningLevel(level))
+	}
+	require.Error(t, ValidateReasoningLevel(""))
+	require.Error(t, ValidateReasoningLevel("extreme"))
+}
+
+func TestLoadRegistry_MergesExtraLLMs(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, filepath.Join(root, "agents.yml"), "agents:\n  - name: agent1\n    supports-llms: [llm-a]\n")
+	writeRegistryFile(t, filepath.Join(root, "llms.yml"), "llms:\n  - name: llm-a\n    model: old-model\n")
+	extra := filepath.Join(t.TempDir(), "extra.yml")
+	writeRegistryFile(t, extra, "llms:\n  - name: llm-a\n    model: new-model\n  - name: llm-new\n    model: brand-new\n    reasoning-level: low\n")
+
+	reg, err := LoadRegistry(root, extra)
+	require.NoError(t, err)
+
+	_, llm, err := reg.ValidateAgentModel("agent1", "llm-a")
+	require.NoError(t, err)
+	require.Equal(t, "new-model", llm.Model)
+	require.False(t, llm.AdHoc)
+
+	// Models only defined in the extra file are usable by any agent.
+	_, llm, err = reg.ValidateAgentModel("agent1", "llm-new")
+	require.NoError(t, err)
+	require.Equal(t, "brand-new", llm.Model)
+	require.Equal(t, "low", llm.ReasoningLevel)
+
+	writeRegistryFile(t, extra, "llms:\n  - model: nameless\n")
+	_, err = LoadRegistry(root, extra)
+	require.ErrorContains(t, err, "empty name")
+}
+
+func TestLoadRegistry_Pricing(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, filepath.Join(root, "agents.yml"), "agents:\n  - name: agent1\n    supports-llms: [llm-a]\n")
+	writeRegistryFile(t, filepath.Join(root, "llms.yml"), "llms:\n  - name: llm-a\n    model: m\n    input-cost: 1.25\n    output-cost: 10\n")
+
+	reg, err := LoadRegistry(root)
+	require.NoError(t, err)
+	llm, ok := reg.LLM("llm-a")
+	require.True(t, ok)
+	require.NotNil(t, llm.InputCost)
+	require.Equal(t, 1.25, *llm.InputCost)
+	require.Nil(t, llm.CachedInputCost)
+	require.Equal(t, 10.0, *llm.OutputCost)
+
+	writeRegistryFile(t, filepath.Join(root, "llms.yml"), "llms:\n  - name: llm-a\n    model: m\n    cached-input-cost: -1\n")
+	_, err = LoadRegistry(root)
+	require.ErrorContains(t, err, `llm "llm-a"`)
+	require.ErrorContains(t, err, "cached-input-cost must be >= 0, got -1")
+
+	extra := filepath.Join(t.TempDir(), "extra.yml")
+	writeRegistryFile(t, filepath.Join(root, "llms.yml"), "llms:\n  - name: llm-a\n    model: m\n")
+	writeRegistryFile(t, extra, "llms:\n  - name: llm-b\n    model: m\n    output-cost: -2\n")
+	_, err = LoadRegistry(root, extra)
+	require.ErrorContains(t, err, "output-cost must be >= 0")
+}
+
+func writeRegistryFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestValidateRegistryFiles_RepoRegistryIsValid(t *testing.T) {
+	problems, err := ValidateRegistryFiles(findRepoRoot(t))
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
+
+func TestValidateRegistry_ReportsAllProblems(t *testing.T) {
+	problems := validateRegistry(
+		[]Definition{
+			{Name: "agent1", SupportsLLMs: []string{"llm-a", "missing", "llm-a"}},
+			{Name: "agent1"},
+			{Name: ""},
+			{Name: "crush", SupportsLLMs: []string{"llm-a"}},
+		},
+		[]LLMDefinition{
+			{Name: "llm-a", ReasoningLevel: "extreme", PerAgent: map[string]string{"ghost": "m", "agent1": " "}},
+			{Name: "llm-a"},
+			{Name: ""},
+		},
+	)
+	require.ElementsMatch(t, []string{
+		`agents.yml: duplicate agent "agent1"`,
+		`agents.yml: agent #3 has an empty name`,
+		`llms.yml: duplicate llm "llm-a"`,
+		`llms.yml: llm #3 has an empty name`,
+		`llms.yml: llm "llm-a": unknown reasoning level "extreme" (expected one of low, medium, high, xhigh)`,
+		`llms.yml: llm "llm-a": per-agent override for unknown agent "ghost"`,
+		`llms.yml: llm "llm-a": per-agent override for "agent1" has an empty model`,
+		`agents.yml: agent "agent1" lists "llm-a" in supports-llms more than once`,
+		`agents.yml: agent "agent1" supports "missing", which is not in llms.yml`,
+		`agents.yml: agent "crush" supports "llm-a", which has no Crush provider mapping`,
+	}, problems)
+}
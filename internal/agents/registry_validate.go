@@ -0,0 +1,111 @@
+package agents
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValidateRegistryFiles checks agents.yml and llms.yml in root and returns every problem found (empty or duplicate
+// names, dangling supports-llms references, unknown reasoning levels, per-agent overrides for unknown agents, invalid
+// command templates, and agent-specific requirements like Crush provider mappings). The error is non-nil only if a file
+// can't be read or parsed.
+func ValidateRegistryFiles(root string) ([]string, error) {
+	agentPath := filepath.Join(root, "agents.yml")
+	llmPath := filepath.Join(root, "llms.yml")
+	var af registryFile
+	if err := readYAML(agentPath, &af); err != nil {
+		return nil, err
+	}
+	var lf llmFile
+	if err := readYAML(llmPath, &lf); err != nil {
+		return nil, err
+	}
+	return validateRegistry(af.Agents, lf.LLMs), nil
+}
+
+func validateRegistry(agentDefs []Definition, llmDefs []LLMDefinition) []string {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	agentNames := map[string]bool{}
+	for i, a := range agentDefs {
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			addf("agents.yml: agent #%d has an empty name", i+1)
+			continue
+		}
+		if agentNames[name] {
+			addf("agents.yml: duplicate agent %q", name)
+		}
+		agentNames[name] = true
+		if err := a.validateCommand(); err != nil {
+			addf("agents.yml: agent %q: %v", name, err)
+		}
+	}
+
+	llmNames := map[string]bool{}
+	for i, l := range llmDefs {
+		name := strings.TrimSpace(l.Name)
+		if name == "" {
+			addf("llms.yml: llm #%d has an empty name", i+1)
+			continue
+		}
+		if llmNames[name] {
+			addf("llms.yml: duplicate llm %q", name)
+		}
+		llmNames[name] = true
+		if l.ReasoningLevel != "" {
+			if err := ValidateReasoningLevel(l.ReasoningLevel); err != nil {
+				addf("llms.yml: llm %q: %v", name, err)
+			}
+		}
+		if err := l.validatePricing(); err != nil {
+			addf("llms.yml: llm %q: %v", name, err)
+		}
+		for _, agentName := range sortedKeys(l.PerAgent) {
+			model := l.PerAgent[agentName]
+			if !agentNames[agentName] {
+				addf("llms.yml: llm %q: per-agent override for unknown agent %q", name, agentName)
+			}
+			if strings.TrimSpace(model) == "" {
+				addf("llms.yml: llm %q: per-agent override for %q has an empty model", name, agentName)
+			}
+		}
+	}
+
+	for _, a := range agentDefs {
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, llmName := range a.SupportsLLMs {
+			if seen[llmName] {
+				addf("agents.yml: agent %q lists %q in supports-llms more than once", name, llmName)
+			}
+			seen[llmName] = true
+			if !llmNames[llmName] {
+				addf("agents.yml: agent %q supports %q, which is not in llms.yml", name, llmName)
+			}
+			if name == "crush" {
+				if _, ok := crushProviderForLLM[llmName]; !ok {
+					addf("agents.yml: agent %q supports %q, which has no Crush provider mapping", name, llmName)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -79,10 +79,22 @@ func buildAgent(ctx context.Context, def Definition, printer *output.Printer) (A
 	case "crush":
 		return newCrushAgent(ctx, printer), true
 	default:
+		if len(def.Command) > 0 {
+			return newCommandAgent(ctx, def, printer), true
+		}
 		return nil, false
 	}
 }
 
+// runHarnessCommand runs an agent CLI in cwd with extra env entries, streaming through printer when available, and
+// returns its output. Harnesses that emit a JSON stream should parse Stdout, so stderr noise can't corrupt it.
+func runHarnessCommand(ctx context.Context, printer *output.Printer, cwd string, env []string, name string, args ...string) (output.CommandOutput, error) {
+	if printer == nil {
+		printer = output.NewPrinter(nil)
+	}
+	return printer.RunCommandStreamingSplit(ctx, cwd, env, name, args...)
+}
+
 func runResultsToProgress(modelName string, rc RunContext, started time.Time, ended time.Time, results RunResults) *types.RunProgress {
 	promptTokens := results.InputTokens + results.CachedInputTokens + results.WriteCachedInputTokens
 	completionTokens := results.OutputTokens
@@ -120,7 +132,8 @@ func runResultsToProgress(modelName string, rc RunContext, started time.Time, en
 			Total:            promptTokens + completionTokens,
 			Cost:             results.Cost,
 		},
-		Transcripts: transcripts,
+		Transcripts:        transcripts,
+		FirstOutputSeconds: results.FirstOutputSeconds,
 	}
 	if results.Err != nil {
 		progress.Notes = strings.TrimSpace(results.Err.Error())
@@ -4,6 +4,9 @@ type RunOptions struct {
 	// Package is an optional Go package path (relative to the workspace root)
 	// that an agent may use to scope work (ex: "internal/cli").
 	Package string
+
+	// Env holds extra KEY=VALUE entries added to the agent subprocess environment (ex: proxy settings).
+	Env []string
 }
 
 // RunResults contains the details returned by an Agent Run invocation.
@@ -23,6 +26,9 @@ type RunResults struct {
 	// A better solution might be to just not use ChatGPT Pro's auth, but that would cost more money/time to run.
 	ScaleDuration float64
 
+	// FirstOutputSeconds is the time until the agent CLI first printed anything (0 if unknown).
+	FirstOutputSeconds float64
+
 	// If an agent supports it, this is the session ID (or resume ID). We can pass this ID to future Run calls to continue.
 	Session string
 
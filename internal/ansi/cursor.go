@@ -0,0 +1,12 @@
+package ansi
+
+// Cursor and screen control sequences.
+const (
+	// ClearScreen moves the cursor to the top-left corner and clears the screen.
+	ClearScreen = "\x1b[H\x1b[2J"
+)
+
+// StdoutIsTTY reports whether stdout is a terminal (and not running under CI), ie whether cursor control is meaningful.
+func StdoutIsTTY() bool {
+	return stdoutIsTTY()
+}
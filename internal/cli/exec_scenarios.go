@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
+	"github.com/codalotl/goagentbench/internal/workspace"
+)
+
+// execOutcome is how one scenario's exec pipeline ended, when it didn't error.
+type execOutcome struct {
+	// report is the last attempt's verification report (nil if verify didn't run).
+	report *types.VerificationReport
+	// skipped means --resume-sweep found the combo already completed, as run runID.
+	skipped bool
+	runID   string
+}
+
+// execScenarioNames returns exec's scenarios: args, then the lines of scenariosFile (if set), cleaned.
+func execScenarioNames(args []string, scenariosFile string) ([]string, error) {
+	raw := append([]string{}, args...)
+	if scenariosFile != "" {
+		f, err := os.Open(scenariosFile)
+		if err != nil {
+			return nil, fmt.Errorf("--scenarios-file: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			raw = append(raw, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("--scenarios-file: %w", err)
+		}
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("exec needs at least one scenario (as arguments or with --scenarios-file)")
+	}
+	names := make([]string, 0, len(raw))
+	for _, r := range raw {
+		name, err := workspace.CleanScenario(r)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// expandMatrixScenarios replaces each of names whose scenario has a matrix with its instances, named
+// <scenario>@<instance ID>, in ExpandMatrix order. Names that are already instances, or whose scenario.yml doesn't load
+// (the pipeline reports that), are kept as is.
+func expandMatrixScenarios(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, instanceID := workspace.SplitInstance(name); instanceID != "" {
+			out = append(out, name)
+			continue
+		}
+		sc, err := scenario.Load(workspace.ScenarioFile(name))
+		if err != nil || len(sc.Matrix) == 0 {
+			out = append(out, name)
+			continue
+		}
+		instances, err := sc.ExpandMatrix()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, inst := range instances {
+			out = append(out, name+workspace.InstanceSep+inst.ID())
+		}
+	}
+	return out, nil
+}
+
+// execSummaryRow is one scenario's line in the multi-scenario exec summary.
+type execSummaryRow struct {
+	scenario string
+	outcome  execOutcome
+	err      error
+}
+
+// failed reports whether the scenario errored or failed verification (what --fail-fast stops on).
+func (r execSummaryRow) failed() bool {
+	return r.err != nil || r.outcome.report != nil && !r.outcome.report.Success
+}
+
+// result returns the row's RESULT, RUN, and NOTE columns.
+func (r execSummaryRow) result() (string, string, string) {
+	switch {
+	case r.err != nil:
+		return "error", "", strings.Join(strings.Fields(r.err.Error()), " ")
+	case r.outcome.skipped:
+		return "skipped", r.outcome.runID, "already completed (--resume-sweep)"
+	case r.outcome.report == nil:
+		return "not verified", "", ""
+	case r.outcome.report.Success:
+		return "pass", r.outcome.report.RunID, ""
+	default:
+		return "fail", r.outcome.report.RunID, ""
+	}
+}
+
+// execSummary renders the table printed after a multi-scenario exec: one row per scenario in scenarioNames (those
+// never reached, because of --fail-fast or cancellation, are "not run" with notRunNote), then the totals.
+func execSummary(scenarioNames []string, rows []execSummaryRow, notRunNote string) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCENARIO\tRESULT\tRUN\tNOTE")
+	counts := map[string]int{}
+	for i, name := range scenarioNames {
+		result, runID, note := "not run", "", notRunNote
+		if i < len(rows) {
+			result, runID, note = rows[i].result()
+		}
+		counts[result]++
+		fmt.Fprintln(tw, name+"\t"+result+"\t"+runID+"\t"+note)
+	}
+	_ = tw.Flush()
+	// Rows without a note would end in padding.
+	table := b.String()
+	b.Reset()
+	for _, line := range strings.SplitAfter(table, "\n") {
+		b.WriteString(strings.TrimRight(line, " \n"))
+		if strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	var totals []string
+	for _, result := range []string{"pass", "fail", "error", "skipped", "not verified", "not run"} {
+		if counts[result] > 0 {
+			totals = append(totals, fmt.Sprintf("%d %s", counts[result], result))
+		}
+	}
+	fmt.Fprintf(&b, "%d scenario(s): %s", len(scenarioNames), strings.Join(totals, ", "))
+	return b.String()
+}
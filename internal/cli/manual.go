@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/types"
+	"github.com/codalotl/goagentbench/internal/workspace"
+)
+
+// manualCompletion is what the user reports about a manually run agent (run-agent --manual-complete).
+type manualCompletion struct {
+	// Duration is the agent's run time. Zero means the time since the run started.
+	Duration time.Duration
+	// Tokens is the manually entered token usage; Total is computed.
+	Tokens types.TokenUsage
+}
+
+// completeManualRun finalizes a run started with run-agent --only-start: it writes .run-progress.json from
+// .run-start.json with an end time, duration, and mc's token usage, so verify and report treat it like any other run.
+func completeManualRun(printer *output.Printer, workspacePath, scenarioName string, mc manualCompletion, now time.Time) error {
+	workspaceDir := workspace.WorkspaceScenarioDir(workspacePath, scenarioName)
+	runStartPath := filepath.Join(workspaceDir, ".run-start.json")
+	runProgressPath := filepath.Join(workspaceDir, ".run-progress.json")
+	data, err := os.ReadFile(runStartPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no run started at %s; run run-agent --only-start first", runStartPath)
+		}
+		return err
+	}
+	var start types.RunStart
+	if err := json.Unmarshal(data, &start); err != nil {
+		return fmt.Errorf("parse %s: %w", runStartPath, err)
+	}
+	if _, err := os.Stat(runProgressPath); err == nil {
+		return fmt.Errorf("run already recorded at %s", runProgressPath)
+	}
+	if mc.Duration < 0 {
+		return fmt.Errorf("--duration must be >= 0, got %s", mc.Duration)
+	}
+	duration := mc.Duration
+	if duration == 0 {
+		duration = now.Sub(start.StartedAt)
+	}
+	tokens := mc.Tokens
+	if tokens.Input < 0 || tokens.CachedInput < 0 || tokens.WriteCachedInput < 0 || tokens.Output < 0 || tokens.Cost < 0 {
+		return errors.New("token counts and --cost must be >= 0")
+	}
+	tokens.Total = tokens.Input + tokens.CachedInput + tokens.WriteCachedInput + tokens.Output
+	ended := start.StartedAt.Add(duration)
+	progress := types.RunProgress{
+		RunID:           start.RunID,
+		Scenario:        start.Scenario,
+		Agent:           start.Agent,
+		AgentVersion:    start.AgentVersion,
+		Model:           start.Model,
+		ReasoningLevel:  start.ReasoningLevel,
+		StartedAt:       start.StartedAt,
+		UpdatedAt:       now,
+		EndedAt:         &ended,
+		DurationSeconds: duration.Seconds(),
+		TokenUsage:      tokens,
+	}
+	if err := writeJSON(runProgressPath, progress); err != nil {
+		return err
+	}
+	return printer.Appf("Wrote %s (duration %s, %d tokens).", runProgressPath, duration.Round(time.Second), tokens.Total)
+}
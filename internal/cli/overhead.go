@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/codalotl/goagentbench/internal/agents"
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// overheadInstructions is the no-op prompt sent by run-agent --measure-overhead.
+const overheadInstructions = "This is a connectivity check. Reply with just \"ok\": do not read or change any files or run any commands."
+
+// measureOverhead runs the agent once with overheadInstructions, in a fresh session, and returns the turn's token usage:
+// roughly what the agent spends before doing any work (system prompt, tool definitions, and so on). rc's Instructions and
+// Session are ignored, and the turn runs in an empty temporary dir instead of rc.ScenarioPath, so nothing it writes
+// (files, or agent state such as sessions) ends up in the graded workspace or in the run's usage.
+func measureOverhead(ctx context.Context, printer *output.Printer, rc agents.RunContext) (_ *types.TokenUsage, err error) {
+	if err := printer.Appf("Measuring agent overhead with a no-op prompt (agent %s, model=%s)", rc.Agent.Name, rc.ModelName); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "goagentbench-overhead-")
+	if err != nil {
+		return nil, fmt.Errorf("measure overhead: %w", err)
+	}
+	defer func() { err = errors.Join(err, os.RemoveAll(dir)) }()
+	rc.ScenarioPath = dir
+	rc.Instructions = overheadInstructions
+	rc.Session = ""
+	outcome, err := agentRunner(ctx, rc)
+	if err != nil {
+		return nil, fmt.Errorf("measure overhead: %w", err)
+	}
+	if outcome == nil || outcome.Progress == nil {
+		return nil, fmt.Errorf("measure overhead: agent runner returned no progress")
+	}
+	usage := outcome.Progress.TokenUsage
+	usage.Total = usage.Input + usage.CachedInput + usage.WriteCachedInput + usage.Output
+	if err := printer.Appf("Agent overhead: %d tokens, cost %s.", usage.Total, types.FormatFloat(usage.Cost)); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
@@ -3,12 +3,17 @@ package cli
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	agentspkg "github.com/codalotl/goagentbench/internal/agents"
+	"github.com/codalotl/goagentbench/internal/ansi"
 	"github.com/codalotl/goagentbench/internal/report"
 )
 
@@ -20,13 +25,75 @@ func newReportCmd() *cobra.Command {
 	var after string
 	var allAgentVersions bool
 	var includeTokens bool
+	var includeLinesChanged bool
+	var includeSetupTime bool
+	var includeFirstOutput bool
+	var includeTranscriptSize bool
+	var costBreakdown bool
+	var includeErrors bool
 	var publish bool
+	var summary bool
+	var explain bool
+	var indexPath string
+	var sinceRun string
+	var priorReport string
+	var flakiness bool
+	var minSuccessRate float64
+	var maxSuccessRate float64
+	var format string
+	var watch bool
+	var watchInterval time.Duration
+	var resultsDirs []string
+	var dryRun bool
+	var byPlatform bool
+	var includeNetCost bool
+	var includeLatency bool
+	var includeNetwork bool
+	var currency string
+	var currencyRate float64
 
 	cmd := silenceUsageAndErrors(&cobra.Command{
 		Use:   "report",
 		Short: "Aggregate results into a CSV report",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if flakiness && publish {
+				return fmt.Errorf("--flakiness cannot be combined with --publish")
+			}
+			switch format {
+			case "csv":
+			case "html", "json", "ndjson":
+				if flakiness {
+					return fmt.Errorf("--format=%s cannot be combined with --flakiness", format)
+				}
+				if format == "ndjson" && publish {
+					return fmt.Errorf("--format=ndjson cannot be combined with --publish")
+				}
+			default:
+				return fmt.Errorf("invalid --format %q (expected csv, html, json, or ndjson)", format)
+			}
+			if format == "ndjson" && sinceRun != "" {
+				return fmt.Errorf("--format=ndjson cannot be combined with --since-run")
+			}
+			if watch && (publish || explain || format != "csv") {
+				return fmt.Errorf("--watch cannot be combined with --publish, --explain, or --format=%s", format)
+			}
+			if byPlatform && (publish || flakiness) {
+				return fmt.Errorf("--by-platform cannot be combined with --publish or --flakiness")
+			}
+			if dryRun && (publish || watch) {
+				return fmt.Errorf("--dry-run cannot be combined with --publish or --watch")
+			}
+			cur := report.Currency{Code: strings.ToUpper(strings.TrimSpace(currency)), PerUSD: currencyRate}
+			if !cur.IsUSD() && currencyRate <= 0 {
+				return fmt.Errorf("--currency=%s requires a positive --currency-rate (%s per USD)", cur.Code, cur.Code)
+			}
+			if cur.IsUSD() && cmd.Flags().Changed("currency-rate") {
+				return fmt.Errorf("--currency-rate needs a --currency other than USD (costs are recorded in USD)")
+			}
+			if watchInterval <= 0 {
+				return fmt.Errorf("--watch-interval must be positive, got %s", watchInterval)
+			}
 			rootDir, _ := os.Getwd()
 			var afterTime *time.Time
 			if strings.TrimSpace(after) != "" {
@@ -37,21 +104,96 @@ func newReportCmd() *cobra.Command {
 				afterTime = &parsed
 			}
 
-			rep, err := report.Run(report.Options{
-				RootPath:         rootDir,
-				Scenarios:        splitCommaList(scenarios),
-				Agents:           splitCommaList(agents),
-				Models:           splitCommaList(models),
-				Limit:            limit,
-				After:            afterTime,
-				AllAgentVersions: allAgentVersions,
-				IncludeTokens:    includeTokens,
-			})
+			var minRate, maxRate *float64
+			if cmd.Flags().Changed("min-success-rate") {
+				minRate = &minSuccessRate
+			}
+			if cmd.Flags().Changed("max-success-rate") {
+				maxRate = &maxSuccessRate
+			}
+
+			opts := report.Options{
+				RootPath:              rootDir,
+				Scenarios:             splitCommaList(scenarios),
+				Agents:                splitCommaList(agents),
+				Models:                splitCommaList(models),
+				Limit:                 limit,
+				After:                 afterTime,
+				AllAgentVersions:      allAgentVersions,
+				IncludeTokens:         includeTokens,
+				IncludeLinesChanged:   includeLinesChanged,
+				IncludeSetupTime:      includeSetupTime,
+				IncludeFirstOutput:    includeFirstOutput,
+				IncludeTranscriptSize: includeTranscriptSize,
+				CostBreakdown:         costBreakdown,
+				IncludeErrors:         includeErrors,
+				IncludeNetCost:        includeNetCost,
+				IncludeLatency:        includeLatency,
+				IncludeNetwork:        includeNetwork,
+				Summary:               summary,
+				MinSuccessRate:        minRate,
+				MaxSuccessRate:        maxRate,
+				Pricing:               reportPricing(rootDir),
+				DisplayNames:          reportDisplayNames(rootDir),
+				Explain:               explain,
+				IndexPath:             indexPath,
+				SinceRunID:            strings.TrimSpace(sinceRun),
+				PriorReportPath:       priorReport,
+				Flakiness:             flakiness,
+				ResultsDirs:           resultsDirs,
+				ByPlatform:            byPlatform,
+				Currency:              cur,
+			}
+			if dryRun {
+				return writeDryRun(os.Stderr, rootDir, opts)
+			}
+			if watch {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return watchReport(ctx, os.Stdout, ansi.StdoutIsTTY(), watchInterval, func() ([]byte, error) {
+					rep, err := report.Run(opts)
+					if err != nil {
+						return nil, err
+					}
+					var buf bytes.Buffer
+					if flakiness {
+						err = rep.WriteFlakinessCSV(&buf)
+					} else {
+						err = rep.WriteCSV(&buf)
+					}
+					return buf.Bytes(), err
+				})
+			}
+			rep, err := report.Run(opts)
 			if err != nil {
 				return err
 			}
+			if explain {
+				if err := rep.WriteExplain(os.Stderr); err != nil {
+					return err
+				}
+			}
+			if format == "ndjson" {
+				// Written straight to stdout: exports can be large.
+				return rep.WriteNDJSON(os.Stdout)
+			}
 			var buf bytes.Buffer
-			if err := rep.WriteCSV(&buf); err != nil {
+			if flakiness {
+				if err := rep.WriteFlakinessCSV(&buf); err != nil {
+					return err
+				}
+				_, err := os.Stdout.Write(buf.Bytes())
+				return err
+			}
+			switch format {
+			case "html":
+				err = rep.WriteHTML(&buf, time.Now())
+			case "json":
+				err = rep.WriteJSON(&buf)
+			default:
+				err = rep.WriteCSV(&buf)
+			}
+			if err != nil {
 				return err
 			}
 			if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
@@ -73,11 +215,88 @@ func newReportCmd() *cobra.Command {
 	cmd.Flags().StringVar(&after, "after", "", "only include results on/after YYYY-MM-DD (local time)")
 	cmd.Flags().BoolVar(&allAgentVersions, "all-agent-versions", false, "include all agent versions (default: only newest)")
 	cmd.Flags().BoolVar(&includeTokens, "include-tokens", false, "include token columns in output")
+	cmd.Flags().BoolVar(&includeLinesChanged, "include-lines-changed", false, "include avg_lines_changed column in output")
+	cmd.Flags().BoolVar(&includeSetupTime, "include-setup-time", false, "include avg_setup_time column in output")
+	cmd.Flags().BoolVar(&costBreakdown, "cost-breakdown", false, "include avg_input_cost, avg_cached_input_cost, and avg_output_cost columns (from model pricing)")
+	cmd.Flags().BoolVar(&includeFirstOutput, "include-first-output", false, "include avg_first_output column (agent time to first output) in output")
+	cmd.Flags().BoolVar(&includeTranscriptSize, "include-transcript-size", false, "include avg_transcript_bytes and avg_transcript_lines columns (agent output size) in output")
+	cmd.Flags().BoolVar(&includeNetCost, "include-net-cost", false, "include avg_cost_net column (cost minus the overhead measured by run-agent --measure-overhead)")
+	cmd.Flags().BoolVar(&includeLatency, "include-latency", false, "include median_time and p90_time columns in output")
+	cmd.Flags().BoolVar(&includeNetwork, "include-network", false, "include network_hosts column (hosts contacted by runs with agent.record-network) in output")
+	cmd.Flags().BoolVar(&includeErrors, "include-errors", false, "include errors (runs with agent error notes) and top_error columns in output")
+	cmd.Flags().StringVar(&currency, "currency", "USD", "currency to show cost columns in (ex: EUR); costs are recorded in USD and converted with --currency-rate")
+	cmd.Flags().Float64Var(&currencyRate, "currency-rate", 0, "with --currency, units of that currency per USD (ex: 0.92)")
+	cmd.Flags().BoolVar(&byPlatform, "by-platform", false, "group rows by the os/arch the agent ran on too, adding a platform column")
+	cmd.Flags().BoolVar(&summary, "summary", false, "append a final ALL row with totals across all rows")
+	cmd.Flags().Float64Var(&minSuccessRate, "min-success-rate", 0, "only include rows with success_rate >= this value (0-1)")
+	cmd.Flags().Float64Var(&maxSuccessRate, "max-success-rate", 1, "only include rows with success_rate <= this value (0-1)")
+	cmd.Flags().StringSliceVar(&resultsDirs, "results-dir", nil, "results dir to read (comma-separated or repeatable; results are merged; default: the results dir)")
+	cmd.Flags().StringVar(&indexPath, "index", "", "cache parsed results in this file; only new/changed result files are parsed")
+	cmd.Flags().StringVar(&sinceRun, "since-run", "", "with --prior-report, merge only the results verified after the result with this run id into the prior report")
+	cmd.Flags().StringVar(&priorReport, "prior-report", "", "with --since-run, a --format=json report to merge the newer results into")
+	cmd.Flags().BoolVar(&flakiness, "flakiness", false, "output {scenario,agent,model} combos with mixed pass/fail outcomes instead of the report")
+	cmd.Flags().StringVar(&format, "format", "csv", "output format: csv, html (self-contained page with a sortable table), json (the rows as one JSON document), or ndjson (one JSON object per selected result)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "re-render the report every --watch-interval until Ctrl-C (prints once when stdout is not a terminal)")
+	cmd.Flags().DurationVar(&watchInterval, "watch-interval", defaultWatchInterval, "with --watch, how often to recompute the report")
+	cmd.Flags().BoolVar(&explain, "explain", false, "print how each row's results were selected to stderr")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the result files matching --scenarios/--agents/--models/--after to stderr, without building the report")
 	cmd.Flags().BoolVar(&publish, "publish", false, "publish report summary to result_summaries and update README.md")
+	cmd.AddCommand(newReportDiffCmd())
+	cmd.AddCommand(newReportImportCmd())
 
 	return cmd
 }
 
+// writeDryRun lists the result files report.MatchingFiles finds for opts (relative to rootDir when possible), then
+// their count.
+func writeDryRun(w io.Writer, rootDir string, opts report.Options) error {
+	files, err := report.MatchingFiles(opts)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if rel, err := filepath.Rel(rootDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		if _, err := fmt.Fprintln(w, path); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "%d result file(s) match (dedup, version selection, and --limit not applied)\n", len(files))
+	return err
+}
+
+// reportPricing returns known per-model pricing for estimating missing costs. The registry is optional for report, so
+// any load error just disables estimation.
+func reportPricing(rootDir string) map[string]agentspkg.Pricing {
+	registry, err := agentspkg.LoadRegistry(rootDir)
+	if err != nil {
+		return nil
+	}
+	pricing := map[string]agentspkg.Pricing{}
+	for name := range registry.LLMs {
+		if p, ok := registry.PricingFor(name); ok {
+			pricing[name] = p
+		}
+	}
+	return pricing
+}
+
+// reportDisplayNames maps LLM names to their llms.yml display-name, for LLMs that have one.
+func reportDisplayNames(rootDir string) map[string]string {
+	registry, err := agentspkg.LoadRegistry(rootDir)
+	if err != nil {
+		return nil
+	}
+	names := map[string]string{}
+	for name, llm := range registry.LLMs {
+		if d := strings.TrimSpace(llm.DisplayName); d != "" {
+			names[name] = d
+		}
+	}
+	return names
+}
+
 func splitCommaList(value string) []string {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codalotl/goagentbench/internal/report"
+)
+
+func newReportDiffCmd() *cobra.Command {
+	return silenceUsageAndErrors(&cobra.Command{
+		Use:   "diff <summaryA> <summaryB>",
+		Short: "Compare two published report summaries",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, _ := os.Getwd()
+			var summaries [2][]report.SummaryRow
+			for i, arg := range args {
+				rows, err := report.LoadSummary(resolveSummaryDir(rootDir, arg))
+				if err != nil {
+					return err
+				}
+				summaries[i] = rows
+			}
+			return report.WriteDiff(os.Stdout, report.DiffSummaries(summaries[0], summaries[1]))
+		},
+	})
+}
+
+// resolveSummaryDir returns arg if it's a directory, otherwise result_summaries/arg (so "summary_<stamp>" works).
+func resolveSummaryDir(rootDir, arg string) string {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return arg
+	}
+	candidate := filepath.Join(rootDir, "result_summaries", arg)
+	if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+		return candidate
+	}
+	return arg
+}
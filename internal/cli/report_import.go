@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codalotl/goagentbench/internal/results"
+)
+
+func newReportImportCmd() *cobra.Command {
+	return silenceUsageAndErrors(&cobra.Command{
+		Use:   "import <file.ndjson>",
+		Short: "Import verification reports from an NDJSON file into the results dir",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, _ := os.Getwd()
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			dir := results.Dir(rootDir)
+			stats, err := results.Import(f, results.NewFSStore(dir))
+			for _, p := range stats.Problems {
+				fmt.Fprintf(cmd.ErrOrStderr(), "skipped %s\n", p)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d record(s) into %s; skipped %d malformed record(s) and %d already in the results\n",
+				stats.Imported, dir, stats.Skipped, stats.Existing)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/report"
+	"github.com/codalotl/goagentbench/internal/results"
+)
+
+func TestReportImport(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	t.Setenv(results.EnvVar, "")
+	batch := strings.Join([]string{
+		`{"schema_version":1,"run_id":"ext_1","scenario":"demo","agent":"other-agent","agent_version":"1.0.0","model":"m1","verified_at":"2025-12-03T10:00:00Z","success":true,"tests":[{"name":"./...","passed":true}],"progress":{"duration_seconds":30,"transcripts":["long transcript"]}}`,
+		`{"schema_version":1,"run_id":"ext_2","scenario":"other","agent":"other-agent","agent_version":"1.0.0","model":"m1","verified_at":"2025-12-04T10:00:00Z","success":false,"tests":[{"name":"./...","passed":false}],"progress":{"duration_seconds":50}}`,
+		``,
+		`{"schema_version":1,"run_id":"ext_3","scenario":"demo","agent":"other-agent","verified_at":"2025-12-04T10:00:00Z"}`,
+		`{"schema_version":1,"run_id":"ext_4","scenario":"demo","agent":"other-agent","model":"m1","verified_at":"2025-12-04T10:00:00Z","score":1}`,
+		`not json`,
+	}, "\n")
+	batchPath := filepath.Join(t.TempDir(), "batch.ndjson")
+	require.NoError(t, os.WriteFile(batchPath, []byte(batch), 0o644))
+
+	var out, errOut bytes.Buffer
+	cmd := newReportImportCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{batchPath})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, "Imported 2 record(s) into "+filepath.Join(root, "results")+"; skipped 3 malformed record(s) and 0 already in the results\n", out.String())
+	require.Contains(t, errOut.String(), "skipped line 4: missing model\n")
+	require.Contains(t, errOut.String(), `skipped line 5: json: unknown field "score"`)
+	require.Contains(t, errOut.String(), "skipped line 6: ")
+
+	written, err := os.ReadFile(filepath.Join(root, "results", "demo", "2025-12-03-ext_1-other-agent-m1.verify.json"))
+	require.NoError(t, err)
+	require.NotContains(t, string(written), "long transcript")
+
+	// Report counts the latest result per scenario, so the two records are in different scenarios.
+	rep, err := report.Run(report.Options{RootPath: root})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	row := rep.Rows[0]
+	require.Equal(t, "other-agent", row.Agent)
+	require.Equal(t, "m1", row.Model)
+	require.Equal(t, 2, row.Count)
+	require.Equal(t, 1, row.Success)
+	require.Equal(t, 40.0, row.AvgTimeSeconds)
+
+	// Importing the batch again doesn't replace the stored results.
+	out.Reset()
+	errOut.Reset()
+	cmd = newReportImportCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{batchPath})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, "Imported 0 record(s) into "+filepath.Join(root, "results")+"; skipped 3 malformed record(s) and 2 already in the results\n", out.String())
+	require.Contains(t, errOut.String(), `skipped line 1: run_id "ext_1" is already in the results`)
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,6 +30,10 @@ func publishReport(rootDir string, rep *report.Report, command string, at time.T
 	stamp := at.In(time.Local).Format("2006-01-02_15-04-05")
 	summaryRel := filepath.Join("result_summaries", "summary_"+stamp)
 	summaryDir := filepath.Join(rootDir, summaryRel)
+	previous, err := previousSummary(rootDir, summaryDir)
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(summaryDir, 0o755); err != nil {
 		return "", err
 	}
@@ -44,7 +49,7 @@ func publishReport(rootDir string, rep *report.Report, command string, at time.T
 		return "", err
 	}
 
-	table := reportMarkdownTable(rep)
+	table := reportMarkdownTable(rep, previous)
 	summaryLink := filepath.ToSlash(summaryRel)
 	dateOnly := at.In(time.Local).Format("2006-01-02")
 	resultsLine := fmt.Sprintf("Results as of %s. See [%s](%s).", dateOnly, summaryLink, summaryLink)
@@ -56,20 +61,60 @@ func publishReport(rootDir string, rep *report.Report, command string, at time.T
 	return summaryRel, nil
 }
 
-func reportMarkdownTable(rep *report.Report) string {
+// reportMarkdownTable renders rep as the README table. Rows also in previous (the last published summary, if any) show
+// their success rate change in percentage points (ex: "23% (+5%)").
+func reportMarkdownTable(rep *report.Report, previous []report.SummaryRow) string {
+	type key struct{ agent, model string }
+	previousByKey := map[key]report.SummaryRow{}
+	for _, row := range previous {
+		previousByKey[key{row.Agent, row.Model}] = row
+	}
 	var b strings.Builder
 	b.WriteString("| Agent | Model | Success | Avg Cost | Avg Time |\n")
 	b.WriteString("| --- | --- | --- | --- | --- |\n")
 	for _, row := range rep.Rows {
 		successPct := int(math.Round(row.SuccessRate * 100))
-		cost := math.Round(row.AvgCost*100) / 100
-		avgCost := fmt.Sprintf("$%.2f", cost)
+		success := fmt.Sprintf("%d%%", successPct)
+		if prev, ok := previousByKey[key{row.Agent, row.ModelLabel()}]; ok {
+			if delta := successPct - int(math.Round(prev.SuccessRate*100)); delta != 0 {
+				success += fmt.Sprintf(" (%+d%%)", delta)
+			}
+		}
+		cost := math.Round(rep.Currency.Convert(row.AvgCost)*100) / 100
+		avgCost := fmt.Sprintf("%s%.2f", rep.Currency.Symbol(), cost)
+		if row.CostEstimated {
+			avgCost += "*"
+		}
 		avgTime := formatDurationSeconds(row.AvgTimeSeconds)
-		b.WriteString(fmt.Sprintf("| %s | %s | %d%% | %s | %s |\n", row.Agent, row.Model, successPct, avgCost, avgTime))
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", row.Agent, row.ModelLabel(), success, avgCost, avgTime))
 	}
 	return b.String()
 }
 
+// previousSummary loads the most recent result_summaries/summary_* other than exclude, or returns nil if there is none.
+func previousSummary(rootDir, exclude string) ([]report.SummaryRow, error) {
+	dirs, err := filepath.Glob(filepath.Join(rootDir, "result_summaries", "summary_*"))
+	if err != nil {
+		return nil, err
+	}
+	// Stamps sort chronologically.
+	sort.Strings(dirs)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i] == exclude {
+			continue
+		}
+		if info, err := os.Stat(dirs[i]); err != nil || !info.IsDir() {
+			continue
+		}
+		rows, err := report.LoadSummary(dirs[i])
+		if err != nil {
+			return nil, fmt.Errorf("previous summary: %w", err)
+		}
+		return rows, nil
+	}
+	return nil, nil
+}
+
 func formatDurationSeconds(seconds float64) string {
 	if seconds <= 0 {
 		return "0s"
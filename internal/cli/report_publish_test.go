@@ -62,6 +62,42 @@ func TestPublishReportWritesFilesAndUpdatesReadme(t *testing.T) {
 	require.Contains(t, string(updated), "Results as of "+dateOnly+". See [result_summaries/summary_"+stamp+"](result_summaries/summary_"+stamp+").")
 }
 
+func TestPublishReportShowsDeltasVsPreviousSummary(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	readme := beginResultsMarker + "\n" + endResultsMarker + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte(readme), 0o644))
+
+	older := &report.Report{Rows: []report.Row{{Agent: "codex", Model: "gpt-5", SuccessRate: 0.1}}}
+	_, err := publishReport(root, older, "first", time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	prior := &report.Report{Rows: []report.Row{
+		{Agent: "codex", Model: "gpt-5", SuccessRate: 0.18},
+		{Agent: "claude", Model: "opus", SuccessRate: 0.5},
+	}}
+	_, err = publishReport(root, prior, "second", time.Date(2025, 12, 10, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	firstReadme, err := os.ReadFile(filepath.Join(root, "README.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(firstReadme), "| codex | gpt-5 | 18% (+8%) |")
+	require.Contains(t, string(firstReadme), "| claude | opus | 50% |")
+
+	rep := &report.Report{Rows: []report.Row{
+		{Agent: "codex", Model: "gpt-5", SuccessRate: 0.23},
+		{Agent: "claude", Model: "opus", SuccessRate: 0.5},
+		{Agent: "crush", Model: "glm", SuccessRate: 0.4},
+	}}
+	_, err = publishReport(root, rep, "third", time.Date(2025, 12, 14, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+
+	updated, err := os.ReadFile(filepath.Join(root, "README.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(updated), "| codex | gpt-5 | 23% (+5%) |")
+	require.Contains(t, string(updated), "| claude | opus | 50% |")
+	require.Contains(t, string(updated), "| crush | glm | 40% |")
+}
+
 func TestShellQuote(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportCurrencyFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--currency=EUR"}, wantErr: "--currency=EUR requires a positive --currency-rate (EUR per USD)"},
+		{args: []string{"--currency-rate=0.92"}, wantErr: "--currency-rate needs a --currency other than USD (costs are recorded in USD)"},
+		{args: []string{"--currency=usd", "--currency-rate=1"}, wantErr: "--currency-rate needs a --currency other than USD (costs are recorded in USD)"},
+	} {
+		cmd := newReportCmd()
+		cmd.SetArgs(tc.args)
+		require.EqualError(t, cmd.ExecuteContext(context.Background()), tc.wantErr)
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/ansi"
+)
+
+// defaultWatchInterval is how often report --watch recomputes the report.
+const defaultWatchInterval = 5 * time.Second
+
+// watchReport writes render's output to w, then (when tty is true) re-renders every interval until ctx is done, clearing
+// the screen and redrawing whenever the output changes. When tty is false it renders once, since redrawing only makes
+// sense in a terminal. A render error while watching is shown in place of the report and retried on the next tick (ex:
+// a result file that was only partially written).
+func watchReport(ctx context.Context, w io.Writer, tty bool, interval time.Duration, render func() ([]byte, error)) error {
+	if !tty {
+		out, err := render()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last []byte
+	for first := true; ; first = false {
+		out, err := render()
+		if err != nil {
+			out = []byte(fmt.Sprintf("Error: %v\n", err))
+		}
+		if first || !bytes.Equal(out, last) {
+			last = out
+			header := fmt.Sprintf("Updated %s; refreshing every %s (Ctrl-C to exit)\n\n", time.Now().Format("15:04:05"), interval)
+			if _, err := io.WriteString(w, ansi.ClearScreen+header); err != nil {
+				return err
+			}
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// select picks randomly when both are ready; don't redraw after cancellation.
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
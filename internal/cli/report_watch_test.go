@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/ansi"
+)
+
+func TestWatchReportPrintsOnceWithoutTTY(t *testing.T) {
+	calls := 0
+	var out bytes.Buffer
+	err := watchReport(context.Background(), &out, false, time.Millisecond, func() ([]byte, error) {
+		calls++
+		return []byte("agent,model\n"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, "agent,model\n", out.String())
+
+	err = watchReport(context.Background(), &out, false, time.Millisecond, func() ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	require.EqualError(t, err, "boom")
+}
+
+func TestWatchReportRedrawsOnChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	renders := []string{"v1\n", "v1\n", "v2\n", "v2\n"}
+	calls := 0
+	var out bytes.Buffer
+	err := watchReport(ctx, &out, true, time.Millisecond, func() ([]byte, error) {
+		// Stay in bounds even if a tick races the cancel.
+		r := renders[min(calls, len(renders)-1)]
+		calls++
+		if calls == len(renders) {
+			cancel()
+		}
+		return []byte(r), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(renders), calls)
+	require.Equal(t, 2, strings.Count(out.String(), ansi.ClearScreen))
+	require.Contains(t, out.String(), "Ctrl-C to exit")
+	require.True(t, strings.HasSuffix(out.String(), "v2\n"))
+}
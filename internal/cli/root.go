@@ -3,18 +3,25 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/codalotl/goagentbench/internal/agents"
+	"github.com/codalotl/goagentbench/internal/cmdshim"
+	"github.com/codalotl/goagentbench/internal/netrecord"
 	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/redact"
+	"github.com/codalotl/goagentbench/internal/results"
 	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/codalotl/goagentbench/internal/setup"
 	"github.com/codalotl/goagentbench/internal/types"
@@ -27,24 +34,78 @@ var (
 	agentRunner         = agents.Run
 	agentVersionChecker = agents.AgentVersion
 	verifyRunner        = verify.Run
+	setupRunner         = setup.Run
+	unverifiedRecorder  = verify.RecordUnverified
 )
 
+// jsonLogs is the global --json-logs flag: printers emit JSON events instead of styled text.
+var jsonLogs bool
+
+// newPrinter returns the stdout printer for a command, in phase (see output.Printer.SetPhase).
+func newPrinter(phase string) *output.Printer {
+	if !jsonLogs {
+		return output.NewPrinter(os.Stdout)
+	}
+	printer := output.NewJSONPrinter(os.Stdout)
+	printer.SetPhase(phase)
+	return printer
+}
+
+// rootTimeout is the global --timeout flag: a deadline for the whole invocation (0 means none).
+var rootTimeout time.Duration
+
+// errRootTimeout is the context cause once --timeout passes.
+var errRootTimeout = errors.New("--timeout exceeded")
+
 // Execute runs the CLI.
 func Execute() error {
+	return execute(context.Background(), newRootCmd(workspace.Path()))
+}
+
+func newRootCmd(workspacePath string) *cobra.Command {
 	root := silenceUsageAndErrors(&cobra.Command{
 		Use:   "goagentbench",
 		Short: "Benchmark AI coding agents on Go coding tasks.",
 	})
-	workspacePath := workspace.Path()
+	root.PersistentFlags().BoolVar(&jsonLogs, "json-logs", false, "print newline-delimited JSON events instead of styled text")
+	root.PersistentFlags().DurationVar(&rootTimeout, "timeout", 0, "deadline for the whole command, including every subprocess it runs (ex: 2h; 0 means none)")
 
 	root.AddCommand(newValidateCmd())
+	root.AddCommand(newListScenariosCmd())
+	root.AddCommand(newListAgentsCmd())
+	root.AddCommand(newValidateRegistryCmd())
 	root.AddCommand(newSetupCmd(workspacePath))
 	root.AddCommand(newRunAgentCmd(workspacePath))
 	root.AddCommand(newExecCmd(workspacePath))
 	root.AddCommand(newVerifyCmd(workspacePath))
 	root.AddCommand(newReportCmd())
-	executed, err := root.ExecuteC()
+	return root
+}
+
+// execute runs root under ctx, bounded by --timeout once flags are parsed. Commands pass cmd.Context() to every
+// subprocess they start, so all of them are killed when it passes; an error returned after that is prefixed with the
+// timeout.
+func execute(ctx context.Context, root *cobra.Command) error {
+	cancel := context.CancelFunc(func() {})
+	defer func() { cancel() }()
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if rootTimeout < 0 {
+			return fmt.Errorf("--timeout must be >= 0, got %s", rootTimeout)
+		}
+		if rootTimeout > 0 {
+			var timeoutCtx context.Context
+			timeoutCtx, cancel = context.WithTimeoutCause(cmd.Context(), rootTimeout, fmt.Errorf("%w (%s)", errRootTimeout, rootTimeout))
+			cmd.SetContext(timeoutCtx)
+		}
+		return nil
+	}
+	executed, err := root.ExecuteContextC(ctx)
 	if err != nil {
+		if executed != nil && executed.Context() != nil {
+			if cause := context.Cause(executed.Context()); errors.Is(cause, errRootTimeout) && !errors.Is(err, errRootTimeout) {
+				err = fmt.Errorf("%w: %w", cause, err)
+			}
+		}
 		maybePrintUsage(executed, root, err)
 	}
 	return err
@@ -73,6 +134,204 @@ func newValidateCmd() *cobra.Command {
 				return fmt.Errorf("format scenario: %w", err)
 			}
 			fmt.Println(string(formatted))
+			if err := scenario.CheckSinglePackage(sc); err != nil {
+				fmt.Printf("warning: %v\n", err)
+			}
+			instances, err := sc.ExpandMatrix()
+			if err != nil {
+				return err
+			}
+			for _, inst := range instances {
+				fmt.Printf("matrix instance: %s%s%s\n", scenarioName, workspace.InstanceSep, inst.ID())
+			}
+			fmt.Println("valid")
+			return nil
+		},
+	})
+	return cmd
+}
+
+// scenarioListing is one list-scenarios entry. Error is set, and Type and Repo are empty, if the scenario.yml doesn't
+// load.
+type scenarioListing struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Repo  string `json:"repo"`
+	Error string `json:"error,omitempty"`
+}
+
+func newListScenariosCmd() *cobra.Command {
+	var asJSON bool
+	cmd := silenceUsageAndErrors(&cobra.Command{
+		Use:   "list-scenarios",
+		Short: "List the scenarios under the scenario root",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listings, err := listScenarios(workspace.ScenarioDir(""))
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(listings)
+			}
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tTYPE\tREPO")
+			for _, l := range listings {
+				line := l.Name + "\t" + l.Type + "\t" + l.Repo
+				if l.Error != "" {
+					line += "\terror: " + l.Error
+				}
+				fmt.Fprintln(tw, line)
+			}
+			return tw.Flush()
+		},
+	})
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the scenarios as a JSON array of {name, type, repo, error}")
+	return cmd
+}
+
+// listScenarios finds every scenario.yml under root, in path order, and loads each (without the full validate-scenario
+// checks, which hit the network). A scenario that doesn't load is listed with its error rather than failing the listing.
+func listScenarios(root string) ([]scenarioListing, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("scenario root: %w", err)
+	}
+	var listings []scenarioListing
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		// Look for scenario.yml on entering each dir, rather than when the walk reaches it, so subdirs that sort before
+		// it (ex: data/) are skipped too.
+		scenarioPath := filepath.Join(path, "scenario.yml")
+		if _, err := os.Stat(scenarioPath); err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		listing := scenarioListing{Name: filepath.ToSlash(rel)}
+		if sc, err := scenario.Load(scenarioPath); err != nil {
+			listing.Error = strings.Join(strings.Fields(err.Error()), " ")
+		} else {
+			listing.Type = sc.Classification.Type
+			listing.Repo = sc.Repo
+		}
+		listings = append(listings, listing)
+		// A scenario's own files (ex: setup and verify data) aren't scenarios, so skip the rest of its dir.
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// loadRunnableScenario loads scenarioName's scenario for setup, run-agent, verify, or exec. A scenario with a matrix is
+// a family of scenarios, so it runs one instance at a time, named <scenario>@<instance ID> (exec expands the family).
+func loadRunnableScenario(scenarioName string) (*scenario.Scenario, error) {
+	path := workspace.ScenarioFile(scenarioName)
+	sc, err := scenario.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	base, instanceID := workspace.SplitInstance(scenarioName)
+	if len(sc.Matrix) == 0 {
+		if instanceID != "" {
+			return nil, fmt.Errorf("%s has no matrix, so it has no instance %q", path, instanceID)
+		}
+		return sc, nil
+	}
+	if instanceID == "" {
+		return nil, fmt.Errorf("%s has a matrix: name one instance as %s%s<instance> (validate-scenario lists them), or exec %s to run them all", path, base, workspace.InstanceSep, base)
+	}
+	instances, err := sc.ExpandMatrix()
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		if inst.ID() != instanceID {
+			continue
+		}
+		// The instance ID is part of its workspace dir and results paths.
+		if strings.ContainsAny(instanceID, `/\`) {
+			return nil, fmt.Errorf("%s: matrix instance %q can't be run: values used in instance names can't contain path separators", path, instanceID)
+		}
+		return inst.Scenario, nil
+	}
+	return nil, fmt.Errorf("%s has no matrix instance %q (validate-scenario lists them)", path, instanceID)
+}
+
+func newListAgentsCmd() *cobra.Command {
+	var agentFilter string
+	cmd := silenceUsageAndErrors(&cobra.Command{
+		Use:   "list-agents",
+		Short: "List the agents in agents.yml and the models each supports",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, _ := os.Getwd()
+			registry, err := agents.LoadRegistry(rootDir)
+			if err != nil {
+				return err
+			}
+			names := registry.AgentNames()
+			if agentFilter != "" {
+				if _, ok := registry.Agent(agentFilter); !ok {
+					return fmt.Errorf("unknown agent %q", agentFilter)
+				}
+				names = []string{agentFilter}
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "AGENT\tVERSION\tLLM\tMODEL\tREASONING")
+			for _, name := range names {
+				agent, _ := registry.Agent(name)
+				llms, missing := registry.SupportedLLMs(name)
+				if len(llms) == 0 && len(missing) == 0 {
+					fmt.Fprintf(tw, "%s\t%s\t-\t\t\n", name, agent.Version)
+				}
+				for i, llm := range llms {
+					line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", name, agent.Version, llm.Name, llm.Model, llm.ReasoningLevel)
+					// run-agent uses the first supported LLM when --model isn't given.
+					if i == 0 && agent.SupportsLLMs[0] == llm.Name {
+						line += "\tdefault"
+					}
+					fmt.Fprintln(tw, line)
+				}
+				for _, m := range missing {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t\t\terror: missing from llms.yml\n", name, agent.Version, m)
+				}
+			}
+			return tw.Flush()
+		},
+	})
+	cmd.Flags().StringVar(&agentFilter, "agent", "", "only list this agent")
+	return cmd
+}
+
+func newValidateRegistryCmd() *cobra.Command {
+	cmd := silenceUsageAndErrors(&cobra.Command{
+		Use:   "validate-registry",
+		Short: "Validate agents.yml and llms.yml",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, _ := os.Getwd()
+			problems, err := agents.ValidateRegistryFiles(rootDir)
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Println(p)
+				}
+				return fmt.Errorf("registry has %d problem(s)", len(problems))
+			}
 			fmt.Println("valid")
 			return nil
 		},
@@ -81,6 +340,7 @@ func newValidateCmd() *cobra.Command {
 }
 
 func newSetupCmd(workspacePath string) *cobra.Command {
+	var checkReproduced bool
 	cmd := silenceUsageAndErrors(&cobra.Command{
 		Use:   "setup <scenario>",
 		Short: "Prepare the scenario workspace",
@@ -91,15 +351,21 @@ func newSetupCmd(workspacePath string) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			scenarioPath := workspace.ScenarioFile(scenarioName)
-			sc, err := scenario.Load(scenarioPath)
+			sc, err := loadRunnableScenario(scenarioName)
 			if err != nil {
 				return err
 			}
-			printer := output.NewPrinter(os.Stdout)
-			return setup.Run(ctx, printer, scenarioName, workspacePath, sc)
+			printer := newPrinter("setup")
+			if err := setup.Run(ctx, printer, scenarioName, workspacePath, sc); err != nil {
+				return err
+			}
+			if checkReproduced {
+				return verify.CheckReproduced(ctx, printer, scenarioName, workspacePath, sc)
+			}
+			return nil
 		},
 	})
+	cmd.Flags().BoolVar(&checkReproduced, "check-reproduced", false, "after setup, check that the hidden tests fail (when classification.sees-failing-tests is false)")
 	return cmd
 }
 
@@ -107,22 +373,48 @@ func newRunAgentCmd(workspacePath string) *cobra.Command {
 	var agentName string
 	var modelName string
 	var onlyStart bool
+	var reasoning string
+	var modelsFile string
+	var agentEnv []string
+	var strictVersion bool
+	var printInstructions bool
+	var baseInstructionsFile string
+	var tokenBudget int
+	var manualComplete bool
+	var manual manualCompletion
+	var measureOverhead bool
 	cmd := silenceUsageAndErrors(&cobra.Command{
 		Use:   "run-agent --agent=<agent> [--model=<model>] <scenario>",
 		Short: "Run an agent on a prepared scenario",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			if agentName == "" {
+			if !manualComplete {
+				for _, name := range manualCompletionFlags {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s requires --manual-complete", name)
+					}
+				}
+			}
+			if agentName == "" && !manualComplete {
 				return fmt.Errorf("--agent is required")
 			}
-			printer := output.NewPrinter(os.Stdout)
+			printer := newPrinter("run-agent")
 			scenarioName, err := workspace.CleanScenario(args[0])
 			if err != nil {
 				return err
 			}
+			if manualComplete {
+				if onlyStart {
+					return fmt.Errorf("--manual-complete cannot be combined with --only-start")
+				}
+				return completeManualRun(printer, workspacePath, scenarioName, manual, time.Now())
+			}
+			if measureOverhead && onlyStart {
+				return fmt.Errorf("--measure-overhead cannot be combined with --only-start")
+			}
 			rootDir, _ := os.Getwd()
-			registry, err := agents.LoadRegistry(rootDir)
+			registry, err := loadRegistry(rootDir, modelsFile)
 			if err != nil {
 				return err
 			}
@@ -130,86 +422,548 @@ func newRunAgentCmd(workspacePath string) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			scenarioPath := workspace.ScenarioFile(scenarioName)
-			sc, err := scenario.Load(scenarioPath)
+			if err := applyReasoningOverride(llmDef, reasoning); err != nil {
+				return err
+			}
+			sc, err := loadRunnableScenario(scenarioName)
 			if err != nil {
 				return err
 			}
+			if err := applyAgentEnvFlags(sc, agentEnv); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("token-budget") {
+				sc.Agent.TokenBudget = tokenBudget
+			}
 			if err := scenario.Validate(sc, workspace.ScenarioDir(scenarioName)); err != nil {
 				return err
 			}
-			return runAgent(ctx, printer, workspacePath, scenarioName, agentDef, modelName, llmDef, sc, onlyStart)
+			if printInstructions {
+				base, err := loadBaseInstructions(rootDir, baseInstructionsFile)
+				if err != nil {
+					return err
+				}
+				return printResolvedInstructions(printer, sc, base)
+			}
+			return runAgent(ctx, printer, workspacePath, scenarioName, agentDef, modelName, llmDef, sc, runAgentOptions{
+				OnlyStart:            onlyStart,
+				AllowVersionDrift:    !strictVersion,
+				BaseInstructionsFile: baseInstructionsFile,
+				MeasureOverhead:      measureOverhead,
+			})
 		},
 	})
 	cmd.Flags().StringVar(&agentName, "agent", "", "agent to run (required)")
 	cmd.Flags().StringVar(&modelName, "model", "", "model to use")
 	cmd.Flags().BoolVar(&onlyStart, "only-start", false, "only create .run-start.json without running agent")
+	cmd.Flags().StringVar(&reasoning, "reasoning", "", "override the model's reasoning level ("+strings.Join(agents.ReasoningLevels, "|")+")")
+	cmd.Flags().StringVar(&modelsFile, "models-file", "", "extra llms.yml-style file merged over llms.yml")
+	cmd.Flags().StringArrayVar(&agentEnv, "agent-env", nil, "KEY=VALUE set only in the agent's environment (repeatable; overrides agent.env)")
+	cmd.Flags().BoolVar(&strictVersion, "strict-version", true, "error if the installed agent version differs from agents.yml (false: record the installed version)")
+	cmd.Flags().BoolVar(&printInstructions, "print-instructions", false, "print the instructions that would be sent to the agent and exit")
+	cmd.Flags().StringVar(&baseInstructionsFile, "base-instructions-file", "", "file prepended to agent.instructions (default: "+defaultBaseInstructionsFile+", if it exists)")
+	cmd.Flags().IntVar(&tokenBudget, "token-budget", 0, "stop the run once it has used more than this many tokens (overrides agent.token-budget; 0: no limit)")
+	cmd.Flags().BoolVar(&measureOverhead, "measure-overhead", false, "first run a no-op prompt and record its cost as the run's fixed overhead (report --include-net-cost)")
+	cmd.Flags().BoolVar(&manualComplete, "manual-complete", false, "finish a run started with --only-start: write .run-progress.json with its end time, duration, and any token usage given")
+	cmd.Flags().DurationVar(&manual.Duration, "duration", 0, "with --manual-complete, the agent's run time (default: time since the run started)")
+	cmd.Flags().IntVar(&manual.Tokens.Input, "input-tokens", 0, "with --manual-complete, non-cached input tokens used")
+	cmd.Flags().IntVar(&manual.Tokens.CachedInput, "cached-input-tokens", 0, "with --manual-complete, cached input tokens used")
+	cmd.Flags().IntVar(&manual.Tokens.WriteCachedInput, "write-cached-input-tokens", 0, "with --manual-complete, cache-write input tokens used")
+	cmd.Flags().IntVar(&manual.Tokens.Output, "output-tokens", 0, "with --manual-complete, output tokens used")
+	cmd.Flags().Float64Var(&manual.Tokens.Cost, "cost", 0, "with --manual-complete, the run's cost in USD (default: estimated from tokens by report)")
 	return cmd
 }
 
+// manualCompletionFlags are the run-agent flags that only apply with --manual-complete.
+var manualCompletionFlags = []string{"duration", "input-tokens", "cached-input-tokens", "write-cached-input-tokens", "output-tokens", "cost"}
+
 func newExecCmd(workspacePath string) *cobra.Command {
 	var agentName string
 	var modelName string
+	var reasoning string
+	var modelsFile string
+	var agentEnv []string
+	var strictVersion bool
+	var printInstructions bool
+	var untilSuccess bool
+	var maxAttempts int
+	var baseInstructionsFile string
+	var tokenBudget int
+	var phasesFlag string
+	var resumeSweep bool
+	var noVerify bool
+	var scenariosFile string
+	var failFast bool
 	cmd := silenceUsageAndErrors(&cobra.Command{
-		Use:   "exec --agent=<agent> [--model=<model>] <scenario>",
-		Short: "Validate, set up, run, and verify a scenario",
-		Args:  cobra.ExactArgs(1),
+		Use:   "exec --agent=<agent> [--model=<model>] <scenario>...",
+		Short: "Validate, set up, run, and verify one or more scenarios",
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			if agentName == "" {
-				return fmt.Errorf("--agent is required")
-			}
-			printer := output.NewPrinter(os.Stdout)
-			scenarioName, err := workspace.CleanScenario(args[0])
+			phases, err := parseExecPhases(phasesFlag)
 			if err != nil {
 				return err
 			}
-			scenarioPath := workspace.ScenarioFile(scenarioName)
-			sc, err := scenario.Load(scenarioPath)
-			if err != nil {
-				return err
+			if noVerify {
+				if cmd.Flags().Changed("phases") && phases.verify {
+					return fmt.Errorf("--no-verify cannot be combined with verify in --phases")
+				}
+				if !phases.run {
+					return fmt.Errorf("--no-verify requires run in --phases")
+				}
+				if untilSuccess || resumeSweep {
+					return fmt.Errorf("--no-verify cannot be combined with --repeat-until-success or --resume-sweep")
+				}
+				phases.verify = false
 			}
-			if err := scenario.Validate(sc, workspace.ScenarioDir(scenarioName)); err != nil {
-				return err
+			if agentName == "" && phases.run {
+				return fmt.Errorf("--agent is required")
 			}
-			if err := printer.App("Scenario validated."); err != nil {
-				return err
+			if untilSuccess && !(phases.setup && phases.run && phases.verify) {
+				return fmt.Errorf("--repeat-until-success requires all of setup,run,verify in --phases")
 			}
-			if err := setup.Run(ctx, printer, scenarioName, workspacePath, sc); err != nil {
-				return err
+			if cmd.Flags().Changed("max-attempts") && !untilSuccess {
+				return fmt.Errorf("--max-attempts requires --repeat-until-success")
 			}
-			if err := printer.App("Scenario setup complete."); err != nil {
-				return err
+			if maxAttempts < 1 {
+				return fmt.Errorf("--max-attempts must be >= 1, got %d", maxAttempts)
 			}
-			rootDir, _ := os.Getwd()
-			registry, err := agents.LoadRegistry(rootDir)
+			if resumeSweep && !phases.verify {
+				return fmt.Errorf("--resume-sweep requires verify in --phases")
+			}
+			statePath := sweepStatePath(workspacePath)
+			// execScenario runs the pipeline for one scenario, printing through printer.
+			execScenario := func(printer *output.Printer, scenarioName string) (execOutcome, error) {
+				sweepKey := sweepCombo{Scenario: scenarioName, Agent: agentName, Model: modelName, Reasoning: strings.TrimSpace(reasoning)}
+				if resumeSweep {
+					state, err := loadSweepState(statePath)
+					if err != nil {
+						return execOutcome{}, err
+					}
+					if done, ok := state.completed(sweepKey); ok {
+						return execOutcome{skipped: true, runID: done.RunID}, printer.Appf("Skipping %s (agent=%s, model=%s%s): already completed in %s (run %s).", scenarioName, agentName, modelName, sweepReasoningNote(sweepKey.Reasoning), statePath, done.RunID)
+					}
+				}
+				sc, err := loadRunnableScenario(scenarioName)
+				if err != nil {
+					return execOutcome{}, err
+				}
+				if err := applyAgentEnvFlags(sc, agentEnv); err != nil {
+					return execOutcome{}, err
+				}
+				if cmd.Flags().Changed("token-budget") {
+					sc.Agent.TokenBudget = tokenBudget
+				}
+				if err := scenario.Validate(sc, workspace.ScenarioDir(scenarioName)); err != nil {
+					return execOutcome{}, err
+				}
+				rootDir, _ := os.Getwd()
+				if printInstructions {
+					base, err := loadBaseInstructions(rootDir, baseInstructionsFile)
+					if err != nil {
+						return execOutcome{}, err
+					}
+					return execOutcome{}, printResolvedInstructions(printer, sc, base)
+				}
+				if err := printer.App("Scenario validated."); err != nil {
+					return execOutcome{}, err
+				}
+				var agentDef agents.Definition
+				var llmDef *agents.LLMDefinition
+				if phases.run {
+					registry, err := loadRegistry(rootDir, modelsFile)
+					if err != nil {
+						return execOutcome{}, err
+					}
+					agentDef, llmDef, err = registry.ValidateAgentModel(agentName, modelName)
+					if err != nil {
+						return execOutcome{}, err
+					}
+					if err := applyReasoningOverride(llmDef, reasoning); err != nil {
+						return execOutcome{}, err
+					}
+				}
+				if !untilSuccess {
+					maxAttempts = 1
+				}
+				reports, err := repeatUntilSuccess(maxAttempts, func(attempt int) (*types.VerificationReport, error) {
+					if untilSuccess {
+						if err := printer.Appf("Attempt %d of %d.", attempt, maxAttempts); err != nil {
+							return nil, err
+						}
+					}
+					// Setup resets the workspace, so every attempt starts clean.
+					if phases.setup {
+						printer.SetPhase("setup")
+						if err := setupRunner(ctx, printer, scenarioName, workspacePath, sc); err != nil {
+							return nil, err
+						}
+						if err := printer.App("Scenario setup complete."); err != nil {
+							return nil, err
+						}
+					}
+					if phases.run {
+						opts := runAgentOptions{AllowVersionDrift: !strictVersion, BaseInstructionsFile: baseInstructionsFile}
+						if untilSuccess {
+							opts.Attempt = attempt
+						}
+						printer.SetPhase("run-agent")
+						if err := runAgent(ctx, printer, workspacePath, scenarioName, agentDef, modelName, llmDef, sc, opts); err != nil {
+							return nil, err
+						}
+					}
+					if noVerify {
+						res, err := unverifiedRecorder(verify.Options{
+							ScenarioName:  scenarioName,
+							WorkspacePath: workspacePath,
+							RootPath:      rootDir,
+							Printer:       printer,
+						})
+						if err != nil {
+							return nil, err
+						}
+						return nil, printer.Appf("Recorded run %s without verification.", res.RunID)
+					}
+					if !phases.verify {
+						return nil, nil
+					}
+					printer.SetPhase("verify")
+					res, err := verifyRunner(ctx, verify.Options{
+						ScenarioName:  scenarioName,
+						WorkspacePath: workspacePath,
+						RootPath:      rootDir,
+						Printer:       printer,
+					}, sc)
+					if err != nil || res == nil {
+						return nil, err
+					}
+					return res.Report, nil
+				})
+				if err != nil {
+					return execOutcome{}, err
+				}
+				outcome := execOutcome{report: reports[len(reports)-1]}
+				if !phases.verify {
+					return outcome, nil
+				}
+				if err := printer.App("Verification complete."); err != nil {
+					return outcome, err
+				}
+				if resumeSweep {
+					combo := sweepKey
+					combo.CompletedAt = time.Now()
+					if last := reports[len(reports)-1]; last != nil {
+						combo.RunID = last.RunID
+						combo.Success = last.Success
+					}
+					if err := recordSweepCombo(statePath, combo); err != nil {
+						return outcome, err
+					}
+				}
+				if untilSuccess {
+					return outcome, printer.App(attemptsSummary(reports, maxAttempts))
+				}
+				return outcome, nil
+			}
+
+			scenarioNames, err := execScenarioNames(args, scenariosFile)
 			if err != nil {
 				return err
 			}
-			agentDef, llmDef, err := registry.ValidateAgentModel(agentName, modelName)
+			scenarioNames, err = expandMatrixScenarios(scenarioNames)
 			if err != nil {
 				return err
 			}
-			if err := runAgent(ctx, printer, workspacePath, scenarioName, agentDef, modelName, llmDef, sc, false); err != nil {
+			if len(scenarioNames) == 1 {
+				_, err := execScenario(newPrinter("validate"), scenarioNames[0])
 				return err
 			}
-			if _, err := verifyRunner(ctx, verify.Options{
-				ScenarioName:  scenarioName,
-				WorkspacePath: workspacePath,
-				RootPath:      rootDir,
-				Printer:       printer,
-			}, sc); err != nil {
+			if printInstructions {
+				return fmt.Errorf("--print-instructions takes a single scenario")
+			}
+			// Each scenario runs in its own workspace dir, and one that errors doesn't stop the rest (unless --fail-fast).
+			// Cancellation (Ctrl-C or the root --timeout) stops the sweep.
+			var rows []execSummaryRow
+			notRunNote := "stopped by --fail-fast"
+			for i, scenarioName := range scenarioNames {
+				if ctx.Err() != nil {
+					notRunNote = "cancelled"
+					break
+				}
+				printer := newPrinter("validate")
+				if err := printer.Appf("Scenario %d of %d: %s", i+1, len(scenarioNames), scenarioName); err != nil {
+					return err
+				}
+				outcome, err := execScenario(printer, scenarioName)
+				row := execSummaryRow{scenario: scenarioName, outcome: outcome, err: err}
+				rows = append(rows, row)
+				if err != nil {
+					if printErr := printer.Appf("Error: %v", err); printErr != nil {
+						return printErr
+					}
+				}
+				if failFast && row.failed() {
+					break
+				}
+			}
+			printer := newPrinter("exec")
+			if err := printer.App(execSummary(scenarioNames, rows, notRunNote)); err != nil {
 				return err
 			}
-			return printer.App("Verification complete.")
+			errored := 0
+			for _, row := range rows {
+				if row.err != nil {
+					errored++
+				}
+			}
+			if errored > 0 {
+				return fmt.Errorf("%d of %d scenario(s) errored", errored, len(scenarioNames))
+			}
+			if len(rows) < len(scenarioNames) && ctx.Err() != nil {
+				return fmt.Errorf("%d of %d scenario(s) not run: %w", len(scenarioNames)-len(rows), len(scenarioNames), context.Cause(ctx))
+			}
+			return nil
 		},
 	})
 	cmd.Flags().StringVar(&agentName, "agent", "", "agent to run (required)")
 	cmd.Flags().StringVar(&modelName, "model", "", "model to use")
+	cmd.Flags().StringVar(&reasoning, "reasoning", "", "override the model's reasoning level ("+strings.Join(agents.ReasoningLevels, "|")+")")
+	cmd.Flags().StringVar(&modelsFile, "models-file", "", "extra llms.yml-style file merged over llms.yml")
+	cmd.Flags().StringArrayVar(&agentEnv, "agent-env", nil, "KEY=VALUE set only in the agent's environment (repeatable; overrides agent.env)")
+	cmd.Flags().BoolVar(&strictVersion, "strict-version", true, "error if the installed agent version differs from agents.yml (false: record the installed version)")
+	cmd.Flags().BoolVar(&printInstructions, "print-instructions", false, "print the instructions that would be sent to the agent and exit")
+	cmd.Flags().StringVar(&baseInstructionsFile, "base-instructions-file", "", "file prepended to agent.instructions (default: "+defaultBaseInstructionsFile+", if it exists)")
+	cmd.Flags().IntVar(&tokenBudget, "token-budget", 0, "stop the run once it has used more than this many tokens (overrides agent.token-budget; 0: no limit)")
+	cmd.Flags().BoolVar(&untilSuccess, "repeat-until-success", false, "repeat setup, run, and verify until verification passes (see --max-attempts)")
+	cmd.Flags().IntVar(&maxAttempts, "max-attempts", 5, "with --repeat-until-success, the most attempts to make")
+	cmd.Flags().BoolVar(&resumeSweep, "resume-sweep", false, "skip this {scenario, agent, model, reasoning} if the workspace's sweep state file records it as completed; record it once verified")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "skip verification; record the run's tokens, cost, and time in the results as unverified")
+	cmd.Flags().StringVar(&phasesFlag, "phases", strings.Join(execPhaseNames, ","), "comma-separated phases to run, in order (validation always runs)")
+	cmd.Flags().StringVar(&scenariosFile, "scenarios-file", "", "file listing scenarios to run after the arguments, one per line (blank lines and # comments are ignored)")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "with several scenarios, stop after the first one that errors or fails verification")
 	return cmd
 }
 
-func runAgent(ctx context.Context, printer *output.Printer, workspacePath, scenarioName string, agentDef agents.Definition, modelName string, llm *agents.LLMDefinition, sc *scenario.Scenario, onlyStart bool) error {
+// execPhaseNames are exec's phases in the order they run.
+var execPhaseNames = []string{"setup", "run", "verify"}
+
+// execPhases records which of exec's phases to run.
+type execPhases struct {
+	setup  bool
+	run    bool
+	verify bool
+}
+
+// parseExecPhases parses --phases: a non-empty comma-separated subset of execPhaseNames, in that order, with no repeats.
+func parseExecPhases(s string) (execPhases, error) {
+	var phases execPhases
+	next := 0
+	for _, raw := range strings.Split(s, ",") {
+		name := strings.TrimSpace(raw)
+		if name == "" {
+			continue
+		}
+		idx := -1
+		for i, p := range execPhaseNames {
+			if p == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return execPhases{}, fmt.Errorf("unknown phase %q in --phases (want %s)", name, strings.Join(execPhaseNames, "|"))
+		}
+		if idx < next {
+			return execPhases{}, fmt.Errorf("--phases must be in order %s, with no repeats: got %q", strings.Join(execPhaseNames, ","), s)
+		}
+		next = idx + 1
+		switch name {
+		case "setup":
+			phases.setup = true
+		case "run":
+			phases.run = true
+		case "verify":
+			phases.verify = true
+		}
+	}
+	if phases == (execPhases{}) {
+		return execPhases{}, fmt.Errorf("--phases cannot be empty")
+	}
+	return phases, nil
+}
+
+// repeatUntilSuccess calls attempt (with 1-based attempt numbers) until it returns a successful report or maxAttempts
+// attempts were made. It returns every attempt's report, in order. Errors name their attempt only when there can be
+// more than one (exec --repeat-until-success).
+func repeatUntilSuccess(maxAttempts int, attempt func(n int) (*types.VerificationReport, error)) ([]*types.VerificationReport, error) {
+	var reports []*types.VerificationReport
+	for n := 1; n <= maxAttempts; n++ {
+		rep, err := attempt(n)
+		if err != nil {
+			if maxAttempts > 1 {
+				err = fmt.Errorf("attempt %d: %w", n, err)
+			}
+			return reports, err
+		}
+		reports = append(reports, rep)
+		if rep != nil && rep.Success {
+			break
+		}
+	}
+	return reports, nil
+}
+
+// attemptsSummary describes the outcome of exec --repeat-until-success: the attempt that succeeded (if any) and each
+// attempt's run id.
+func attemptsSummary(reports []*types.VerificationReport, maxAttempts int) string {
+	runIDs := make([]string, 0, len(reports))
+	succeeded := false
+	for _, rep := range reports {
+		if rep == nil {
+			continue
+		}
+		runIDs = append(runIDs, rep.RunID)
+		succeeded = rep.Success
+	}
+	var b strings.Builder
+	if succeeded {
+		fmt.Fprintf(&b, "Succeeded on attempt %d of %d.", len(reports), maxAttempts)
+	} else {
+		fmt.Fprintf(&b, "No successful verification in %d attempts.", len(reports))
+	}
+	if len(runIDs) > 0 {
+		fmt.Fprintf(&b, " Runs: %s", strings.Join(runIDs, ", "))
+	}
+	return b.String()
+}
+
+// applyAgentEnvFlags merges --agent-env KEY=VALUE entries into sc.Agent.Env.
+func applyAgentEnvFlags(sc *scenario.Scenario, entries []string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	env := scenario.SecretEnv{}
+	for _, entry := range entries {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid --agent-env %q (expected KEY=VALUE)", entry)
+		}
+		env[key] = value
+	}
+	if err := env.Validate("--agent-env"); err != nil {
+		return err
+	}
+	if sc.Agent.Env == nil {
+		sc.Agent.Env = scenario.SecretEnv{}
+	}
+	for k, v := range env {
+		sc.Agent.Env[k] = v
+	}
+	return nil
+}
+
+// defaultBaseInstructionsFile is the repo-level preamble prepended to every scenario's instructions, if it exists.
+const defaultBaseInstructionsFile = "instructions_preamble.md"
+
+// loadBaseInstructions reads the base instructions file at path (relative to rootDir unless absolute). An empty path
+// means defaultBaseInstructionsFile, which may be missing; an explicit path must exist.
+func loadBaseInstructions(rootDir, path string) (string, error) {
+	explicit := strings.TrimSpace(path) != ""
+	if !explicit {
+		path = defaultBaseInstructionsFile
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read base instructions: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveInstructions returns the instructions for the agent's first turn: base (if any) followed by agent.instructions,
+// unless the scenario sets agent.skip-base-instructions.
+func resolveInstructions(sc *scenario.Scenario, base string) string {
+	instructions := strings.TrimSpace(sc.Agent.Instructions)
+	base = strings.TrimSpace(base)
+	if base == "" || sc.Agent.SkipBaseInstructions {
+		return instructions
+	}
+	return base + "\n\n" + instructions
+}
+
+// printResolvedInstructions prints the instructions that would be sent to the agent (--print-instructions). For a
+// staged scenario, that's each stage's instructions (only the first stage gets base).
+func printResolvedInstructions(printer *output.Printer, sc *scenario.Scenario, base string) error {
+	stages := sc.StageScenarios()
+	if len(stages) == 0 {
+		if err := printer.App("Instructions:"); err != nil {
+			return err
+		}
+		return printer.App(resolveInstructions(sc, base))
+	}
+	for i, stageSc := range stages {
+		if err := printer.Appf("Instructions (stage %s):", sc.Stages[i].Name); err != nil {
+			return err
+		}
+		instructions := strings.TrimSpace(stageSc.Agent.Instructions)
+		if i == 0 {
+			instructions = resolveInstructions(stageSc, base)
+		}
+		if err := printer.App(instructions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRegistry loads the agent/LLM registry from rootDir, merging modelsFile (if set) over llms.yml.
+func loadRegistry(rootDir, modelsFile string) (*agents.Registry, error) {
+	if strings.TrimSpace(modelsFile) == "" {
+		return agents.LoadRegistry(rootDir)
+	}
+	return agents.LoadRegistry(rootDir, modelsFile)
+}
+
+// applyReasoningOverride replaces llm's reasoning level with level, if level is non-empty.
+func applyReasoningOverride(llm *agents.LLMDefinition, level string) error {
+	level = strings.TrimSpace(level)
+	if level == "" {
+		return nil
+	}
+	if err := agents.ValidateReasoningLevel(level); err != nil {
+		return fmt.Errorf("invalid --reasoning: %w", err)
+	}
+	if llm == nil {
+		return fmt.Errorf("--reasoning requires a model")
+	}
+	llm.ReasoningLevel = level
+	llm.ReasoningOverride = true
+	return nil
+}
+
+// runAgentOptions are the run-agent/exec flags that affect runAgent. The zero value is the default behavior.
+type runAgentOptions struct {
+	// OnlyStart only writes .run-start.json without running the agent.
+	OnlyStart bool
+	// BaseInstructionsFile is the preamble prepended to agent.instructions. Empty means defaultBaseInstructionsFile,
+	// if it exists.
+	BaseInstructionsFile string
+	// Attempt, when > 0, is appended to the run id so repeated attempts (exec --repeat-until-success) get unique ids
+	// even within one second.
+	Attempt int
+	// AllowVersionDrift records the harness-reported agent version instead of erroring when it differs from agents.yml
+	// (--strict-version=false).
+	AllowVersionDrift bool
+	// MeasureOverhead runs a no-op turn before the run and records its usage as the run's OverheadTokenUsage.
+	MeasureOverhead bool
+}
+
+func runAgent(ctx context.Context, printer *output.Printer, workspacePath, scenarioName string, agentDef agents.Definition, modelName string, llm *agents.LLMDefinition, sc *scenario.Scenario, opts runAgentOptions) error {
 	if modelName == "" && llm != nil {
 		modelName = llm.Name
 	}
@@ -226,26 +980,77 @@ func runAgent(ctx context.Context, printer *output.Printer, workspacePath, scena
 	if _, err := os.Stat(runProgressPath); err == nil {
 		return fmt.Errorf("run already in progress at %s", runProgressPath)
 	}
+	baseInstructions, err := loadBaseInstructions(rootDir, opts.BaseInstructionsFile)
+	if err != nil {
+		return err
+	}
+	// Transcripts and notes are redacted before they're persisted, since results may be shared publicly.
+	envValues := make([]string, 0, len(sc.Agent.Env))
+	for _, v := range sc.Agent.Env {
+		envValues = append(envValues, v)
+	}
+	redactor, err := redact.Load(envValues)
+	if err != nil {
+		return err
+	}
 	agentVersion := agentDef.Version
 	actualVersion, err := agentVersionChecker(ctx, agentDef)
 	if err != nil {
 		return fmt.Errorf("check version for agent %q: %w", agentDef.Name, err)
 	}
 	if actualVersion != agentDef.Version {
-		return fmt.Errorf("agent %q version mismatch: expected %s, got %s", agentDef.Name, agentDef.Version, actualVersion)
+		if !opts.AllowVersionDrift {
+			return fmt.Errorf("agent %q version mismatch: expected %s, got %s", agentDef.Name, agentDef.Version, actualVersion)
+		}
+		if err := printer.Appf("Warning: agent %q version drift: expected %s, got %s (recording %s).", agentDef.Name, agentDef.Version, actualVersion, actualVersion); err != nil {
+			return err
+		}
 	}
 	agentVersion = actualVersion
 	agentDef.Version = actualVersion
+	if !opts.OnlyStart {
+		// Run before the run starts, so agent prep isn't timed as part of the run.
+		if err := runAgentSetupCommands(ctx, printer, workspaceDir, sc); err != nil {
+			return err
+		}
+	}
+	var overhead *types.TokenUsage
+	if opts.MeasureOverhead && !opts.OnlyStart {
+		// Also before the run starts, so the no-op turn isn't timed either.
+		overhead, err = measureOverhead(ctx, printer, agents.RunContext{
+			ScenarioName: scenarioName,
+			ScenarioPath: workspaceDir,
+			ModelName:    modelName,
+			LLM:          llm,
+			Agent:        agentDef,
+			Options: agents.RunOptions{
+				Package: strings.TrimSpace(sc.Agent.Package),
+				Env:     sc.Agent.Env.Entries(),
+			},
+			Printer: printer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	reasoningLevel := ""
+	if llm != nil {
+		reasoningLevel = llm.ReasoningLevel
+	}
 	runID := fmt.Sprintf("run_%d", time.Now().Unix())
+	if opts.Attempt > 0 {
+		runID = fmt.Sprintf("%s_attempt%d", runID, opts.Attempt)
+	}
 	now := time.Now()
 	start := types.RunStart{
-		RunID:        runID,
-		Scenario:     scenarioName,
-		Workspace:    workspacePath,
-		Agent:        agentDef.Name,
-		AgentVersion: agentVersion,
-		Model:        modelName,
-		StartedAt:    now,
+		RunID:          runID,
+		Scenario:       scenarioName,
+		Workspace:      workspacePath,
+		Agent:          agentDef.Name,
+		AgentVersion:   agentVersion,
+		Model:          modelName,
+		ReasoningLevel: reasoningLevel,
+		StartedAt:      now,
 		System: types.SystemInfo{
 			OS:        runtime.GOOS,
 			Arch:      runtime.GOARCH,
@@ -255,136 +1060,269 @@ func runAgent(ctx context.Context, printer *output.Printer, workspacePath, scena
 	if err := writeJSON(runStartPath, start); err != nil {
 		return err
 	}
-	if onlyStart {
+	if opts.OnlyStart {
 		return printer.Appf("Wrote %s", runStartPath)
 	}
 
-	allowContinues := sc.Agent.AllowMultipleTurnsOnFailedVerify
+	// A staged scenario runs each stage's scenario in turn, resuming the session; otherwise there's one "stage": sc.
+	stages := sc.StageScenarios()
+	staged := len(stages) > 0
+	if !staged {
+		stages = []*scenario.Scenario{sc}
+	}
 	maxContinues := 3
-	continuesUsed := 0
 	session := ""
 	aggTokens := types.TokenUsage{}
 	var transcripts []string
 	lastNotes := ""
 	lastEnded := start.StartedAt
-	currentInstructions := strings.TrimSpace(sc.Agent.Instructions)
+	firstInstructions := resolveInstructions(stages[0], baseInstructions)
+	var stageResults []types.StageResult
+	var progress *types.RunProgress
+	firstOutputSeconds := 0.0
 
-	for turn := 1; ; turn++ {
-		if err := printer.Appf("Running agent %s (model=%s) turn %d", agentDef.Name, modelName, turn); err != nil {
+	agentEnv := sc.Agent.Env.Entries()
+	if len(agentEnv) > 0 {
+		if err := printer.Appf("Agent env: %s (values hidden)", strings.Join(sc.Agent.Env.Keys(), ", ")); err != nil {
 			return err
 		}
-		outcome, runErr := agentRunner(ctx, agents.RunContext{
-			ScenarioName: scenarioName,
-			ScenarioPath: workspaceDir,
-			ModelName:    modelName,
-			LLM:          llm,
-			Agent:        agentDef,
-			Instructions: currentInstructions,
-			Session:      session,
-			Options: agents.RunOptions{
-				Package: strings.TrimSpace(sc.Agent.Package),
-			},
-			Printer: printer,
-		})
-		if runErr != nil {
-			_ = printer.Appf("Agent run error: %v", runErr)
-		}
-		if outcome == nil || outcome.Progress == nil {
-			return fmt.Errorf("agent runner returned no progress")
+	}
+	if len(sc.Agent.DenyCommands) > 0 {
+		shim, err := cmdshim.Build(sc.Agent.DenyCommands)
+		if err != nil {
+			return fmt.Errorf("build agent.deny-commands shims: %w", err)
 		}
-
-		turnProgress := outcome.Progress
-		if s := strings.TrimSpace(turnProgress.Session); s != "" {
-			session = s
+		defer shim.Close()
+		agentEnv = append(agentEnv, shim.PathEnv(agentEnv))
+		if err := printer.Appf("Blocking agent commands via PATH shims: %s", strings.Join(sc.Agent.DenyCommands, ", ")); err != nil {
+			return err
 		}
-		aggTokens.Input += turnProgress.TokenUsage.Input
-		aggTokens.CachedInput += turnProgress.TokenUsage.CachedInput
-		aggTokens.WriteCachedInput += turnProgress.TokenUsage.WriteCachedInput
-		aggTokens.Output += turnProgress.TokenUsage.Output
-		aggTokens.Cost += turnProgress.TokenUsage.Cost
-		aggTokens.Total = aggTokens.Input + aggTokens.CachedInput + aggTokens.WriteCachedInput + aggTokens.Output
-		transcripts = append(transcripts, turnProgress.Transcripts...)
-		if turnProgress.Notes != "" {
-			lastNotes = turnProgress.Notes
+	}
+	var recorder *netrecord.Recorder
+	if sc.Agent.RecordNetwork {
+		// Kept out of the workspace, where the agent could tamper with it and where it would show up in the diff.
+		networkLogDir := filepath.Join(results.Dir(rootDir), scenarioName)
+		if err := os.MkdirAll(networkLogDir, 0o755); err != nil {
+			return fmt.Errorf("create network log dir: %w", err)
 		}
-		if turnProgress.EndedAt != nil {
-			lastEnded = *turnProgress.EndedAt
-		} else {
-			lastEnded = time.Now()
-		}
-
-		now = time.Now()
-		ended := lastEnded
-		durationScale := durationScaleFromProgress(turnProgress)
-		progress := &types.RunProgress{
-			RunID:           runID,
-			Scenario:        scenarioName,
-			Agent:           agentDef.Name,
-			AgentVersion:    agentVersion,
-			Model:           modelName,
-			StartedAt:       start.StartedAt,
-			UpdatedAt:       now,
-			EndedAt:         &ended,
-			Session:         session,
-			DurationSeconds: ended.Sub(start.StartedAt).Seconds() * durationScale,
-			TokenUsage:      aggTokens,
-			Transcripts:     transcripts,
-			Notes:           lastNotes,
+		networkLogPath := filepath.Join(networkLogDir, runID+".network.jsonl")
+		recorder, err = netrecord.Start(networkLogPath)
+		if err != nil {
+			return fmt.Errorf("start network recorder: %w", err)
 		}
-		if err := writeJSON(runProgressPath, progress); err != nil {
+		defer recorder.Close()
+		agentEnv = append(agentEnv, recorder.Env()...)
+		if err := printer.Appf("Recording agent network requests via %s to %s", recorder.URL(), networkLogPath); err != nil {
 			return err
 		}
-		if runErr != nil {
-			return fmt.Errorf("agent run failed: %w", runErr)
-		}
-		if !allowContinues {
-			break
-		}
+	}
 
-		verRes, err := verifyRunner(ctx, verify.Options{
-			ScenarioName:  scenarioName,
-			WorkspacePath: workspacePath,
-			RootPath:      rootDir,
-			OnlyReport:    true,
-			Printer:       printer,
-		}, sc)
-		if err != nil {
-			return err
+	turn := 0
+	for i, stageSc := range stages {
+		currentInstructions := strings.TrimSpace(stageSc.Agent.Instructions)
+		if i == 0 {
+			currentInstructions = firstInstructions
 		}
-		var summary string
-		var success bool
-		if verRes != nil && verRes.Report != nil {
-			summary = verify.DetailedString(verRes.Report)
-			success = verRes.Report.Success
+		if staged {
+			if err := printer.Appf("Stage %d of %d: %s", i+1, len(stages), sc.Stages[i].Name); err != nil {
+				return err
+			}
 		}
-		if success {
-			if err := printer.App("Verification passed; stopping."); err != nil {
+		allowContinues := stageSc.Agent.AllowMultipleTurnsOnFailedVerify
+		continuesUsed := 0
+		var stageReport *types.VerificationReport
+		for {
+			turn++
+			stageReport = nil
+			if err := printer.Appf("Running agent %s (model=%s) turn %d", agentDef.Name, modelName, turn); err != nil {
 				return err
 			}
+			outcome, runErr := agentRunner(ctx, agents.RunContext{
+				ScenarioName: scenarioName,
+				ScenarioPath: workspaceDir,
+				ModelName:    modelName,
+				LLM:          llm,
+				Agent:        agentDef,
+				Instructions: currentInstructions,
+				Session:      session,
+				Options: agents.RunOptions{
+					Package: strings.TrimSpace(sc.Agent.Package),
+					Env:     agentEnv,
+				},
+				Printer: printer,
+			})
+			if runErr != nil {
+				_ = printer.Appf("Agent run error: %v", runErr)
+			}
+			if outcome == nil || outcome.Progress == nil {
+				return fmt.Errorf("agent runner returned no progress")
+			}
+
+			turnProgress := outcome.Progress
+			if s := strings.TrimSpace(turnProgress.Session); s != "" {
+				session = s
+			}
+			aggTokens.Input += turnProgress.TokenUsage.Input
+			aggTokens.CachedInput += turnProgress.TokenUsage.CachedInput
+			aggTokens.WriteCachedInput += turnProgress.TokenUsage.WriteCachedInput
+			aggTokens.Output += turnProgress.TokenUsage.Output
+			aggTokens.Cost += turnProgress.TokenUsage.Cost
+			aggTokens.Total = aggTokens.Input + aggTokens.CachedInput + aggTokens.WriteCachedInput + aggTokens.Output
+			transcripts = append(transcripts, redactor.Strings(turnProgress.Transcripts)...)
+			if turn == 1 {
+				firstOutputSeconds = turnProgress.FirstOutputSeconds
+			}
+			if turnProgress.Notes != "" {
+				lastNotes = redactor.String(turnProgress.Notes)
+			}
+			if turnProgress.EndedAt != nil {
+				lastEnded = *turnProgress.EndedAt
+			} else {
+				lastEnded = time.Now()
+			}
+
+			now = time.Now()
+			ended := lastEnded
+			durationScale := durationScaleFromProgress(turnProgress)
+			progress = &types.RunProgress{
+				RunID:              runID,
+				Scenario:           scenarioName,
+				Agent:              agentDef.Name,
+				AgentVersion:       agentVersion,
+				Model:              modelName,
+				ReasoningLevel:     reasoningLevel,
+				ReasoningOverride:  llm != nil && llm.ReasoningOverride,
+				StartedAt:          start.StartedAt,
+				UpdatedAt:          now,
+				EndedAt:            &ended,
+				Session:            session,
+				DurationSeconds:    ended.Sub(start.StartedAt).Seconds() * durationScale,
+				FirstOutputSeconds: firstOutputSeconds,
+				TokenUsage:         aggTokens,
+				Transcripts:        transcripts,
+				Notes:              lastNotes,
+				Instructions:       firstInstructions,
+				Stages:             stageResults,
+				OverheadTokenUsage: overhead,
+			}
+			progress.TranscriptBytes, progress.TranscriptLines = transcriptSize(transcripts)
+			if recorder != nil {
+				progress.NetworkHosts = recorder.Hosts()
+			}
+			if err := writeJSON(runProgressPath, progress); err != nil {
+				return err
+			}
+			if runErr != nil {
+				return fmt.Errorf("agent run failed: %w", runErr)
+			}
+			if budget := sc.Agent.TokenBudget; budget > 0 && aggTokens.Total > budget {
+				// Stop every remaining turn and stage; verify still checks whatever the agent got done.
+				progress.TokenBudgetExceeded = true
+				if err := writeJSON(runProgressPath, progress); err != nil {
+					return err
+				}
+				if err := printer.Appf("Token budget exceeded (%d tokens used, budget %d); stopping.", aggTokens.Total, budget); err != nil {
+					return err
+				}
+				return printer.Appf("Run complete. Start: %s, progress: %s", runStartPath, runProgressPath)
+			}
+			if !allowContinues {
+				break
+			}
+
+			verRes, err := verifyRunner(ctx, verify.Options{
+				ScenarioName:  scenarioName,
+				WorkspacePath: workspacePath,
+				RootPath:      rootDir,
+				OnlyReport:    true,
+				Printer:       printer,
+			}, stageSc)
+			if err != nil {
+				return err
+			}
+			var summary string
+			var success bool
+			if verRes != nil && verRes.Report != nil {
+				stageReport = verRes.Report
+				summary = verify.DetailedString(verRes.Report)
+				success = verRes.Report.Success
+			}
+			if success {
+				if err := printer.App("Verification passed; stopping."); err != nil {
+					return err
+				}
+				break
+			}
+			if continuesUsed >= maxContinues {
+				if err := printer.Appf("Verification failed; reached continue limit (%d).", maxContinues); err != nil {
+					return err
+				}
+				break
+			}
+			continuesUsed++
+			if err := printer.Appf("Verification failed; continuing (attempt %d of %d).", continuesUsed, maxContinues); err != nil {
+				return err
+			}
+			nextPrompt := strings.TrimSpace(summary)
+			if nextPrompt != "" {
+				nextPrompt = fmt.Sprintf("%s\n\nPlease continue until the problem is solved.", nextPrompt)
+			} else {
+				nextPrompt = "Please continue until the problem is solved."
+			}
+			currentInstructions = nextPrompt
+		}
+		// The final stage is verified by verify itself, which folds in the earlier stages' outcomes.
+		if !staged || i == len(stages)-1 {
 			break
 		}
-		if continuesUsed >= maxContinues {
-			if err := printer.Appf("Verification failed; reached continue limit (%d).", maxContinues); err != nil {
+
+		// Record the stage's outcome before moving on, since later stages change the workspace.
+		if stageReport == nil {
+			verRes, err := verifyRunner(ctx, verify.Options{
+				ScenarioName:  scenarioName,
+				WorkspacePath: workspacePath,
+				RootPath:      rootDir,
+				OnlyReport:    true,
+				Printer:       printer,
+			}, stageSc)
+			if err != nil {
 				return err
 			}
-			break
+			if verRes != nil {
+				stageReport = verRes.Report
+			}
 		}
-		continuesUsed++
-		if err := printer.Appf("Verification failed; continuing (attempt %d of %d).", continuesUsed, maxContinues); err != nil {
-			return err
+		result := types.StageResult{Name: sc.Stages[i].Name}
+		if stageReport != nil {
+			result.Success = stageReport.Success
+			result.PartialScore = stageReport.PartialScore
+			result.Tests = stageReport.Tests
+			result.PartialTests = stageReport.PartialTests
 		}
-		nextPrompt := strings.TrimSpace(summary)
-		if nextPrompt != "" {
-			nextPrompt = fmt.Sprintf("%s\n\nPlease continue until the problem is solved.", nextPrompt)
-		} else {
-			nextPrompt = "Please continue until the problem is solved."
+		stageResults = append(stageResults, result)
+		progress.Stages = stageResults
+		if err := writeJSON(runProgressPath, progress); err != nil {
+			return err
 		}
-		currentInstructions = nextPrompt
 	}
 
 	return printer.Appf("Run complete. Start: %s, progress: %s", runStartPath, runProgressPath)
 }
 
+// runAgentSetupCommands runs agent.setup-commands in workspaceDir with the agent's env.
+func runAgentSetupCommands(ctx context.Context, printer *output.Printer, workspaceDir string, sc *scenario.Scenario) error {
+	for _, entry := range sc.Agent.SetupCommands {
+		cmd := strings.TrimSpace(entry)
+		if err := printer.Appf("Running agent setup command: %s", cmd); err != nil {
+			return err
+		}
+		if _, err := printer.RunCommandStreamingEnv(ctx, workspaceDir, sc.Agent.Env.Entries(), "sh", "-c", cmd); err != nil {
+			return fmt.Errorf("agent setup command %q failed: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
 func durationScaleFromProgress(progress *types.RunProgress) float64 {
 	if progress == nil || progress.EndedAt == nil || progress.DurationSeconds <= 0 {
 		return 1
@@ -403,30 +1341,58 @@ func durationScaleFromProgress(progress *types.RunProgress) float64 {
 func newVerifyCmd(workspacePath string) *cobra.Command {
 	var onlyReport bool
 	var copyOnly bool
+	var githubAnnotations bool
+	var junitPath string
+	var shuffle bool
+	var shuffleSeed int64
+	var baselinePath string
+	var runFilter string
+	var captureDiff bool
+	var captureStatus bool
+	var explainTargets bool
+	var parallelism int
 	cmd := silenceUsageAndErrors(&cobra.Command{
 		Use:   "verify <scenario>",
 		Short: "Verify an agent run for a scenario",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			printer := output.NewPrinter(os.Stdout)
+			printer := newPrinter("verify")
 			scenarioName, err := workspace.CleanScenario(args[0])
 			if err != nil {
 				return err
 			}
-			scenarioPath := workspace.ScenarioFile(scenarioName)
-			sc, err := scenario.Load(scenarioPath)
+			sc, err := loadRunnableScenario(scenarioName)
 			if err != nil {
 				return err
 			}
 			rootDir, _ := os.Getwd()
 			opts := verify.Options{
-				ScenarioName:  scenarioName,
-				WorkspacePath: workspacePath,
-				RootPath:      rootDir,
-				OnlyReport:    onlyReport,
-				CopyOnly:      copyOnly,
-				Printer:       printer,
+				ScenarioName:      scenarioName,
+				WorkspacePath:     workspacePath,
+				RootPath:          rootDir,
+				OnlyReport:        onlyReport,
+				CopyOnly:          copyOnly,
+				GitHubAnnotations: githubAnnotations,
+				JUnitPath:         junitPath,
+				Shuffle:           shuffle,
+				RunFilter:         runFilter,
+				CaptureDiff:       captureDiff,
+				CaptureStatus:     captureStatus,
+				Parallelism:       parallelism,
+				Printer:           printer,
+			}
+			if cmd.Flags().Changed("shuffle-seed") {
+				opts.ShuffleSeed = &shuffleSeed
+			}
+			if explainTargets {
+				return verify.ExplainTargets(printer, opts, sc)
+			}
+			if baselinePath != "" {
+				opts.Baseline, err = verify.LoadReport(baselinePath)
+				if err != nil {
+					return fmt.Errorf("load baseline: %w", err)
+				}
 			}
 			_, err = verify.Run(ctx, opts, sc)
 			return err
@@ -434,9 +1400,33 @@ func newVerifyCmd(workspacePath string) *cobra.Command {
 	})
 	cmd.Flags().BoolVar(&onlyReport, "only-report", false, "print report without writing results file")
 	cmd.Flags().BoolVar(&copyOnly, "copy-only", false, "apply verify.copy steps only (no tests; no cleanup)")
+	cmd.Flags().StringVar(&junitPath, "junit", "", "also write results as JUnit XML to this file")
+	cmd.Flags().BoolVar(&githubAnnotations, "github-annotations", false, "print failures as GitHub Actions ::error annotations instead of the summary")
+	cmd.Flags().BoolVar(&shuffle, "shuffle", false, "run tests with go test -shuffle using a random seed (recorded in the report)")
+	cmd.Flags().Int64Var(&shuffleSeed, "shuffle-seed", 0, "run tests with go test -shuffle using this seed (implies --shuffle)")
+	cmd.Flags().StringVar(&runFilter, "run", "", "only run tests matching this -run pattern, replacing the scenario's patterns")
+	cmd.Flags().StringVar(&baselinePath, "compare-baseline", "", "diff test outcomes against this known-good .verify.json (diagnostic only)")
+	cmd.Flags().BoolVar(&captureDiff, "capture-diff", false, "store the agent's unified diff (truncated past 256 KiB) in the results file")
+	cmd.Flags().BoolVar(&captureStatus, "capture-status", false, "store the agent's git status --porcelain (staged, modified, and untracked files) in the results file")
+	cmd.Flags().IntVar(&parallelism, "parallelism", 1, "run up to this many verify.tests entries at once (output is still printed per entry, in order)")
+	cmd.Flags().BoolVar(&explainTargets, "explain-targets", false, "print how each test entry resolves to a go test command, then exit without running")
 	return cmd
 }
 
+// transcriptSize returns the total bytes and lines of transcripts. A transcript's last line counts even without a
+// trailing newline.
+func transcriptSize(transcripts []string) (int, int) {
+	size, lines := 0, 0
+	for _, t := range transcripts {
+		size += len(t)
+		lines += strings.Count(t, "\n")
+		if t != "" && !strings.HasSuffix(t, "\n") {
+			lines++
+		}
+	}
+	return size, lines
+}
+
 func silenceUsageAndErrors(cmd *cobra.Command) *cobra.Command {
 	silenceErrors(cmd)
 	cmd.SilenceUsage = true
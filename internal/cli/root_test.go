@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/codalotl/goagentbench/internal/types"
 	"github.com/codalotl/goagentbench/internal/verify"
+	"github.com/codalotl/goagentbench/internal/workspace"
 )
 
 var runnerStubMu sync.Mutex
@@ -82,7 +87,7 @@ func TestRunAgentFailsWithoutRunningVerify(t *testing.T) {
 	}
 
 	printer := output.NewPrinter(io.Discard)
-	err := runAgent(context.Background(), printer, workspacePath, scenarioName, agentDef, "test-model", nil, sc, false)
+	err := runAgent(context.Background(), printer, workspacePath, scenarioName, agentDef, "test-model", nil, sc, runAgentOptions{})
 
 	require.Error(t, err)
 	require.ErrorContains(t, err, "agent run failed")
@@ -156,7 +161,7 @@ func TestRunAgentPersistsScaledDurationSeconds(t *testing.T) {
 	}
 
 	printer := output.NewPrinter(io.Discard)
-	err := runAgent(context.Background(), printer, workspacePath, scenarioName, agentDef, "test-model", nil, sc, false)
+	err := runAgent(context.Background(), printer, workspacePath, scenarioName, agentDef, "test-model", nil, sc, runAgentOptions{})
 	require.NoError(t, err)
 
 	data, err := os.ReadFile(filepath.Join(workspaceDir, ".run-progress.json"))
@@ -165,3 +170,1089 @@ func TestRunAgentPersistsScaledDurationSeconds(t *testing.T) {
 	require.NoError(t, json.Unmarshal(data, &progress))
 	require.InDelta(t, 18.0, progress.DurationSeconds, 1e-9)
 }
+
+func TestApplyReasoningOverride(t *testing.T) {
+	llm := &agents.LLMDefinition{Name: "gpt", ReasoningLevel: "high"}
+
+	require.NoError(t, applyReasoningOverride(llm, ""))
+	require.Equal(t, "high", llm.ReasoningLevel)
+	require.False(t, llm.ReasoningOverride)
+
+	require.Error(t, applyReasoningOverride(llm, "extreme"))
+	require.Equal(t, "high", llm.ReasoningLevel)
+
+	require.NoError(t, applyReasoningOverride(llm, "low"))
+	require.Equal(t, "low", llm.ReasoningLevel)
+	require.True(t, llm.ReasoningOverride)
+}
+
+func TestRepeatUntilSuccess(t *testing.T) {
+	outcomes := []bool{false, false, true, false}
+	var attempts []int
+	reports, err := repeatUntilSuccess(5, func(n int) (*types.VerificationReport, error) {
+		attempts = append(attempts, n)
+		return &types.VerificationReport{RunID: fmt.Sprintf("run_%d", n), Success: outcomes[n-1]}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, attempts)
+	require.Equal(t, "Succeeded on attempt 3 of 5. Runs: run_1, run_2, run_3", attemptsSummary(reports, 5))
+
+	reports, err = repeatUntilSuccess(2, func(n int) (*types.VerificationReport, error) {
+		return &types.VerificationReport{RunID: fmt.Sprintf("run_%d", n)}, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.Equal(t, "No successful verification in 2 attempts. Runs: run_1, run_2", attemptsSummary(reports, 2))
+
+	_, err = repeatUntilSuccess(3, func(n int) (*types.VerificationReport, error) {
+		return nil, errors.New("clone failed")
+	})
+	require.EqualError(t, err, "attempt 1: clone failed")
+
+	// A single attempt (no --repeat-until-success) returns errors as is.
+	_, err = repeatUntilSuccess(1, func(n int) (*types.VerificationReport, error) {
+		return nil, errors.New("clone failed")
+	})
+	require.EqualError(t, err, "clone failed")
+}
+
+func TestRunAgentScopesEnvToAgent(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+
+	sc := &scenario.Scenario{
+		Agent: scenario.AgentConfig{
+			Instructions:                     "do something",
+			AllowMultipleTurnsOnFailedVerify: true,
+			Env:                              scenario.SecretEnv{"GAB_TEST_SECRET": "from-yml", "GAB_TEST_OTHER": "x"},
+		},
+	}
+	require.NoError(t, applyAgentEnvFlags(sc, []string{"GAB_TEST_SECRET=from-flag"}))
+	require.Error(t, applyAgentEnvFlags(sc, []string{"NOEQUALS"}))
+	require.Error(t, applyAgentEnvFlags(sc, []string{"BAD-KEY=1"}))
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+		verifyRunner = origVerifyRunner
+	})
+
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var gotEnv []string
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		gotEnv = rc.Options.Env
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now}}, nil
+	}
+	verifyRan := false
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		verifyRan = true
+		// verify subprocesses inherit this process's environment, which must not contain agent env.
+		require.Empty(t, os.Getenv("GAB_TEST_SECRET"))
+		return &verify.Result{Report: &types.VerificationReport{Success: true}}, nil
+	}
+
+	var out bytes.Buffer
+	printer := output.NewPrinter(&out)
+	err := runAgent(context.Background(), printer, workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+	require.True(t, verifyRan)
+	require.Equal(t, []string{"GAB_TEST_OTHER=x", "GAB_TEST_SECRET=from-flag"}, gotEnv)
+	require.Contains(t, out.String(), "GAB_TEST_OTHER, GAB_TEST_SECRET (values hidden)")
+	require.NotContains(t, out.String(), "from-flag")
+}
+
+func TestRunAgentDeniesCommands(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+
+	sc := &scenario.Scenario{
+		Agent: scenario.AgentConfig{
+			Instructions: "do something",
+			DenyCommands: []string{"curl"},
+		},
+	}
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+		verifyRunner = origVerifyRunner
+	})
+
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var curlOutput string
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		cmd := exec.Command("sh", "-c", "curl https://example.com")
+		cmd.Env = append(os.Environ(), rc.Options.Env...)
+		out, err := cmd.CombinedOutput()
+		require.Error(t, err)
+		curlOutput = string(out)
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now}}, nil
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		return &verify.Result{Report: &types.VerificationReport{Success: true}}, nil
+	}
+
+	var out bytes.Buffer
+	err := runAgent(context.Background(), output.NewPrinter(&out), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+	require.Contains(t, curlOutput, "curl is blocked by agent.deny-commands")
+	require.Contains(t, out.String(), "Blocking agent commands via PATH shims: curl")
+}
+
+func TestRunAgentMeasuresOverhead(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{Instructions: "do something"}}
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var prompts, dirs []string
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		prompts = append(prompts, rc.Instructions)
+		dirs = append(dirs, rc.ScenarioPath)
+		if rc.Instructions == overheadInstructions {
+			// The no-op turn's side effects must not reach the workspace.
+			require.NoError(t, os.WriteFile(filepath.Join(rc.ScenarioPath, "stray.txt"), []byte("x"), 0o644))
+		}
+		usage := types.TokenUsage{Input: 1000, Output: 10, Cost: 0.01}
+		if len(prompts) == 2 {
+			usage = types.TokenUsage{Input: 5000, Output: 500, Cost: 0.05}
+		}
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now, TokenUsage: usage}}, nil
+	}
+
+	var out bytes.Buffer
+	err := runAgent(context.Background(), output.NewPrinter(&out), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{MeasureOverhead: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{overheadInstructions, "do something"}, prompts)
+	require.Equal(t, filepath.Join(workspacePath, scenarioName), dirs[1])
+	require.NotEqual(t, dirs[1], dirs[0])
+	require.NoDirExists(t, dirs[0])
+	require.NoFileExists(t, filepath.Join(workspacePath, scenarioName, "stray.txt"))
+	require.Contains(t, out.String(), "Agent overhead: 1010 tokens, cost 0.01.")
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, scenarioName, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.Equal(t, 5500, progress.TokenUsage.Total)
+	require.InDelta(t, 0.05, progress.TokenUsage.Cost, 1e-9)
+	require.NotNil(t, progress.OverheadTokenUsage)
+	require.Equal(t, types.TokenUsage{Input: 1000, Output: 10, Total: 1010, Cost: 0.01}, *progress.OverheadTokenUsage)
+}
+
+func TestLoadAndResolveBaseInstructions(t *testing.T) {
+	root := t.TempDir()
+	base, err := loadBaseInstructions(root, "")
+	require.NoError(t, err)
+	require.Empty(t, base)
+	_, err = loadBaseInstructions(root, "missing.md")
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, defaultBaseInstructionsFile), []byte("You are in a Go repo.\n"), 0o644))
+	base, err = loadBaseInstructions(root, "")
+	require.NoError(t, err)
+	require.Equal(t, "You are in a Go repo.", base)
+
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{Instructions: "Fix the bug."}}
+	require.Equal(t, "You are in a Go repo.\n\nFix the bug.", resolveInstructions(sc, base))
+	require.Equal(t, "Fix the bug.", resolveInstructions(sc, ""))
+	sc.Agent.SkipBaseInstructions = true
+	require.Equal(t, "Fix the bug.", resolveInstructions(sc, base))
+}
+
+func TestRunAgentRecordsBaseInstructions(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+	preamble := filepath.Join(t.TempDir(), "preamble.md")
+	require.NoError(t, os.WriteFile(preamble, []byte("Shared preamble."), 0o644))
+
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{Instructions: "do something"}}
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var gotInstructions string
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		gotInstructions = rc.Instructions
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now}}, nil
+	}
+
+	err := runAgent(context.Background(), output.NewPrinter(io.Discard), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{BaseInstructionsFile: preamble})
+	require.NoError(t, err)
+	require.Equal(t, "Shared preamble.\n\ndo something", gotInstructions)
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, scenarioName, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.Equal(t, "Shared preamble.\n\ndo something", progress.Instructions)
+}
+
+func TestRunAgentVersionDrift(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() { agentVersionChecker = origAgentVersionChecker })
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return "v2", nil
+	}
+
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{Instructions: "do something"}}
+	agentDef := agents.Definition{Name: "dummy", Version: "v1"}
+
+	workspacePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, "demo"), 0o755))
+	printer := output.NewPrinter(io.Discard)
+	err := runAgent(context.Background(), printer, workspacePath, "demo", agentDef, "test-model", nil, sc, runAgentOptions{OnlyStart: true})
+	require.ErrorContains(t, err, "version mismatch: expected v1, got v2")
+
+	var out bytes.Buffer
+	err = runAgent(context.Background(), output.NewPrinter(&out), workspacePath, "demo", agentDef, "test-model", nil, sc, runAgentOptions{OnlyStart: true, AllowVersionDrift: true})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "version drift")
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, "demo", ".run-start.json"))
+	require.NoError(t, err)
+	var start types.RunStart
+	require.NoError(t, json.Unmarshal(data, &start))
+	require.Equal(t, "v2", start.AgentVersion)
+}
+
+func TestRunAgentRunsStagesInOrder(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+
+	sc := &scenario.Scenario{Stages: []scenario.Stage{
+		{Name: "fix-a", Instructions: "fix A"},
+		{Name: "fix-b", Instructions: "fix B"},
+	}}
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+		verifyRunner = origVerifyRunner
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var gotInstructions, gotSessions []string
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		gotInstructions = append(gotInstructions, rc.Instructions)
+		gotSessions = append(gotSessions, rc.Session)
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now, Session: "sess-1"}}, nil
+	}
+	var verified []string
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		verified = append(verified, sc.Agent.Instructions)
+		return &verify.Result{Report: &types.VerificationReport{Success: true}}, nil
+	}
+
+	err := runAgent(context.Background(), output.NewPrinter(io.Discard), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"fix A", "fix B"}, gotInstructions)
+	require.Equal(t, []string{"", "sess-1"}, gotSessions)
+	// Only the first stage is verified by run-agent; verify handles the last.
+	require.Equal(t, []string{"fix A"}, verified)
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, scenarioName, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.Equal(t, []types.StageResult{{Name: "fix-a", Success: true}}, progress.Stages)
+}
+
+func TestRunAgentRunsSetupCommandsBeforeAgent(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	var prepared string
+	agentCalls := 0
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		agentCalls++
+		data, _ := os.ReadFile(filepath.Join(rc.ScenarioPath, "agent-config"))
+		prepared = string(data)
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{
+		Instructions:  "do something",
+		Env:           scenario.SecretEnv{"AGENT_MODE": "fast"},
+		SetupCommands: scenario.StringList{`printf "$AGENT_MODE" > agent-config`},
+	}}
+	err := runAgent(context.Background(), output.NewPrinter(io.Discard), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, agentCalls)
+	require.Equal(t, "fast", prepared)
+
+	failing := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(failing, scenarioName), 0o755))
+	sc.Agent.SetupCommands = scenario.StringList{"exit 1"}
+	err = runAgent(context.Background(), output.NewPrinter(io.Discard), failing, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.ErrorContains(t, err, `agent setup command "exit 1" failed`)
+	require.Equal(t, 1, agentCalls)
+	require.NoFileExists(t, filepath.Join(failing, scenarioName, ".run-start.json"))
+}
+
+func TestParseExecPhases(t *testing.T) {
+	phases, err := parseExecPhases("setup,run,verify")
+	require.NoError(t, err)
+	require.Equal(t, execPhases{setup: true, run: true, verify: true}, phases)
+
+	phases, err = parseExecPhases("setup, run")
+	require.NoError(t, err)
+	require.Equal(t, execPhases{setup: true, run: true}, phases)
+
+	phases, err = parseExecPhases("verify")
+	require.NoError(t, err)
+	require.Equal(t, execPhases{verify: true}, phases)
+
+	_, err = parseExecPhases("run,setup")
+	require.ErrorContains(t, err, "must be in order")
+	_, err = parseExecPhases("run,run")
+	require.ErrorContains(t, err, "no repeats")
+	_, err = parseExecPhases("build")
+	require.ErrorContains(t, err, `unknown phase "build"`)
+	_, err = parseExecPhases("")
+	require.EqualError(t, err, "--phases cannot be empty")
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, "demo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, "demo", "scenario.yml"), []byte(`name: demo
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+  has-spec: false
+  single-package: true
+  sees-failing-tests: false
+agent:
+  instructions: do it
+verify:
+  tests:
+    - ./...
+`), 0o644))
+
+	origSetupRunner := setupRunner
+	t.Cleanup(func() { setupRunner = origSetupRunner })
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		// The deadline must reach subprocesses.
+		return exec.CommandContext(ctx, "sleep", "10").Run()
+	}
+
+	root := newRootCmd(t.TempDir())
+	root.SetArgs([]string{"--timeout=200ms", "exec", "--phases=setup", "demo"})
+	start := time.Now()
+	err := execute(context.Background(), root)
+	require.ErrorIs(t, err, errRootTimeout)
+	require.ErrorContains(t, err, "--timeout exceeded (200ms): ")
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	root = newRootCmd(t.TempDir())
+	root.SetArgs([]string{"--timeout=-1s", "exec", "--phases=setup", "demo"})
+	require.EqualError(t, execute(context.Background(), root), "--timeout must be >= 0, got -1s")
+}
+
+func TestExecRunsOnlySelectedPhases(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, "demo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, "demo", "scenario.yml"), []byte(`name: demo
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+  has-spec: false
+  single-package: true
+  sees-failing-tests: false
+agent:
+  instructions: do it
+verify:
+  tests:
+    - ./...
+`), 0o644))
+
+	origSetupRunner := setupRunner
+	origAgentRunner := agentRunner
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		setupRunner = origSetupRunner
+		agentRunner = origAgentRunner
+		verifyRunner = origVerifyRunner
+	})
+	var ran []string
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		ran = append(ran, "setup")
+		return nil
+	}
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		ran = append(ran, "run")
+		return nil, errors.New("agent should not run")
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		ran = append(ran, "verify")
+		return &verify.Result{Report: &types.VerificationReport{Success: true}}, nil
+	}
+
+	for _, tc := range []struct {
+		phases string
+		want   []string
+	}{
+		{phases: "setup", want: []string{"setup"}},
+		{phases: "verify", want: []string{"verify"}},
+		{phases: "setup,verify", want: []string{"setup", "verify"}},
+	} {
+		ran = nil
+		cmd := newExecCmd(t.TempDir())
+		cmd.SetArgs([]string{"--phases=" + tc.phases, "demo"})
+		require.NoError(t, cmd.ExecuteContext(context.Background()), tc.phases)
+		require.Equal(t, tc.want, ran, tc.phases)
+	}
+
+	cmd := newExecCmd(t.TempDir())
+	cmd.SetArgs([]string{"--phases=setup,run", "demo"})
+	require.EqualError(t, cmd.ExecuteContext(context.Background()), "--agent is required")
+}
+
+func TestExecNoVerify(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, "demo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, "demo", "scenario.yml"), []byte(`name: demo
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+  has-spec: false
+  single-package: true
+  sees-failing-tests: false
+agent:
+  instructions: do it
+verify:
+  tests:
+    - ./...
+`), 0o644))
+
+	origSetupRunner := setupRunner
+	origVerifyRunner := verifyRunner
+	origUnverifiedRecorder := unverifiedRecorder
+	t.Cleanup(func() {
+		setupRunner = origSetupRunner
+		verifyRunner = origVerifyRunner
+		unverifiedRecorder = origUnverifiedRecorder
+	})
+	var ran []string
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		ran = append(ran, "setup")
+		return nil
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		ran = append(ran, "verify")
+		return &verify.Result{Report: &types.VerificationReport{Success: true}}, nil
+	}
+	unverifiedRecorder = func(opts verify.Options) (*types.VerificationReport, error) {
+		ran = append(ran, "record:"+opts.ScenarioName)
+		return &types.VerificationReport{RunID: "run_1", Unverified: true}, nil
+	}
+
+	// Flag conflicts are rejected before anything runs.
+	for _, tc := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--no-verify", "--phases=setup,run,verify", "demo"}, wantErr: "--no-verify cannot be combined with verify in --phases"},
+		{args: []string{"--no-verify", "--phases=setup", "demo"}, wantErr: "--no-verify requires run in --phases"},
+		{args: []string{"--no-verify", "--agent=codex", "--repeat-until-success", "demo"}, wantErr: "--no-verify cannot be combined with --repeat-until-success or --resume-sweep"},
+	} {
+		cmd := newExecCmd(t.TempDir())
+		cmd.SetArgs(tc.args)
+		require.EqualError(t, cmd.ExecuteContext(context.Background()), tc.wantErr)
+	}
+	require.Empty(t, ran)
+
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "agents.yml"), []byte("agents:\n  - name: dummy\n    version: v1\n    supports-llms: [m]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "llms.yml"), []byte("llms:\n  - name: m\n    model: m\n"), 0o644))
+	t.Chdir(rootDir)
+	workspacePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, "demo"), 0o755))
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		ran = append(ran, "run")
+		return &agents.RunOutcome{Progress: &types.RunProgress{Agent: rc.Agent.Name}}, nil
+	}
+	cmd := newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--no-verify", "--agent=dummy", "demo"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, []string{"setup", "run", "record:demo"}, ran)
+}
+
+func TestListScenarios(t *testing.T) {
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	writeScenario := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, name, "scenario.yml"), []byte(content), 0o644))
+	}
+	writeScenario("b/good", "name: good\nrepo: github.com/x/y\nclassification:\n  type: fix-bug\n")
+	writeScenario("a/broken", "name: [\n")
+	// Files belonging to a scenario aren't scenarios themselves.
+	writeScenario("b/good/data", "name: nested\n")
+	writeScenario("b/good/z_data", "name: nested\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, "empty"), 0o755))
+
+	var out bytes.Buffer
+	cmd := newListScenariosCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--json"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	var listings []scenarioListing
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listings))
+	require.Len(t, listings, 2)
+	require.Equal(t, "a/broken", listings[0].Name)
+	require.NotEmpty(t, listings[0].Error)
+	require.Equal(t, scenarioListing{Name: "b/good", Type: "fix-bug", Repo: "github.com/x/y"}, listings[1])
+
+	out.Reset()
+	cmd = newListScenariosCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "NAME      TYPE     REPO", strings.TrimSpace(lines[0]))
+	require.Contains(t, lines[1], "a/broken")
+	require.Contains(t, lines[1], "error: ")
+	require.Equal(t, "b/good    fix-bug  github.com/x/y", lines[2])
+}
+
+func TestListAgents(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "agents.yml"), []byte(`agents:
+  - name: beta
+    version: 2.0.0
+    supports-llms: [llm-b, llm-gone]
+  - name: alpha
+    version: 1.0.0
+    supports-llms: [llm-a, llm-b]
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "llms.yml"), []byte(`llms:
+  - name: llm-a
+    model: model-a
+    reasoning-level: high
+  - name: llm-b
+    model: model-b
+    per-agent:
+      beta: beta-model-b
+`), 0o644))
+	t.Chdir(rootDir)
+
+	list := func(args ...string) ([]string, error) {
+		var out bytes.Buffer
+		cmd := newListAgentsCmd()
+		cmd.SetOut(&out)
+		// A nil slice would make cobra parse the test binary's own args.
+		cmd.SetArgs(append([]string{}, args...))
+		err := cmd.ExecuteContext(context.Background())
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			lines = append(lines, strings.Join(strings.Fields(line), " "))
+		}
+		return lines, err
+	}
+
+	lines, err := list()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"AGENT VERSION LLM MODEL REASONING",
+		"alpha 1.0.0 llm-a model-a high default",
+		"alpha 1.0.0 llm-b model-b",
+		"beta 2.0.0 llm-b beta-model-b default",
+		"beta 2.0.0 llm-gone error: missing from llms.yml",
+	}, lines)
+
+	lines, err = list("--agent=beta")
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	require.Equal(t, "beta 2.0.0 llm-b beta-model-b default", lines[1])
+
+	_, err = list("--agent=gamma")
+	require.EqualError(t, err, `unknown agent "gamma"`)
+}
+
+func TestExecResumeSweepSkipsCompletedCombos(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	for _, name := range []string{"one", "two"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, name, "scenario.yml"), []byte(`name: `+name+`
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+agent:
+  instructions: do it
+`), 0o644))
+	}
+
+	origSetupRunner := setupRunner
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		setupRunner = origSetupRunner
+		verifyRunner = origVerifyRunner
+	})
+	var ran []string
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		ran = append(ran, "setup "+scenarioName)
+		return nil
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		ran = append(ran, "verify "+opts.ScenarioName)
+		if opts.ScenarioName == "two" {
+			return nil, errors.New("crashed")
+		}
+		return &verify.Result{Report: &types.VerificationReport{RunID: "run_1", Success: true}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	sweep := func(wantErr bool) {
+		t.Helper()
+		for _, name := range []string{"one", "two"} {
+			cmd := newExecCmd(workspacePath)
+			cmd.SetArgs([]string{"--phases=setup,verify", "--resume-sweep", name})
+			err := cmd.ExecuteContext(context.Background())
+			if name == "two" && wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		}
+	}
+
+	sweep(true)
+	require.Equal(t, []string{"setup one", "verify one", "setup two", "verify two"}, ran)
+	state, err := loadSweepState(sweepStatePath(workspacePath))
+	require.NoError(t, err)
+	require.Len(t, state.Completed, 1)
+	require.Equal(t, "one", state.Completed[0].Scenario)
+	require.Equal(t, "run_1", state.Completed[0].RunID)
+	require.True(t, state.Completed[0].Success)
+
+	// The re-invoked sweep skips the completed combo and retries the one that crashed.
+	ran = nil
+	sweep(true)
+	require.Equal(t, []string{"setup two", "verify two"}, ran)
+
+	// Only the reasoning level differs, so this is a new combo; a re-run at the same level skips it.
+	ran = nil
+	for range 2 {
+		cmd := newExecCmd(workspacePath)
+		cmd.SetArgs([]string{"--phases=setup,verify", "--resume-sweep", "--reasoning=high", "one"})
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+	}
+	require.Equal(t, []string{"setup one", "verify one"}, ran)
+	state, err = loadSweepState(sweepStatePath(workspacePath))
+	require.NoError(t, err)
+	require.Len(t, state.Completed, 2)
+	require.Equal(t, "high", state.Completed[1].Reasoning)
+
+	cmd := newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup", "--resume-sweep", "one"})
+	require.EqualError(t, cmd.ExecuteContext(context.Background()), "--resume-sweep requires verify in --phases")
+}
+
+func TestExecRunsMatrixInstances(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, "mathx"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, "mathx", "scenario.yml"), []byte(`name: mathx-{{.func}}
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+matrix:
+  func: [Add, Sub]
+agent:
+  instructions: Fix {{.func}}.
+`), 0o644))
+
+	origSetupRunner := setupRunner
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		setupRunner = origSetupRunner
+		verifyRunner = origVerifyRunner
+	})
+	var ran []string
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		require.Nil(t, sc.Matrix)
+		ran = append(ran, "setup "+scenarioName+": "+sc.Agent.Instructions)
+		return nil
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		ran = append(ran, "verify "+opts.ScenarioName)
+		return &verify.Result{Report: &types.VerificationReport{RunID: "run_1", Success: true}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	cmd := newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup,verify", "mathx"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, []string{
+		"setup mathx@func=Add: Fix Add.", "verify mathx@func=Add",
+		"setup mathx@func=Sub: Fix Sub.", "verify mathx@func=Sub",
+	}, ran)
+
+	// One instance can be run on its own; the bare scenario can't, outside exec.
+	ran = nil
+	cmd = newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup,verify", "mathx@func=Sub"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, []string{"setup mathx@func=Sub: Fix Sub.", "verify mathx@func=Sub"}, ran)
+
+	cmd = newSetupCmd(workspacePath)
+	cmd.SetArgs([]string{"mathx"})
+	require.ErrorContains(t, cmd.ExecuteContext(context.Background()), "has a matrix: name one instance as mathx@<instance>")
+	cmd = newSetupCmd(workspacePath)
+	cmd.SetArgs([]string{"mathx@func=Mul"})
+	require.ErrorContains(t, cmd.ExecuteContext(context.Background()), `has no matrix instance "func=Mul"`)
+}
+
+func TestExecMultipleScenarios(t *testing.T) {
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	for _, name := range []string{"one", "two", "three"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(scenarioRoot, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(scenarioRoot, name, "scenario.yml"), []byte(`name: `+name+`
+repo: github.com/codalotl/goagentbench
+commit: ef870776d6eb5a24690accf00617f8dad7fb0d48
+classification:
+  type: build-package
+agent:
+  instructions: do it
+`), 0o644))
+	}
+
+	origSetupRunner := setupRunner
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		setupRunner = origSetupRunner
+		verifyRunner = origVerifyRunner
+	})
+	var ran []string
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		ran = append(ran, "setup "+scenarioName)
+		if scenarioName == "two" {
+			return errors.New("clone failed")
+		}
+		return nil
+	}
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		ran = append(ran, "verify "+opts.ScenarioName)
+		return &verify.Result{Report: &types.VerificationReport{RunID: "run_" + opts.ScenarioName, Success: opts.ScenarioName == "three"}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	scenariosFile := filepath.Join(t.TempDir(), "scenarios.txt")
+	require.NoError(t, os.WriteFile(scenariosFile, []byte("# the rest of the sweep\n\nthree\n"), 0o644))
+	cmd := newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup,verify", "--scenarios-file", scenariosFile, "one", "two"})
+	// A setup failure in two doesn't stop three, but the command still fails.
+	require.EqualError(t, cmd.ExecuteContext(context.Background()), "1 of 3 scenario(s) errored")
+	require.Equal(t, []string{"setup one", "verify one", "setup two", "setup three", "verify three"}, ran)
+
+	// --fail-fast stops at the first failed verification.
+	ran = nil
+	cmd = newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup,verify", "--fail-fast", "one", "three"})
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	require.Equal(t, []string{"setup one", "verify one"}, ran)
+
+	// Cancellation stops the sweep after the running scenario.
+	ran = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupRunner = func(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+		ran = append(ran, "setup "+scenarioName)
+		cancel()
+		return nil
+	}
+	cmd = newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup", "one", "two", "three"})
+	require.EqualError(t, cmd.ExecuteContext(ctx), "2 of 3 scenario(s) not run: context canceled")
+	require.Equal(t, []string{"setup one"}, ran)
+
+	cmd = newExecCmd(workspacePath)
+	cmd.SetArgs([]string{"--phases=setup,verify"})
+	require.EqualError(t, cmd.ExecuteContext(context.Background()), "exec needs at least one scenario (as arguments or with --scenarios-file)")
+}
+
+func TestExecSummary(t *testing.T) {
+	rows := []execSummaryRow{
+		{scenario: "one", outcome: execOutcome{report: &types.VerificationReport{RunID: "run_1", Success: true}}},
+		{scenario: "two", err: errors.New("setup:\n  clone failed")},
+		{scenario: "three", outcome: execOutcome{report: &types.VerificationReport{RunID: "run_3"}}},
+	}
+	require.Equal(t, `SCENARIO  RESULT   RUN    NOTE
+one       pass     run_1
+two       error           setup: clone failed
+three     fail     run_3
+four      not run         stopped by --fail-fast
+4 scenario(s): 1 pass, 1 fail, 1 error, 1 not run`, execSummary([]string{"one", "two", "three", "four"}, rows, "stopped by --fail-fast"))
+}
+
+func TestRecordSweepComboConcurrentWriters(t *testing.T) {
+	path := sweepStatePath(t.TempDir())
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- recordSweepCombo(path, sweepCombo{Scenario: fmt.Sprintf("sc%d", i), Agent: "codex", Model: "gpt"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	state, err := loadSweepState(path)
+	require.NoError(t, err)
+	require.Len(t, state.Completed, 20)
+}
+
+func TestRunAgentManualComplete(t *testing.T) {
+	workspacePath := t.TempDir()
+	workspaceDir := filepath.Join(workspacePath, "demo")
+	require.NoError(t, os.MkdirAll(workspaceDir, 0o755))
+
+	run := func(args ...string) error {
+		cmd := newRunAgentCmd(workspacePath)
+		cmd.SetArgs(append(args, "demo"))
+		return cmd.ExecuteContext(context.Background())
+	}
+	require.EqualError(t, run("--manual-complete"), "no run started at "+filepath.Join(workspaceDir, ".run-start.json")+"; run run-agent --only-start first")
+
+	startedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, writeJSON(filepath.Join(workspaceDir, ".run-start.json"), types.RunStart{
+		RunID:        "run_1",
+		Scenario:     "demo",
+		Agent:        "manual",
+		AgentVersion: "1",
+		Model:        "gpt",
+		StartedAt:    startedAt,
+	}))
+	require.EqualError(t, run("--agent=manual", "--output-tokens=5"), "--output-tokens requires --manual-complete")
+	require.NoError(t, run("--manual-complete", "--duration=90s", "--input-tokens=100", "--cached-input-tokens=20", "--output-tokens=50", "--cost=0.25"))
+
+	data, err := os.ReadFile(filepath.Join(workspaceDir, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.Equal(t, "run_1", progress.RunID)
+	require.Equal(t, "manual", progress.Agent)
+	require.Equal(t, "gpt", progress.Model)
+	require.True(t, progress.StartedAt.Equal(startedAt))
+	require.NotNil(t, progress.EndedAt)
+	require.True(t, progress.EndedAt.Equal(startedAt.Add(90*time.Second)))
+	require.Equal(t, 90.0, progress.DurationSeconds)
+	require.Equal(t, types.TokenUsage{Input: 100, CachedInput: 20, Output: 50, Total: 170, Cost: 0.25}, progress.TokenUsage)
+
+	require.ErrorContains(t, run("--manual-complete"), "run already recorded at")
+}
+
+func TestRunAgentStopsAtTokenBudget(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	origVerifyRunner := verifyRunner
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+		verifyRunner = origVerifyRunner
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	agentCalls := 0
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		agentCalls++
+		now := time.Now()
+		// Each turn uses more tokens than the last: 400, 800, ...
+		usage := types.TokenUsage{Input: 300 * agentCalls, Output: 100 * agentCalls}
+		return &agents.RunOutcome{Progress: &types.RunProgress{StartedAt: now, UpdatedAt: now, EndedAt: &now, TokenUsage: usage}}, nil
+	}
+	verifyCalls := 0
+	verifyRunner = func(ctx context.Context, opts verify.Options, sc *scenario.Scenario) (*verify.Result, error) {
+		verifyCalls++
+		return &verify.Result{Report: &types.VerificationReport{Success: false}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{
+		Instructions:                     "do something",
+		AllowMultipleTurnsOnFailedVerify: true,
+		TokenBudget:                      1000,
+	}}
+	err := runAgent(context.Background(), output.NewPrinter(io.Discard), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+	// Turn 1 (400 tokens) is under budget and fails verify; turn 2 brings the total to 1200.
+	require.Equal(t, 2, agentCalls)
+	require.Equal(t, 1, verifyCalls)
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, scenarioName, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.True(t, progress.TokenBudgetExceeded)
+	require.Equal(t, 1200, progress.TokenUsage.Total)
+}
+
+func TestRunAgentRecordsTranscriptSize(t *testing.T) {
+	t.Parallel()
+	runnerStubMu.Lock()
+	t.Cleanup(runnerStubMu.Unlock)
+
+	origAgentRunner := agentRunner
+	origAgentVersionChecker := agentVersionChecker
+	t.Cleanup(func() {
+		agentRunner = origAgentRunner
+		agentVersionChecker = origAgentVersionChecker
+	})
+	agentVersionChecker = func(ctx context.Context, def agents.Definition) (string, error) {
+		return def.Version, nil
+	}
+	agentRunner = func(ctx context.Context, rc agents.RunContext) (*agents.RunOutcome, error) {
+		now := time.Now()
+		return &agents.RunOutcome{Progress: &types.RunProgress{
+			StartedAt: now, UpdatedAt: now, EndedAt: &now,
+			Transcripts: []string{"{\"a\":1}\n{\"b\":2}\n", "done"},
+		}}, nil
+	}
+
+	workspacePath := t.TempDir()
+	scenarioName := "demo-scenario"
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, scenarioName), 0o755))
+	sc := &scenario.Scenario{Agent: scenario.AgentConfig{Instructions: "do something"}}
+	err := runAgent(context.Background(), output.NewPrinter(io.Discard), workspacePath, scenarioName, agents.Definition{Name: "dummy", Version: "v1"}, "test-model", nil, sc, runAgentOptions{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, scenarioName, ".run-progress.json"))
+	require.NoError(t, err)
+	var progress types.RunProgress
+	require.NoError(t, json.Unmarshal(data, &progress))
+	require.Equal(t, 20, progress.TranscriptBytes)
+	require.Equal(t, 3, progress.TranscriptLines)
+}
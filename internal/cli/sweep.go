@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/fsutil"
+)
+
+// sweepStateFile is the exec --resume-sweep state file, in the workspace root.
+const sweepStateFile = ".sweep-state.json"
+
+// sweepState records which {scenario, agent, model, reasoning} combos of a sweep have been verified.
+type sweepState struct {
+	Completed []sweepCombo `json:"completed"`
+}
+
+type sweepCombo struct {
+	Scenario string `json:"scenario"`
+	Agent    string `json:"agent"`
+	Model    string `json:"model"`
+	// Reasoning is the --reasoning override the combo ran with; empty means the model's default level. Like report
+	// rows (model@level), each override is its own combo.
+	Reasoning   string    `json:"reasoning,omitempty"`
+	RunID       string    `json:"run_id,omitempty"`
+	Success     bool      `json:"success"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+func sweepStatePath(workspacePath string) string {
+	return filepath.Join(workspacePath, sweepStateFile)
+}
+
+// loadSweepState reads the state file at path. A missing file is an empty state.
+func loadSweepState(path string) (*sweepState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &sweepState{}, nil
+		}
+		return nil, err
+	}
+	var state sweepState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse sweep state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// completed returns the recorded combo matching key's {scenario, agent, model, reasoning}, if any.
+func (s *sweepState) completed(key sweepCombo) (sweepCombo, bool) {
+	for _, c := range s.Completed {
+		if c.Scenario == key.Scenario && c.Agent == key.Agent && c.Model == key.Model && c.Reasoning == key.Reasoning {
+			return c, true
+		}
+	}
+	return sweepCombo{}, false
+}
+
+// sweepReasoningNote returns the ", reasoning=<level>" suffix for a skip message, or "" for the model's default level.
+func sweepReasoningNote(level string) string {
+	if level == "" {
+		return ""
+	}
+	return ", reasoning=" + level
+}
+
+// recordSweepCombo adds combo to the state file at path, re-reading it first to keep entries recorded since it was
+// loaded. The file is locked while it's updated, so parallel sweeps sharing a workspace don't drop each other's entries,
+// and replaced atomically, so a crash never leaves it half-written.
+func recordSweepCombo(path string, combo sweepCombo) (err error) {
+	unlock, err := fsutil.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, unlock()) }()
+	state, err := loadSweepState(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := state.completed(combo); !ok {
+		state.Completed = append(state.Completed, combo)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,76 @@
+// Package cmdshim blocks commands for a subprocess by prepending a directory of failing shim scripts to its PATH.
+//
+// This is a blunt guardrail, not a sandbox: it only affects commands looked up via PATH. Invoking a binary by absolute
+// path (ex: /bin/rm), shell builtins, and programs that exec binaries directly (or reset PATH) are not blocked.
+package cmdshim
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// nameRe matches command names that can be shimmed: a single path element.
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9_+][A-Za-z0-9._+-]*$`)
+
+// ValidateName returns an error if name can't be shimmed (ex: it's empty or contains a path separator).
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid command name %q (expected a bare command name, ex: curl)", name)
+	}
+	return nil
+}
+
+// Shim is a directory of shim scripts, one per blocked command.
+type Shim struct {
+	dir string
+}
+
+// Build creates a temporary shim directory with a script for each command that prints an error and exits 126. Callers
+// must Close the shim to remove the directory.
+func Build(commands []string) (*Shim, error) {
+	dir, err := os.MkdirTemp("", "goagentbench-shims-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Shim{dir: dir}
+	for _, name := range commands {
+		if err := ValidateName(name); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		script := fmt.Sprintf("#!/bin/sh\necho \"goagentbench: %s is blocked by agent.deny-commands\" >&2\nexit 126\n", name)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Dir returns the shim directory.
+func (s *Shim) Dir() string {
+	return s.dir
+}
+
+// PathEnv returns a PATH=... entry with the shim directory prepended to the PATH in env (its last PATH entry), or to
+// this process's PATH if env doesn't set one.
+func (s *Shim) PathEnv(env []string) string {
+	base := os.Getenv("PATH")
+	for _, entry := range env {
+		if v, ok := strings.CutPrefix(entry, "PATH="); ok {
+			base = v
+		}
+	}
+	if base == "" {
+		return "PATH=" + s.dir
+	}
+	return "PATH=" + s.dir + string(os.PathListSeparator) + base
+}
+
+// Close removes the shim directory.
+func (s *Shim) Close() error {
+	return os.RemoveAll(s.dir)
+}
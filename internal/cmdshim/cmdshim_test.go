@@ -0,0 +1,45 @@
+package cmdshim_test
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/cmdshim"
+)
+
+func TestShimBlocksDeniedCommands(t *testing.T) {
+	shim, err := cmdshim.Build([]string{"curl", "git"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shim.Close() })
+
+	env := []string{"PATH=/usr/bin:/bin"}
+	pathEnv := shim.PathEnv(env)
+	require.Equal(t, "PATH="+shim.Dir()+":/usr/bin:/bin", pathEnv)
+
+	cmd := exec.Command("sh", "-c", "curl https://example.com")
+	cmd.Env = append(env, pathEnv)
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	require.Equal(t, 126, cmd.ProcessState.ExitCode())
+	require.Contains(t, string(out), "curl is blocked by agent.deny-commands")
+
+	cmd = exec.Command("sh", "-c", "echo allowed")
+	cmd.Env = append(env, pathEnv)
+	out, err = cmd.CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t, "allowed\n", string(out))
+
+	require.NoError(t, shim.Close())
+	require.NoDirExists(t, shim.Dir())
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"curl", "go1.22", "x86_64-linux-gcc", "g++"} {
+		require.NoError(t, cmdshim.ValidateName(name), name)
+	}
+	for _, name := range []string{"", ".", "..", "bin/rm", "/bin/rm", "rm -rf", "-rf"} {
+		require.Error(t, cmdshim.ValidateName(name), name)
+	}
+}
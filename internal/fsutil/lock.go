@@ -0,0 +1,28 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LockFile takes an exclusive, cross-process lock for the shared file at path, blocking until it's free, and returns a
+// func that releases it. The lock is held on a sibling path+".lock" file, which is left in place, so path itself can be
+// replaced (ex: by an atomic rename) while locked. It's advisory: it only excludes other LockFile callers. Platforms
+// without file locking (see lock_other.go) get no locking.
+func LockFile(path string) (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() error {
+		return errors.Join(unlockFile(f), f.Close())
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+
+package fsutil
+
+import "os"
+
+// lockFile is a no-op without file locking support; concurrent writers aren't excluded.
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -0,0 +1,73 @@
+package fsutil_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/fsutil"
+)
+
+const lockHelperEnv = "GOAGENTBENCH_LOCK_HELPER_COUNTER"
+
+// TestLockFileHelperProcess is a writer process for TestLockFileExcludesConcurrentWriters: it increments the counter
+// file named by lockHelperEnv 50 times, each under the lock.
+func TestLockFileHelperProcess(t *testing.T) {
+	path := os.Getenv(lockHelperEnv)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, incrementLocked(path))
+	}
+}
+
+func incrementLocked(path string) error {
+	unlock, err := fsutil.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	n := 0
+	if s := strings.TrimSpace(string(data)); s != "" {
+		if n, err = strconv.Atoi(s); err != nil {
+			return err
+		}
+	}
+	// Rewrite in place (not atomically), so an unlocked writer would lose or corrupt updates.
+	return os.WriteFile(path, []byte(strconv.Itoa(n+1)), 0o644)
+}
+
+func TestLockFileExcludesConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared", "counter")
+	const writers = 4
+	cmds := make([]*exec.Cmd, writers)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockFileHelperProcess$")
+		cmd.Env = append(os.Environ(), lockHelperEnv+"="+path)
+		require.NoError(t, cmd.Start())
+		cmds[i] = cmd
+	}
+	// This process writes concurrently too.
+	for i := 0; i < 50; i++ {
+		require.NoError(t, incrementLocked(path))
+	}
+	for i, cmd := range cmds {
+		require.NoError(t, cmd.Wait(), fmt.Sprintf("writer %d", i))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa((writers+1)*50), string(data))
+	require.FileExists(t, path+".lock")
+}
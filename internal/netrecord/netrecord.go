@@ -0,0 +1,190 @@
+// Package netrecord provides a minimal HTTP/HTTPS forward proxy that records which hosts an agent contacts.
+//
+// Only request metadata is recorded (method, host, and path for plain HTTP; host for CONNECT tunnels). Query strings,
+// headers, and bodies are never logged, since they may contain secrets.
+package netrecord
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is one recorded request.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Host   string    `json:"host"`
+	Path   string    `json:"path,omitempty"`
+}
+
+// Recorder is a running recording proxy.
+type Recorder struct {
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+
+	mu    sync.Mutex
+	log   *os.File
+	enc   *json.Encoder
+	hosts map[string]struct{}
+}
+
+// Start starts a recording proxy on a random loopback port, appending JSON-lines entries to logPath.
+func Start(logPath string) (*Recorder, error) {
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r := &Recorder{
+		listener:  ln,
+		transport: &http.Transport{Proxy: nil},
+		log:       f,
+		enc:       json.NewEncoder(f),
+		hosts:     map[string]struct{}{},
+	}
+	r.server = &http.Server{Handler: r}
+	go func() { _ = r.server.Serve(ln) }()
+	return r, nil
+}
+
+// URL returns the proxy URL (ex: "http://127.0.0.1:54321").
+func (r *Recorder) URL() string {
+	return "http://" + r.listener.Addr().String()
+}
+
+// Env returns environment entries that route a subprocess's HTTP(S) traffic through the proxy.
+func (r *Recorder) Env() []string {
+	u := r.URL()
+	return []string{
+		"HTTP_PROXY=" + u,
+		"HTTPS_PROXY=" + u,
+		"http_proxy=" + u,
+		"https_proxy=" + u,
+	}
+}
+
+// Hosts returns the distinct hosts recorded so far, sorted.
+func (r *Recorder) Hosts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.hosts) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(r.hosts))
+	for h := range r.hosts {
+		out = append(out, h)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Close stops the proxy and closes the log file.
+func (r *Recorder) Close() error {
+	err := r.server.Close()
+	r.transport.CloseIdleConnections()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cerr := r.log.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (r *Recorder) record(method, host, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts[hostOnly(host)] = struct{}{}
+	_ = r.enc.Encode(Entry{Time: time.Now(), Method: method, Host: host, Path: path})
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		r.record(req.Method, req.Host, "")
+		r.tunnel(w, req)
+		return
+	}
+	if req.URL == nil || req.URL.Host == "" {
+		http.Error(w, "netrecord: only proxy requests are supported", http.StatusBadRequest)
+		return
+	}
+	r.record(req.Method, req.URL.Host, req.URL.Path)
+
+	out := req.Clone(req.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (r *Recorder) tunnel(w http.ResponseWriter, req *http.Request) {
+	upstream, err := net.DialTimeout("tcp", req.Host, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		_ = upstream.Close()
+		http.Error(w, "netrecord: hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = upstream.Close()
+		return
+	}
+	go func() {
+		// Forward anything the client sent after the CONNECT line that was already buffered.
+		if n := buf.Reader.Buffered(); n > 0 {
+			pending, _ := buf.Reader.Peek(n)
+			_, _ = upstream.Write(pending)
+		}
+		_, _ = io.Copy(upstream, client)
+		_ = upstream.Close()
+	}()
+	go func() {
+		_, _ = io.Copy(client, upstream)
+		_ = client.Close()
+	}()
+}
+
+func hostOnly(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport
+	}
+	return host
+}
@@ -0,0 +1,52 @@
+package netrecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderRecordsHostsWithoutQuery(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+
+	logPath := filepath.Join(t.TempDir(), "network.jsonl")
+	rec, err := Start(logPath)
+	require.NoError(t, err)
+
+	proxyURL, err := url.Parse(rec.URL())
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL + "/some/path?token=secret")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "hello", string(body))
+
+	require.Equal(t, []string{"127.0.0.1"}, rec.Hosts())
+	require.NoError(t, rec.Close())
+
+	logged, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	require.Contains(t, string(logged), `"path":"/some/path"`)
+	require.NotContains(t, string(logged), "secret")
+}
+
+func TestRecorderEnvPointsAtProxy(t *testing.T) {
+	rec, err := Start(filepath.Join(t.TempDir(), "network.jsonl"))
+	require.NoError(t, err)
+	defer rec.Close()
+
+	require.Contains(t, rec.Env(), "HTTPS_PROXY="+rec.URL())
+	require.Contains(t, rec.Env(), "http_proxy="+rec.URL())
+}
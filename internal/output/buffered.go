@@ -0,0 +1,47 @@
+package output
+
+import (
+	"bytes"
+)
+
+// Buffered returns a Printer like p that writes to memory, and a flush func that writes everything it printed to p as
+// one block, spaced as if it had been printed to p directly. Concurrent tasks can each print to their own buffered
+// printer and flush in a fixed order, so their output doesn't interleave. flush must not run concurrently with other
+// uses of p, and the buffered printer shouldn't be used after flush.
+func (p *Printer) Buffered() (*Printer, func() error) {
+	var buf bytes.Buffer
+	child := *p
+	child.out = &buf
+	child.last = outputDeferred
+	child.firstOutput = outputNone
+	if p.json != nil {
+		child.json = &jsonSink{out: &buf}
+	}
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		if p.json != nil {
+			p.json.mu.Lock()
+			defer p.json.mu.Unlock()
+			_, err := p.json.out.Write(buf.Bytes())
+			return err
+		}
+		var err error
+		switch child.firstOutput {
+		case outputCommand:
+			err = p.ensureGapBeforeCommand()
+		case outputApp:
+			err = p.ensureGapBeforeApp()
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := p.out.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		p.last = child.last
+		return nil
+	}
+	return &child, flush
+}
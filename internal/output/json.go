@@ -0,0 +1,116 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event kinds written by a JSON printer.
+const (
+	EventMessage       = "message"
+	EventCommandStart  = "command_start"
+	EventCommandOutput = "command_output"
+	EventCommandEnd    = "command_end"
+)
+
+// Event is one line of --json-logs output.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	Level string    `json:"level"`
+	Phase string    `json:"phase,omitempty"`
+	Msg   string    `json:"msg,omitempty"`
+	// Command is the command line, for command events.
+	Command string `json:"command,omitempty"`
+	// Stream ("stdout" or "stderr") and Output are set on command_output events; command_end events from RunCommand
+	// carry the full combined output instead.
+	Stream   string `json:"stream,omitempty"`
+	Output   string `json:"output,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// jsonSink serializes events to out, one JSON object per line. It's shared by the goroutines copying command output.
+type jsonSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewJSONPrinter creates a Printer that writes newline-delimited JSON Events to out instead of styled text.
+func NewJSONPrinter(out io.Writer) *Printer {
+	p := NewPrinter(out)
+	p.json = &jsonSink{out: p.out}
+	return p
+}
+
+// SetPhase sets the lifecycle phase (ex: "setup", "verify") recorded on subsequent JSON events. It has no effect on
+// text output.
+func (p *Printer) SetPhase(phase string) {
+	p.phase = phase
+}
+
+func (p *Printer) emit(e Event) error {
+	e.Time = time.Now().UTC()
+	e.Phase = p.phase
+	if e.Level == "" {
+		e.Level = "info"
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	p.json.mu.Lock()
+	defer p.json.mu.Unlock()
+	_, err = p.json.out.Write(append(data, '\n'))
+	return err
+}
+
+// messageLevel is "warn" for "Warning: ..." messages, otherwise "info".
+func messageLevel(text string) string {
+	if strings.HasPrefix(text, "Warning:") {
+		return "warn"
+	}
+	return "info"
+}
+
+// commandEnd returns the command_end event for a command that finished with err.
+func commandEnd(commandLine string, err error) Event {
+	e := Event{Event: EventCommandEnd, Command: commandLine}
+	code := 0
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	default:
+		code = -1
+	}
+	e.ExitCode = &code
+	if err != nil {
+		e.Level = "error"
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// jsonStreamWriter emits each chunk written to it as a command_output event.
+type jsonStreamWriter struct {
+	p           *Printer
+	commandLine string
+	stream      string
+}
+
+func (w *jsonStreamWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if err := w.p.emit(Event{Event: EventCommandOutput, Command: w.commandLine, Stream: w.stream, Output: string(b)}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// EnvVarKillGrace opts in to process-group cleanup for commands run by a Printer. When set to a positive duration (ex:
+// "10s"), commands start in their own process group, and on context cancellation the whole group gets SIGTERM, then
+// SIGKILL once the grace period passes. Otherwise only the command itself is killed, which can orphan processes it
+// spawned (ex: an agent's tool subprocesses). Unix only; elsewhere it's ignored.
+const EnvVarKillGrace = "GOAGENTBENCH_KILL_GRACE"
+
+// killGrace returns GOAGENTBENCH_KILL_GRACE if it is a positive duration, else 0 (disabled).
+func killGrace() time.Duration {
+	if v := strings.TrimSpace(os.Getenv(EnvVarKillGrace)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// cancelWaitDelay bounds how long Wait blocks after cancellation on output pipes still held open by orphaned
+// descendants (ex: a test binary whose `go test` was killed).
+const cancelWaitDelay = 5 * time.Second
+
+// newCommand is exec.CommandContext, with process-group cleanup on cancellation if GOAGENTBENCH_KILL_GRACE is set.
+func newCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = cancelWaitDelay
+	if grace := killGrace(); grace > 0 {
+		killGroupOnCancel(cmd, grace)
+	}
+	return cmd
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package output
+
+import (
+	"os/exec"
+	"time"
+)
+
+// killGroupOnCancel is a no-op without Unix process groups; cancellation kills only the command itself.
+func killGroupOnCancel(cmd *exec.Cmd, grace time.Duration) {}
@@ -0,0 +1,31 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package output
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// killGroupOnCancel starts cmd in its own process group and makes cancellation SIGTERM the group, then SIGKILL it after
+// grace. Wait also stops waiting for output pipes held open by escaped descendants shortly after that.
+func killGroupOnCancel(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return os.ErrProcessDone
+			}
+			return err
+		}
+		time.AfterFunc(grace, func() {
+			_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		return nil
+	}
+	cmd.WaitDelay = grace + time.Second
+}
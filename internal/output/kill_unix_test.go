@@ -0,0 +1,74 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package output
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandKillsProcessGroupOnCancel(t *testing.T) {
+	t.Setenv(EnvVarKillGrace, "200ms")
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	// The shell and its background child both ignore SIGTERM, so only the SIGKILL escalation can stop them.
+	script := `trap "" TERM; sleep 60 & echo $! > ` + pidFile + `; wait`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pidCh := make(chan int, 1)
+	go func() {
+		for ctx.Err() == nil {
+			if data, err := os.ReadFile(pidFile); err == nil && strings.HasSuffix(string(data), "\n") {
+				pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+				pidCh <- pid
+				cancel()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	_, err := NewPrinter(io.Discard).RunCommandStreamingSplit(ctx, "", nil, "sh", "-c", script)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 10*time.Second)
+	childPID := <-pidCh
+	require.NotZero(t, childPID)
+	require.Eventually(t, func() bool {
+		return processGone(childPID)
+	}, 5*time.Second, 20*time.Millisecond, "grandchild sleep survived cancellation")
+}
+
+func TestKillGraceParsesDuration(t *testing.T) {
+	t.Setenv(EnvVarKillGrace, "")
+	require.Zero(t, killGrace())
+	t.Setenv(EnvVarKillGrace, "bogus")
+	require.Zero(t, killGrace())
+	t.Setenv(EnvVarKillGrace, "5s")
+	require.Equal(t, 5*time.Second, killGrace())
+}
+
+// processGone reports whether pid no longer runs. A killed process may linger as a zombie until it's reaped (which
+// depends on the init process), so a zombie counts as gone where /proc shows it.
+func processGone(pid int) bool {
+	if errors.Is(syscall.Kill(pid, 0), syscall.ESRCH) {
+		return true
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesized command name: "pid (comm) S ...".
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
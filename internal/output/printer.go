@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"os/exec"
+	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/codalotl/goagentbench/internal/ansi"
 )
@@ -19,6 +20,11 @@ type Printer struct {
 	commandStyle       ansi.Style
 	commandOutputStyle ansi.Style
 	last               outputKind
+	// json, when set, receives structured events instead of styled text (see NewJSONPrinter).
+	json  *jsonSink
+	phase string
+	// firstOutput is the kind of the first thing a Buffered printer printed, to pick the gap before it when flushed.
+	firstOutput outputKind
 }
 
 type outputKind int
@@ -27,6 +33,9 @@ const (
 	outputNone outputKind = iota
 	outputApp
 	outputCommand
+	// outputDeferred is a Buffered printer's initial state: the gap before its first output depends on what the parent
+	// printed last when the buffer is flushed.
+	outputDeferred
 )
 
 // NewPrinter creates a Printer that writes to out using the formatting rules from SPEC.md.
@@ -81,6 +90,9 @@ func (p *Printer) App(text string) error {
 	if text == "" {
 		return nil
 	}
+	if p.json != nil {
+		return p.emit(Event{Event: EventMessage, Level: messageLevel(text), Msg: strings.TrimRight(text, "\n")})
+	}
 	if err := p.ensureGapBeforeApp(); err != nil {
 		return err
 	}
@@ -98,6 +110,9 @@ func (p *Printer) Appf(format string, args ...any) error {
 // RunCommand prints the command invocation and then runs it, reformatting stdout+stderr per SPEC.md.
 // Returns the command's combined output.
 func (p *Printer) RunCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if p.json != nil {
+		return p.runCommandJSON(ctx, dir, name, args)
+	}
 	if err := p.ensureGapBeforeCommand(); err != nil {
 		return nil, err
 	}
@@ -106,7 +121,7 @@ func (p *Printer) RunCommand(ctx context.Context, dir, name string, args ...stri
 		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := newCommand(ctx, name, args...)
 	cmd.Dir = dir
 	output, cmdErr := cmd.CombinedOutput()
 	if len(output) > 0 {
@@ -118,44 +133,108 @@ func (p *Printer) RunCommand(ctx context.Context, dir, name string, args ...stri
 	return output, cmdErr
 }
 
-// RunCommandStreaming streams stdout/stderr through the printer as it arrives, while capturing the combined output.
-func (p *Printer) RunCommandStreaming(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
-	if err := p.ensureGapBeforeCommand(); err != nil {
+// runCommandJSON is RunCommand for a JSON printer: the invocation and outcome (with the output) are events.
+func (p *Printer) runCommandJSON(ctx context.Context, dir, name string, args []string) ([]byte, error) {
+	commandLine := formatCommand(name, args)
+	if err := p.emit(Event{Event: EventCommandStart, Command: commandLine}); err != nil {
 		return nil, err
 	}
+	cmd := newCommand(ctx, name, args...)
+	cmd.Dir = dir
+	output, cmdErr := cmd.CombinedOutput()
+	end := commandEnd(commandLine, cmdErr)
+	end.Output = string(output)
+	if err := p.emit(end); err != nil {
+		return output, err
+	}
+	return output, cmdErr
+}
+
+// RunCommandStreaming streams stdout/stderr through the printer as it arrives, while capturing the combined output
+// (stdout, then stderr).
+func (p *Printer) RunCommandStreaming(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	return p.RunCommandStreamingEnv(ctx, dir, nil, name, args...)
+}
+
+// RunCommandStreamingEnv is RunCommandStreaming with extra KEY=VALUE entries appended to the current environment.
+// The env entries are not printed.
+func (p *Printer) RunCommandStreamingEnv(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
+	out, err := p.RunCommandStreamingSplit(ctx, dir, env, name, args...)
+	return out.Combined, err
+}
+
+// CommandOutput is the captured output of a command. Combined is Stdout followed by Stderr: arrival order across the two
+// streams isn't deterministic, so Combined doesn't interleave them (a JSON-lines stdout stays contiguous).
+type CommandOutput struct {
+	Stdout   []byte
+	Stderr   []byte
+	Combined []byte
+	// FirstOutput is the time from starting the command to its first byte of output (either stream), or 0 if it
+	// printed nothing.
+	FirstOutput time.Duration
+}
+
+// RunCommandStreamingSplit is RunCommandStreamingEnv, but also captures stdout and stderr separately, so callers can
+// parse a machine-readable stdout stream without stderr noise (ex: progress output) interleaved into it. Both streams are
+// still displayed.
+func (p *Printer) RunCommandStreamingSplit(ctx context.Context, dir string, env []string, name string, args ...string) (CommandOutput, error) {
 	commandLine := formatCommand(name, args)
-	if err := p.writeStyled(p.commandStyle, ensureTrailingNewline(commandLine)); err != nil {
-		return nil, err
+	if p.json != nil {
+		if err := p.emit(Event{Event: EventCommandStart, Command: commandLine}); err != nil {
+			return CommandOutput{}, err
+		}
+	} else {
+		if err := p.ensureGapBeforeCommand(); err != nil {
+			return CommandOutput{}, err
+		}
+		if err := p.writeStyled(p.commandStyle, ensureTrailingNewline(commandLine)); err != nil {
+			return CommandOutput{}, err
+		}
 	}
 
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := newCommand(ctx, name, args...)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, err
+		return CommandOutput{}, err
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return nil, err
+		return CommandOutput{}, err
 	}
 
-	var buf bytes.Buffer
-	writer := &styledWriter{
-		style: p.commandOutputStyle,
-		out:   p.out,
+	var stdoutBuf, stderrBuf bytes.Buffer
+	var stdoutWriter, stderrWriter io.Writer
+	if p.json != nil {
+		stdoutWriter = &jsonStreamWriter{p: p, commandLine: commandLine, stream: "stdout"}
+		stderrWriter = &jsonStreamWriter{p: p, commandLine: commandLine, stream: "stderr"}
+	} else {
+		writer := &styledWriter{
+			style: p.commandOutputStyle,
+			out:   p.out,
+		}
+		stdoutWriter, stderrWriter = writer, writer
 	}
-	copyStream := func(r io.Reader) error {
-		_, err := io.Copy(writer, io.TeeReader(r, &buf))
+	first := &firstWriteRecorder{}
+	copyStream := func(r io.Reader, display io.Writer, own *bytes.Buffer) error {
+		_, err := io.Copy(io.MultiWriter(first, display, own), r)
 		return err
 	}
 
 	if err := cmd.Start(); err != nil {
-		return nil, err
+		if p.json != nil {
+			_ = p.emit(commandEnd(commandLine, err))
+		}
+		return CommandOutput{}, err
 	}
+	first.start = time.Now()
 
 	errCh := make(chan error, 2)
-	go func() { errCh <- copyStream(stdout) }()
-	go func() { errCh <- copyStream(stderr) }()
+	go func() { errCh <- copyStream(stdout, stdoutWriter, &stdoutBuf) }()
+	go func() { errCh <- copyStream(stderr, stderrWriter, &stderrBuf) }()
 
 	var copyErr error
 	for i := 0; i < 2; i++ {
@@ -167,14 +246,29 @@ func (p *Printer) RunCommandStreaming(ctx context.Context, dir, name string, arg
 	waitErr := cmd.Wait()
 	p.last = outputCommand
 
+	combined := make([]byte, 0, stdoutBuf.Len()+stderrBuf.Len())
+	combined = append(append(combined, stdoutBuf.Bytes()...), stderrBuf.Bytes()...)
+	out := CommandOutput{Stdout: stdoutBuf.Bytes(), Stderr: stderrBuf.Bytes(), Combined: combined, FirstOutput: first.first}
+	if p.json != nil {
+		endErr := waitErr
+		if endErr == nil {
+			endErr = copyErr
+		}
+		if err := p.emit(commandEnd(commandLine, endErr)); err != nil {
+			return out, err
+		}
+	}
 	if waitErr != nil {
-		return []byte(buf.String()), waitErr
+		return out, waitErr
 	}
-	return buf.Bytes(), copyErr
+	return out, copyErr
 }
 
 func (p *Printer) ensureGapBeforeCommand() error {
 	switch p.last {
+	case outputDeferred:
+		p.firstOutput = outputCommand
+		return nil
 	case outputApp, outputCommand:
 		_, err := io.WriteString(p.out, "\n")
 		return err
@@ -184,6 +278,10 @@ func (p *Printer) ensureGapBeforeCommand() error {
 }
 
 func (p *Printer) ensureGapBeforeApp() error {
+	if p.last == outputDeferred {
+		p.firstOutput = outputApp
+		return nil
+	}
 	if p.last != outputCommand {
 		return nil
 	}
@@ -199,6 +297,20 @@ func (p *Printer) writeStyled(style ansi.Style, text string) error {
 	return err
 }
 
+// firstWriteRecorder records when the first non-empty write happened, relative to start.
+type firstWriteRecorder struct {
+	start time.Time
+	once  sync.Once
+	first time.Duration
+}
+
+func (r *firstWriteRecorder) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		r.once.Do(func() { r.first = time.Since(r.start) })
+	}
+	return len(p), nil
+}
+
 type styledWriter struct {
 	style ansi.Style
 	out   io.Writer
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandStreamingSplitCombinedIsDeterministic(t *testing.T) {
+	// Two concurrent writers, so stdout and stderr chunks arrive interleaved.
+	script := `(for i in $(seq 1 50); do echo "{\"n\":$i}"; done) & (for i in $(seq 1 50); do echo "err $i" >&2; done) & wait`
+	var want strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&want, "{\"n\":%d}\n", i)
+	}
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&want, "err %d\n", i)
+	}
+
+	for run := 0; run < 5; run++ {
+		var live bytes.Buffer
+		out, err := NewPrinter(&live).RunCommandStreamingSplit(context.Background(), t.TempDir(), nil, "sh", "-c", script)
+		require.NoError(t, err)
+		require.Equal(t, want.String(), string(out.Combined))
+		// Both streams are still displayed live.
+		require.Contains(t, live.String(), "{\"n\":50}")
+		require.Contains(t, live.String(), "err 50")
+	}
+}
+
+func TestJSONPrinterEmitsEvents(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewJSONPrinter(&buf)
+	p.SetPhase("setup")
+	require.NoError(t, p.App("Cloning repo"))
+	p.SetPhase("verify")
+	require.NoError(t, p.Appf("Warning: %s", "slow"))
+	_, err := p.RunCommandStreamingSplit(context.Background(), t.TempDir(), nil, "sh", "-c", "echo out; exit 3")
+	require.Error(t, err)
+	out, err := p.RunCommand(context.Background(), t.TempDir(), "echo", "hi")
+	require.NoError(t, err)
+	require.Equal(t, "hi\n", string(out))
+
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e), line)
+		require.False(t, e.Time.IsZero())
+		e.Time = time.Time{}
+		events = append(events, e)
+	}
+	code0, code3 := 0, 3
+	require.Equal(t, []Event{
+		{Event: EventMessage, Level: "info", Phase: "setup", Msg: "Cloning repo"},
+		{Event: EventMessage, Level: "warn", Phase: "verify", Msg: "Warning: slow"},
+		{Event: EventCommandStart, Level: "info", Phase: "verify", Command: "sh -c 'echo out; exit 3'"},
+		{Event: EventCommandOutput, Level: "info", Phase: "verify", Command: "sh -c 'echo out; exit 3'", Stream: "stdout", Output: "out\n"},
+		{Event: EventCommandEnd, Level: "error", Phase: "verify", Command: "sh -c 'echo out; exit 3'", ExitCode: &code3, Error: "exit status 3"},
+		{Event: EventCommandStart, Level: "info", Phase: "verify", Command: "echo hi"},
+		{Event: EventCommandEnd, Level: "info", Phase: "verify", Command: "echo hi", Output: "hi\n", ExitCode: &code0},
+	}, events)
+}
+
+func TestRunCommandStreamingSplitRecordsFirstOutput(t *testing.T) {
+	p := NewPrinter(nil)
+	out, err := p.RunCommandStreamingSplit(context.Background(), t.TempDir(), nil, "sh", "-c", "sleep 0.3; echo hi; sleep 0.3; echo bye")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, out.FirstOutput, 300*time.Millisecond)
+	require.Less(t, out.FirstOutput, 600*time.Millisecond)
+
+	out, err = p.RunCommandStreamingSplit(context.Background(), t.TempDir(), nil, "true")
+	require.NoError(t, err)
+	require.Zero(t, out.FirstOutput)
+}
+
+func TestBufferedMatchesDirectOutput(t *testing.T) {
+	ctx := context.Background()
+	var direct bytes.Buffer
+	p := NewPrinter(&direct)
+	require.NoError(t, p.App("start"))
+	_, err := p.RunCommandStreaming(ctx, "", "sh", "-c", "echo one")
+	require.NoError(t, err)
+	_, err = p.RunCommandStreaming(ctx, "", "sh", "-c", "echo two")
+	require.NoError(t, err)
+	require.NoError(t, p.App("done"))
+
+	var buffered bytes.Buffer
+	p = NewPrinter(&buffered)
+	require.NoError(t, p.App("start"))
+	startLen := buffered.Len()
+	first, flushFirst := p.Buffered()
+	second, flushSecond := p.Buffered()
+	third, flushThird := p.Buffered()
+	// Printed out of order, flushed in order.
+	require.NoError(t, third.App("done"))
+	_, err = second.RunCommandStreaming(ctx, "", "sh", "-c", "echo two")
+	require.NoError(t, err)
+	_, err = first.RunCommandStreaming(ctx, "", "sh", "-c", "echo one")
+	require.NoError(t, err)
+	require.Equal(t, startLen, buffered.Len())
+	require.NoError(t, flushFirst())
+	require.NoError(t, flushSecond())
+	require.NoError(t, flushThird())
+	require.Equal(t, direct.String(), buffered.String())
+}
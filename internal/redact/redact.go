@@ -0,0 +1,139 @@
+// Package redact masks secrets (API keys, tokens, and known secret values) in agent transcripts before they're written
+// to run progress and results, which may be shared publicly.
+//
+// Redaction is best effort: it catches common key shapes and exact secret values, not every way a secret can leak
+// (ex: a key split across lines, or encoded).
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Placeholder replaces each redacted secret.
+const Placeholder = "[REDACTED]"
+
+// EnvVarPatternsFile names a file of extra regexp patterns to redact, one per line (blank lines and # comments are
+// skipped).
+const EnvVarPatternsFile = "GOAGENTBENCH_REDACT_FILE"
+
+// DefaultPatterns match common API key and token shapes.
+var DefaultPatterns = []string{
+	`sk-[A-Za-z0-9_-]{16,}`,                // OpenAI and Anthropic (sk-ant-...) keys
+	`gh[pousr]_[A-Za-z0-9]{36,}`,           // GitHub tokens
+	`github_pat_[A-Za-z0-9_]{22,}`,         // GitHub fine-grained tokens
+	`AKIA[0-9A-Z]{16}`,                     // AWS access key ids
+	`AIza[0-9A-Za-z_-]{35}`,                // Google API keys
+	`xox[abposr]-[A-Za-z0-9-]{10,}`,        // Slack tokens
+	`(?i)bearer\s+[A-Za-z0-9._~+/=-]{20,}`, // Authorization headers
+}
+
+// minValueLen is the shortest literal secret value that is redacted; shorter values would mask ordinary text.
+const minValueLen = 8
+
+// Redactor replaces secrets in strings with Placeholder. The zero value redacts nothing.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	values   []string
+}
+
+// New returns a Redactor for the regexp patterns and the literal secret values. Values shorter than 8 characters are
+// ignored.
+func New(patterns, values []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	seen := map[string]bool{}
+	for _, v := range values {
+		if len(v) >= minValueLen && !seen[v] {
+			seen[v] = true
+			r.values = append(r.values, v)
+		}
+	}
+	// Longest first, so a value that contains another is replaced whole.
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+	return r, nil
+}
+
+// Load returns a Redactor for DefaultPatterns, the patterns in the EnvVarPatternsFile file (if set), values, and the
+// values of environment variables whose names look secret (ex: OPENAI_API_KEY, GITHUB_TOKEN).
+func Load(values []string) (*Redactor, error) {
+	patterns := append([]string(nil), DefaultPatterns...)
+	if path := strings.TrimSpace(os.Getenv(EnvVarPatternsFile)); path != "" {
+		extra, err := readPatternsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, extra...)
+	}
+	return New(patterns, append(secretEnvValues(os.Environ()), values...))
+}
+
+func readPatternsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s does not exist: %s", EnvVarPatternsFile, path)
+		}
+		return nil, err
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// secretEnvNameRe matches environment variable names that usually hold secrets.
+var secretEnvNameRe = regexp.MustCompile(`(?i)(KEY|TOKEN|SECRET|PASSWORD)`)
+
+// secretEnvValues returns the values of KEY=VALUE entries whose names match secretEnvNameRe.
+func secretEnvValues(environ []string) []string {
+	var out []string
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && secretEnvNameRe.MatchString(name) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// String returns s with every secret replaced by Placeholder.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Placeholder)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Placeholder)
+	}
+	return s
+}
+
+// Strings returns a copy of ss with each element redacted.
+func (r *Redactor) Strings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = r.String(s)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// Currency is the currency the report shows costs in. Results store costs in USD; only rendering converts them. The zero
+// Currency is USD.
+type Currency struct {
+	// Code is the currency's code (ex: "EUR"). "" means USD.
+	Code string
+	// PerUSD is the conversion rate: units of the currency per USD. Ignored for USD.
+	PerUSD float64
+}
+
+// currencySymbols are the symbols shown for common currencies. Others show their code (ex: "CHF 0.42").
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+}
+
+// IsUSD reports whether c is USD (so costs are shown as stored).
+func (c Currency) IsUSD() bool {
+	code := strings.ToUpper(strings.TrimSpace(c.Code))
+	return code == "" || code == "USD"
+}
+
+// Validate returns an error if c isn't USD and has no positive rate.
+func (c Currency) Validate() error {
+	if c.IsUSD() {
+		return nil
+	}
+	if c.PerUSD <= 0 || math.IsInf(c.PerUSD, 0) || math.IsNaN(c.PerUSD) {
+		return fmt.Errorf("currency %s needs a positive rate (units per USD), got %v", strings.ToUpper(strings.TrimSpace(c.Code)), c.PerUSD)
+	}
+	return nil
+}
+
+// Convert converts a USD amount to c.
+func (c Currency) Convert(usd float64) float64 {
+	if c.IsUSD() {
+		return usd
+	}
+	return usd * c.PerUSD
+}
+
+// Symbol returns the prefix amounts in c are shown with (ex: "$", "€", or "CHF ").
+func (c Currency) Symbol() string {
+	code := strings.ToUpper(strings.TrimSpace(c.Code))
+	if code == "" {
+		code = "USD"
+	}
+	if symbol, ok := currencySymbols[code]; ok {
+		return symbol
+	}
+	return code + " "
+}
+
+// formatMoney formats a USD amount for the CSV: as is for USD (no symbol, as always), otherwise converted and prefixed
+// with the currency's symbol.
+func (r *Report) formatMoney(usd float64) string {
+	if r.Currency.IsUSD() {
+		return types.FormatFloat(usd)
+	}
+	return r.Currency.Symbol() + types.FormatFloat(r.Currency.Convert(usd))
+}
@@ -0,0 +1,194 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// SummaryRow is one row of a published summary's report.csv (see report --publish).
+type SummaryRow struct {
+	Agent          string
+	Model          string
+	SuccessRate    float64
+	AvgCost        float64
+	AvgTimeSeconds float64
+	// CostEstimated is true if the cost_estimated column is true (or, in summaries written before it existed, avg_cost
+	// was suffixed with "*").
+	CostEstimated bool
+}
+
+// LoadSummary reads dir/report.csv. Columns are found by header name, so summaries written with or without optional
+// columns (ex: --include-tokens) load the same.
+func LoadSummary(dir string) ([]SummaryRow, error) {
+	path := filepath.Join(dir, "report.csv")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("read %s: missing header", path)
+	}
+	cols := map[string]int{}
+	for i, name := range records[0] {
+		cols[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"agent", "model", "success_rate", "avg_cost", "avg_time"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("read %s: missing %s column", path, name)
+		}
+	}
+	rows := make([]SummaryRow, 0, len(records)-1)
+	for n, rec := range records[1:] {
+		field := func(name string) string {
+			if i := cols[name]; i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		row := SummaryRow{Agent: field("agent"), Model: field("model")}
+		cost := field("avg_cost")
+		row.CostEstimated = field("cost_estimated") == "true" || strings.HasSuffix(cost, "*")
+		var errs []error
+		row.SuccessRate, err = parseSummaryFloat(field("success_rate"))
+		errs = append(errs, err)
+		row.AvgCost, err = parseSummaryFloat(trimCurrencySymbol(strings.TrimSuffix(cost, "*")))
+		errs = append(errs, err)
+		row.AvgTimeSeconds, err = parseSummaryFloat(field("avg_time"))
+		errs = append(errs, err)
+		if err := errors.Join(errs...); err != nil {
+			return nil, fmt.Errorf("read %s: row %d: %w", path, n+2, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// trimCurrencySymbol removes the currency symbol report --currency prefixes costs with (ex: "€0.42" -> "0.42"). The
+// amount stays in that currency.
+func trimCurrencySymbol(s string) string {
+	return strings.TrimLeftFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '-' && r != '.' })
+}
+
+func parseSummaryFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// DiffRow compares one {agent, model} row between two summaries. Before or After is nil if the row is only in one.
+type DiffRow struct {
+	Agent  string
+	Model  string
+	Before *SummaryRow
+	After  *SummaryRow
+}
+
+// Change classifies the row by success rate: "improved", "regressed", "new", "removed", or "" (unchanged).
+func (d DiffRow) Change() string {
+	switch {
+	case d.Before == nil:
+		return "new"
+	case d.After == nil:
+		return "removed"
+	}
+	delta := d.After.SuccessRate - d.Before.SuccessRate
+	switch {
+	case delta > 1e-9:
+		return "improved"
+	case delta < -1e-9:
+		return "regressed"
+	default:
+		return ""
+	}
+}
+
+// DiffSummaries pairs rows of before and after by {agent, model}: after's rows in order, then rows only in before.
+func DiffSummaries(before, after []SummaryRow) []DiffRow {
+	type key struct{ agent, model string }
+	beforeByKey := map[key]*SummaryRow{}
+	for i := range before {
+		beforeByKey[key{before[i].Agent, before[i].Model}] = &before[i]
+	}
+	seen := map[key]bool{}
+	var out []DiffRow
+	for i := range after {
+		k := key{after[i].Agent, after[i].Model}
+		seen[k] = true
+		out = append(out, DiffRow{Agent: k.agent, Model: k.model, Before: beforeByKey[k], After: &after[i]})
+	}
+	for i := range before {
+		k := key{before[i].Agent, before[i].Model}
+		if !seen[k] {
+			out = append(out, DiffRow{Agent: k.agent, Model: k.model, Before: &before[i]})
+		}
+	}
+	return out
+}
+
+// WriteDiff writes rows as an aligned text table of success rate, cost, and time, each as "before -> after (delta)".
+func WriteDiff(w io.Writer, rows []DiffRow) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "agent\tmodel\tsuccess_rate\tavg_cost\tavg_time\tchange")
+	for _, d := range rows {
+		successRate := diffCell(d, func(r *SummaryRow) float64 { return r.SuccessRate }, func(v float64) string {
+			return fmt.Sprintf("%d%%", int(math.Round(v*100)))
+		}, func(v float64) string {
+			return fmt.Sprintf("%+dpp", int(math.Round(v*100)))
+		})
+		cost := diffCell(d, func(r *SummaryRow) float64 { return r.AvgCost }, func(v float64) string {
+			return "$" + types.FormatFloat(v)
+		}, func(v float64) string {
+			return signed(v, "$"+types.FormatFloat(math.Abs(v)))
+		})
+		avgTime := diffCell(d, func(r *SummaryRow) float64 { return r.AvgTimeSeconds }, func(v float64) string {
+			return types.FormatFloat(v) + "s"
+		}, func(v float64) string {
+			return signed(v, types.FormatFloat(math.Abs(v))+"s")
+		})
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", d.Agent, d.Model, successRate, cost, avgTime, d.Change())
+	}
+	return tw.Flush()
+}
+
+// diffCell formats one metric of d: "before -> after (delta)", or just the one side present.
+func diffCell(d DiffRow, metric func(*SummaryRow) float64, format, formatDelta func(float64) string) string {
+	switch {
+	case d.Before == nil:
+		return format(metric(d.After))
+	case d.After == nil:
+		return format(metric(d.Before))
+	}
+	before, after := metric(d.Before), metric(d.After)
+	return fmt.Sprintf("%s -> %s (%s)", format(before), format(after), formatDelta(after-before))
+}
+
+// signed prefixes abs (the formatted magnitude of v) with v's sign, or returns "0" if v rounds to zero.
+func signed(v float64, abs string) string {
+	if types.FormatFloat(v) == "0" {
+		return "0"
+	}
+	if v < 0 {
+		return "-" + abs
+	}
+	return "+" + abs
+}
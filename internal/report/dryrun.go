@@ -0,0 +1,94 @@
+package report
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/results"
+)
+
+// MatchingFiles lists the result files a report with opts would read, without parsing them (see report --dry-run).
+// The --scenarios, --agents, --models, and --after filters are checked against each file's path, assuming the
+// FSStore layout (<scenario>/<date>-<run_id>-<agent>-<model>.verify.json); a file whose name doesn't follow that
+// layout is only checked by scenario. This can over-match (ex: --after allows a day of slack for time zones, and
+// dedup, version selection, and --limit aren't applied) but shouldn't miss a file the report would use. Paths are
+// returned in walk order, per results dir.
+func MatchingFiles(opts Options) ([]string, error) {
+	scenarioSet := sliceToSet(opts.Scenarios)
+	var out []string
+	for _, dir := range resultsDirs(opts) {
+		files, err := results.NewFSStore(dir).Files()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			// FSStore writes to <scenario>/<file>, and scenario names can be nested (ex: self/report).
+			if scenarioSet != nil && !scenarioSet[path.Dir(f.Key)] {
+				continue
+			}
+			if !fileNameMatches(filepath.Base(f.Path), opts) {
+				continue
+			}
+			out = append(out, f.Path)
+		}
+	}
+	return out, nil
+}
+
+// resultsDirs returns the filesystem results dirs to read for opts (ignoring opts.Store).
+func resultsDirs(opts Options) []string {
+	if len(opts.ResultsDirs) == 0 {
+		return []string{results.Dir(opts.RootPath)}
+	}
+	dirs := make([]string, 0, len(opts.ResultsDirs))
+	for _, dir := range opts.ResultsDirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(opts.RootPath, dir)
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs
+}
+
+// fileNameMatches checks name (<date>-<run_id>-<agent>-<model>.verify.json) against the agent, model, and after
+// filters. Names without a leading date always match.
+func fileNameMatches(name string, opts Options) bool {
+	const dateLayout = "2006-01-02"
+	if len(name) <= len(dateLayout) || name[len(dateLayout)] != '-' {
+		return true
+	}
+	date, err := time.Parse(dateLayout, name[:len(dateLayout)])
+	if err != nil {
+		return true
+	}
+	// Allow a day of slack: the file's date is in the verifying machine's time zone.
+	if opts.After != nil && date.Before(time.Date(opts.After.Year(), opts.After.Month(), opts.After.Day()-1, 0, 0, 0, 0, time.UTC)) {
+		return false
+	}
+	rest := "-" + strings.TrimSuffix(name[len(dateLayout)+1:], ".verify.json")
+	if len(opts.Agents) > 0 && !anyFileNamePart(opts.Agents, func(agent string) bool {
+		return strings.Contains(rest, "-"+agent+"-")
+	}) {
+		return false
+	}
+	if len(opts.Models) > 0 && !anyFileNamePart(opts.Models, func(model string) bool {
+		return strings.HasSuffix(rest, "-"+model) || strings.Contains(rest, "-"+model+"@")
+	}) {
+		return false
+	}
+	return true
+}
+
+// anyFileNamePart reports whether match is true for any of values, escaped as FSStore escapes file name parts.
+func anyFileNamePart(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		v = strings.ReplaceAll(strings.TrimSpace(v), string(os.PathSeparator), "_")
+		if v != "" && match(v) {
+			return true
+		}
+	}
+	return false
+}
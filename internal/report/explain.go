@@ -0,0 +1,78 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// RowExplanation describes how many results for one {agent, model} survived each stage of the report pipeline.
+type RowExplanation struct {
+	Agent        string
+	Model        string
+	Platform     string // with Options.ByPlatform
+	Matched      int    // results matching the scenario/agent/model/after filters
+	AfterDedup   int    // after keeping one result per run_id
+	AfterVersion int    // after keeping only the newest agent version (same as AfterDedup with --all-agent-versions)
+	AfterLimit   int    // after keeping the most recent N per {scenario, agent, model}
+	RunIDs       []string
+}
+
+// explainTracker counts entries per row key (see rowKey) at each pipeline stage.
+type explainTracker struct {
+	stages     [4]map[string]int
+	byPlatform bool
+}
+
+func (t *explainTracker) record(stage int, entries []resultEntry) {
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[rowKey(e.Agent, e.Model, e.Platform, t.byPlatform)]++
+	}
+	t.stages[stage] = counts
+}
+
+// explain builds one explanation per row, in row order. grouped holds the final entries per row key.
+func (t *explainTracker) explain(rows []Row, grouped map[string][]resultEntry) []RowExplanation {
+	out := make([]RowExplanation, 0, len(rows))
+	for _, row := range rows {
+		key := rowKey(row.Agent, row.Model, row.Platform, t.byPlatform)
+		var runIDs []string
+		for _, e := range grouped[key] {
+			id := e.RunID
+			if id == "" {
+				id = "(no run_id)"
+			}
+			runIDs = append(runIDs, id)
+		}
+		sort.Strings(runIDs)
+		out = append(out, RowExplanation{
+			Agent:        row.Agent,
+			Model:        row.Model,
+			Platform:     row.Platform,
+			Matched:      t.stages[0][key],
+			AfterDedup:   t.stages[1][key],
+			AfterVersion: t.stages[2][key],
+			AfterLimit:   t.stages[3][key],
+			RunIDs:       runIDs,
+		})
+	}
+	return out
+}
+
+// WriteExplain writes a human-readable description of Explanations to w.
+func (r *Report) WriteExplain(w io.Writer) error {
+	for _, e := range r.Explanations {
+		label := e.Agent + " / " + e.Model
+		if e.Platform != "" {
+			label += " / " + e.Platform
+		}
+		_, err := fmt.Fprintf(w, "%s: matched=%d after_dedup=%d after_version=%d after_limit=%d\n  runs: %s\n",
+			label, e.Matched, e.AfterDedup, e.AfterVersion, e.AfterLimit, strings.Join(e.RunIDs, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
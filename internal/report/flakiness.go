@@ -0,0 +1,108 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// FlakyCombo is a {scenario, agent, model} whose selected results include both successes and failures.
+type FlakyCombo struct {
+	Scenario  string
+	Agent     string
+	Model     string
+	Runs      int
+	Successes int
+	PassRatio float64
+	// Flakiness is 1 - |2*PassRatio - 1|: 1 for an even split, approaching 0 as outcomes agree.
+	Flakiness float64
+}
+
+// findFlakyCombos groups entries per {scenario, agent, model} and returns the mixed-outcome groups, most split first.
+func findFlakyCombos(entries []resultEntry) []FlakyCombo {
+	type counts struct {
+		scenario, agent, model string
+		runs, successes        int
+	}
+	grouped := map[string]*counts{}
+	for _, e := range entries {
+		if e.Unverified {
+			continue
+		}
+		key := scenarioAgentModelKey(e.Scenario, e.Agent, e.Model)
+		c := grouped[key]
+		if c == nil {
+			c = &counts{scenario: e.Scenario, agent: e.Agent, model: e.Model}
+			grouped[key] = c
+		}
+		c.runs++
+		if e.Success {
+			c.successes++
+		}
+	}
+
+	var out []FlakyCombo
+	for _, c := range grouped {
+		if c.successes == 0 || c.successes == c.runs {
+			continue
+		}
+		ratio := float64(c.successes) / float64(c.runs)
+		out = append(out, FlakyCombo{
+			Scenario:  c.scenario,
+			Agent:     c.agent,
+			Model:     c.model,
+			Runs:      c.runs,
+			Successes: c.successes,
+			PassRatio: ratio,
+			Flakiness: 1 - math.Abs(2*ratio-1),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Flakiness != out[j].Flakiness {
+			return out[i].Flakiness > out[j].Flakiness
+		}
+		if out[i].Runs != out[j].Runs {
+			return out[i].Runs > out[j].Runs
+		}
+		if out[i].Scenario != out[j].Scenario {
+			return out[i].Scenario < out[j].Scenario
+		}
+		if out[i].Agent != out[j].Agent {
+			return out[i].Agent < out[j].Agent
+		}
+		return out[i].Model < out[j].Model
+	})
+	return out
+}
+
+// WriteFlakinessCSV writes Flaky as CSV.
+func (r *Report) WriteFlakinessCSV(w io.Writer) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"scenario", "agent", "model", "runs", "success", "pass_ratio", "flakiness"}); err != nil {
+		return err
+	}
+	for _, f := range r.Flaky {
+		record := []string{
+			f.Scenario,
+			f.Agent,
+			f.Model,
+			strconv.Itoa(f.Runs),
+			strconv.Itoa(f.Successes),
+			types.FormatFloat(f.PassRatio),
+			types.FormatFloat(f.Flakiness),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
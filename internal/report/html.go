@@ -0,0 +1,113 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// describeFilters returns the non-default selection options as "name=value" strings, in flag order.
+func describeFilters(opts Options, limit int) []string {
+	var out []string
+	add := func(name string, values []string) {
+		if len(values) > 0 {
+			out = append(out, name+"="+strings.Join(values, ","))
+		}
+	}
+	add("scenarios", opts.Scenarios)
+	add("agents", opts.Agents)
+	add("models", opts.Models)
+	out = append(out, "limit="+strconv.Itoa(limit))
+	if opts.After != nil {
+		out = append(out, "after="+opts.After.Format("2006-01-02"))
+	}
+	if opts.AllAgentVersions {
+		out = append(out, "all-agent-versions")
+	}
+	if opts.MinSuccessRate != nil {
+		out = append(out, "min-success-rate="+types.FormatFloat(*opts.MinSuccessRate))
+	}
+	if opts.MaxSuccessRate != nil {
+		out = append(out, "max-success-rate="+types.FormatFloat(*opts.MaxSuccessRate))
+	}
+	if opts.SinceRunID != "" {
+		out = append(out, "since-run="+opts.SinceRunID)
+	}
+	if opts.ByPlatform {
+		out = append(out, "by-platform")
+	}
+	return out
+}
+
+// htmlSortScript sorts the table body by the clicked column: numerically when both cells parse as numbers (ignoring the
+// "*" estimated-cost marker and the costPrefix currency symbol, which WriteHTML defines), otherwise as text. Clicking
+// the same column again reverses the order.
+const htmlSortScript = `function sortNumber(s) {
+  if (costPrefix && s.indexOf(costPrefix) === 0) s = s.slice(costPrefix.length);
+  return parseFloat(s);
+}
+document.querySelectorAll("th").forEach(function (th, col) {
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var asc = th.dataset.dir !== "asc";
+    document.querySelectorAll("th").forEach(function (h) { delete h.dataset.dir; });
+    th.dataset.dir = asc ? "asc" : "desc";
+    var rows = Array.prototype.slice.call(tbody.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[col].textContent.replace("*", ""), y = b.cells[col].textContent.replace("*", "");
+      var nx = sortNumber(x), ny = sortNumber(y);
+      var c = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+      return asc ? c : -c;
+    });
+    rows.forEach(function (r) { tbody.appendChild(r); });
+  });
+});`
+
+// WriteHTML writes the report as a self-contained HTML page with a sortable table of Rows (the Summary row, if any, is
+// a fixed footer). The page includes generatedAt and the Filters used.
+func (r *Report) WriteHTML(w io.Writer, generatedAt time.Time) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>goagentbench report</title>\n")
+	b.WriteString("<style>\nbody { font-family: sans-serif; margin: 2em; }\ntable { border-collapse: collapse; }\n" +
+		"th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }\n" +
+		"th { background: #f0f0f0; cursor: pointer; }\nth:nth-child(-n+3), td:nth-child(-n+3) { text-align: left; }\n" +
+		"th[data-dir=asc]::after { content: \" \\25B2\"; }\nth[data-dir=desc]::after { content: \" \\25BC\"; }\n" +
+		"tfoot td { font-weight: bold; }\n</style>\n</head>\n<body>\n<h1>goagentbench report</h1>\n")
+	fmt.Fprintf(&b, "<p>Generated %s</p>\n", html.EscapeString(generatedAt.Format(time.RFC3339)))
+	if len(r.Filters) > 0 {
+		fmt.Fprintf(&b, "<p>Filters: <code>%s</code></p>\n", html.EscapeString(strings.Join(r.Filters, " ")))
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeHTMLRow(&b, "th", r.header())
+	b.WriteString("</thead>\n<tbody>\n")
+	for _, row := range r.Rows {
+		writeHTMLRow(&b, "td", r.record(row))
+	}
+	b.WriteString("</tbody>\n")
+	if r.Summary != nil {
+		b.WriteString("<tfoot>\n")
+		writeHTMLRow(&b, "td", r.record(*r.Summary))
+		b.WriteString("</tfoot>\n")
+	}
+	costPrefix := ""
+	if !r.Currency.IsUSD() {
+		costPrefix = r.Currency.Symbol()
+	}
+	b.WriteString("</table>\n<script>\nvar costPrefix = " + strconv.Quote(costPrefix) + ";\n" + htmlSortScript + "\n</script>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHTMLRow(b *strings.Builder, cell string, values []string) {
+	b.WriteString("<tr>")
+	for _, v := range values {
+		fmt.Fprintf(b, "<%s>%s</%s>", cell, html.EscapeString(v), cell)
+	}
+	b.WriteString("</tr>\n")
+}
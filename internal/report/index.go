@@ -0,0 +1,176 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/results"
+)
+
+const resultIndexVersion = 10
+
+// resultIndex caches parsed result files, keyed by file path (slash-separated), so one index can cover several results
+// dirs.
+type resultIndex struct {
+	Version int                    `json:"version"`
+	Files   map[string]indexedFile `json:"files"`
+}
+
+type indexedFile struct {
+	Size    int64        `json:"size"`
+	ModTime time.Time    `json:"mod_time"`
+	Skip    bool         `json:"skip,omitempty"` // parsed, but excluded from reports (ex: smoke)
+	Entry   *resultEntry `json:"entry,omitempty"`
+}
+
+// readResultIndex reads the index at path. A missing file, or one written by a different index version, yields an
+// empty index (ie, a full scan).
+func readResultIndex(path string) (*resultIndex, error) {
+	empty := &resultIndex{Version: resultIndexVersion, Files: map[string]indexedFile{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return empty, nil
+		}
+		return nil, err
+	}
+	var idx resultIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse index %s: %w", path, err)
+	}
+	if idx.Version != resultIndexVersion || idx.Files == nil {
+		return empty, nil
+	}
+	return &idx, nil
+}
+
+// lookup returns the cached record for rel if the file's size and mod time are unchanged. It is safe on a nil index.
+func (idx *resultIndex) lookup(key string, info fs.FileInfo) (indexedFile, bool) {
+	if idx == nil {
+		return indexedFile{}, false
+	}
+	rec, ok := idx.Files[key]
+	if !ok || rec.Size != info.Size() || !rec.ModTime.Equal(info.ModTime()) {
+		return indexedFile{}, false
+	}
+	return rec, true
+}
+
+func (idx *resultIndex) write(path string) error {
+	idx.Version = resultIndexVersion
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// entriesSinceRun returns the entries verified after the (latest) result for runID.
+func entriesSinceRun(entries []resultEntry, runID string) ([]resultEntry, error) {
+	var cutoff time.Time
+	found := false
+	for _, e := range entries {
+		if e.RunID == runID && (!found || e.VerifiedAt.After(cutoff)) {
+			cutoff = e.VerifiedAt
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("since run: no result with run id %q", runID)
+	}
+	return entriesAfter(entries, cutoff), nil
+}
+
+// entriesAfter returns the entries verified after cutoff.
+func entriesAfter(entries []resultEntry, cutoff time.Time) []resultEntry {
+	out := make([]resultEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.VerifiedAt.After(cutoff) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// cutoff returns when the (latest) indexed result for runID was verified. It is safe on a nil index.
+func (idx *resultIndex) cutoff(runID string) (time.Time, bool) {
+	var cutoff time.Time
+	found := false
+	if idx == nil {
+		return cutoff, false
+	}
+	for _, rec := range idx.Files {
+		if e := rec.Entry; e != nil && e.RunID == runID && (!found || e.VerifiedAt.After(cutoff)) {
+			cutoff = e.VerifiedAt
+			found = true
+		}
+	}
+	return cutoff, found
+}
+
+// loadResultsSince returns the results in stores verified after the (latest) result for runID. When idx has that
+// result, it's the incremental path: files the index places at or before it are taken as already reported and skipped
+// without being stated or parsed, so only new and newer files are read, and idx is updated like loadResults does.
+// Without an index (or if the index doesn't have runID), it falls back to a full scan.
+func loadResultsSince(stores []results.Store, idx *resultIndex, runID string) ([]resultEntry, error) {
+	cutoff, ok := idx.cutoff(runID)
+	if !ok {
+		entries, err := loadResults(stores, idx)
+		if err != nil {
+			return nil, err
+		}
+		return entriesSinceRun(entries, runID)
+	}
+	var out []resultEntry
+	seen := map[string]indexedFile{}
+	for _, store := range stores {
+		fsStore, ok := store.(*results.FSStore)
+		if !ok {
+			entries, err := loadResults([]results.Store{store}, nil)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, entriesAfter(entries, cutoff)...)
+			continue
+		}
+
+		files, err := fsStore.Paths()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			key := filepath.ToSlash(f.Path)
+			if rec, ok := idx.Files[key]; ok && (rec.Skip || rec.Entry != nil && !rec.Entry.VerifiedAt.After(cutoff)) {
+				seen[key] = rec
+				continue
+			}
+			if f.Info, err = os.Stat(f.Path); err != nil {
+				return nil, err
+			}
+			cached, err := readIndexedFile(fsStore, f, idx)
+			if err != nil {
+				return nil, err
+			}
+			seen[key] = cached
+			if cached.Entry != nil && cached.Entry.VerifiedAt.After(cutoff) {
+				out = append(out, *cached.Entry)
+			}
+		}
+	}
+	idx.Files = seen
+	return out, nil
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// JSONReport is the WriteJSON output.
+type JSONReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Filters     []string  `json:"filters,omitempty"`
+	Rows        []JSONRow `json:"rows"`
+	Summary     *JSONRow  `json:"summary,omitempty"`
+	// Currency is the code of the currency the cost fields are in, if not USD.
+	Currency string `json:"currency,omitempty"`
+}
+
+// JSONRow is a Row in WriteJSON output. Fields are named after the CSV columns; the optional ones are only present
+// when their column would be (ex: the avg_tok_* fields with IncludeTokens), so a present 0 is a measured 0.
+type JSONRow struct {
+	Agent string `json:"agent"`
+	Model string `json:"model"`
+	// ModelDisplay is the display name shown in the CSV model column, if different from Model.
+	ModelDisplay        string  `json:"model_display,omitempty"`
+	AgentVersion        string  `json:"agent_version"`
+	Platform            string  `json:"platform,omitempty"`
+	UniqueScenarios     int     `json:"unique_scenarios"`
+	Count               int     `json:"count"`
+	Success             int     `json:"success"`
+	PartialSuccessScore float64 `json:"partial_success_score"`
+	// SuccessRate and PartialSuccessRate are null if none of the row's results were verified (the CSV's "n/a").
+	SuccessRate        *float64 `json:"success_rate"`
+	PartialSuccessRate *float64 `json:"partial_success_rate"`
+	// Unverified is how many of Count results were recorded without verification, so the rates can be recomputed from
+	// the counts (ex: when merging this report into a later one).
+	Unverified int     `json:"unverified,omitempty"`
+	AvgCost    float64 `json:"avg_cost"`
+	// CostEstimated is true if AvgCost includes estimated cost (the CSV's cost_estimated column).
+	CostEstimated bool    `json:"cost_estimated,omitempty"`
+	AvgTime       float64 `json:"avg_time"`
+
+	AvgTokInput            *float64 `json:"avg_tok_input,omitempty"`
+	AvgTokCachedInput      *float64 `json:"avg_tok_cached_input,omitempty"`
+	AvgTokWriteCachedInput *float64 `json:"avg_tok_write_cached_input,omitempty"`
+	AvgTokOutput           *float64 `json:"avg_tok_output,omitempty"`
+	AvgTokTotal            *float64 `json:"avg_tok_total,omitempty"`
+	AvgLinesChanged        *float64 `json:"avg_lines_changed,omitempty"`
+	AvgSetupTime           *float64 `json:"avg_setup_time,omitempty"`
+	AvgFirstOutput         *float64 `json:"avg_first_output,omitempty"`
+	AvgTranscriptBytes     *float64 `json:"avg_transcript_bytes,omitempty"`
+	AvgTranscriptLines     *float64 `json:"avg_transcript_lines,omitempty"`
+	AvgInputCost           *float64 `json:"avg_input_cost,omitempty"`
+	AvgCachedInputCost     *float64 `json:"avg_cached_input_cost,omitempty"`
+	AvgOutputCost          *float64 `json:"avg_output_cost,omitempty"`
+	Errors                 *int     `json:"errors,omitempty"`
+	TopError               *string  `json:"top_error,omitempty"`
+	AvgCostNet             *float64 `json:"avg_cost_net,omitempty"`
+	MedianTime             *float64 `json:"median_time,omitempty"`
+	P90Time                *float64 `json:"p90_time,omitempty"`
+	NetworkHosts           []string `json:"network_hosts,omitempty"`
+}
+
+// WriteJSON writes the report as one indented JSON object (see JSONReport): Rows in the same order as WriteCSV, the
+// Summary row separately, and GeneratedAt and Filters as metadata. Values aren't rounded. Costs are converted to
+// r.Currency.
+func (r *Report) WriteJSON(w io.Writer) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	out := JSONReport{
+		GeneratedAt: r.GeneratedAt,
+		Filters:     r.Filters,
+		Rows:        make([]JSONRow, 0, len(r.Rows)),
+	}
+	if !r.Currency.IsUSD() {
+		out.Currency = strings.ToUpper(strings.TrimSpace(r.Currency.Code))
+	}
+	for _, row := range r.Rows {
+		out.Rows = append(out.Rows, r.jsonRow(row))
+	}
+	if r.Summary != nil {
+		summary := r.jsonRow(*r.Summary)
+		out.Summary = &summary
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// jsonRow converts row, including the optional fields the report's Include* options select.
+func (r *Report) jsonRow(row Row) JSONRow {
+	out := JSONRow{
+		Agent:               row.Agent,
+		Model:               row.Model,
+		ModelDisplay:        row.ModelDisplay,
+		AgentVersion:        row.AgentVersion,
+		UniqueScenarios:     row.UniqueScenarios,
+		Count:               row.Count,
+		Success:             row.Success,
+		PartialSuccessScore: row.PartialScoreSum,
+		AvgCost:             r.Currency.Convert(row.AvgCost),
+		CostEstimated:       row.CostEstimated,
+		AvgTime:             row.AvgTimeSeconds,
+		Unverified:          row.Unverified,
+	}
+	if r.ByPlatform {
+		out.Platform = row.Platform
+	}
+	ptr := func(v float64) *float64 { return &v }
+	if row.Count == 0 || row.Unverified < row.Count {
+		out.SuccessRate = ptr(row.SuccessRate)
+		out.PartialSuccessRate = ptr(row.PartialSuccessRate)
+	}
+	if r.IncludeTokens {
+		out.AvgTokInput = ptr(row.AvgTokInput)
+		out.AvgTokCachedInput = ptr(row.AvgTokCachedInput)
+		out.AvgTokWriteCachedInput = ptr(row.AvgTokWriteCached)
+		out.AvgTokOutput = ptr(row.AvgTokOutput)
+		out.AvgTokTotal = ptr(row.AvgTokTotal)
+	}
+	if r.IncludeLinesChanged {
+		out.AvgLinesChanged = ptr(row.AvgLinesChanged)
+	}
+	if r.IncludeSetupTime {
+		out.AvgSetupTime = ptr(row.AvgSetupSeconds)
+	}
+	if r.IncludeFirstOutput {
+		out.AvgFirstOutput = ptr(row.AvgFirstOutputSeconds)
+	}
+	if r.IncludeTranscriptSize {
+		out.AvgTranscriptBytes = ptr(row.AvgTranscriptBytes)
+		out.AvgTranscriptLines = ptr(row.AvgTranscriptLines)
+	}
+	if r.CostBreakdown {
+		out.AvgInputCost = ptr(r.Currency.Convert(row.AvgInputCost))
+		out.AvgCachedInputCost = ptr(r.Currency.Convert(row.AvgCachedInputCost))
+		out.AvgOutputCost = ptr(r.Currency.Convert(row.AvgOutputCost))
+	}
+	if r.IncludeErrors {
+		errs, topError := row.Errors, row.TopError
+		out.Errors = &errs
+		out.TopError = &topError
+	}
+	if r.IncludeNetCost {
+		out.AvgCostNet = ptr(r.Currency.Convert(row.AvgCostNet))
+	}
+	if r.IncludeLatency {
+		out.MedianTime = ptr(row.MedianTimeSeconds)
+		out.P90Time = ptr(row.P90TimeSeconds)
+	}
+	if r.IncludeNetwork {
+		out.NetworkHosts = row.NetworkHosts
+	}
+	return out
+}
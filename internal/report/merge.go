@@ -0,0 +1,117 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// readPriorReport reads a report written by WriteJSON, for Options.PriorReportPath.
+func readPriorReport(path string) (*JSONReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("prior report: %w", err)
+	}
+	var prior JSONReport
+	if err := json.Unmarshal(data, &prior); err != nil {
+		return nil, fmt.Errorf("parse prior report %s: %w", path, err)
+	}
+	if prior.Currency != "" {
+		return nil, fmt.Errorf("prior report %s has costs in %s; merging needs a report with costs in USD", path, prior.Currency)
+	}
+	return &prior, nil
+}
+
+// mergePriorRows combines rows, built from the results since the prior report, with the prior report's rows: rows with
+// the same key (see rowKey) are merged by mergeRow, and the rest are kept as they are.
+func mergePriorRows(prior []JSONRow, rows []Row, byPlatform bool) []Row {
+	byKey := make(map[string]int, len(rows))
+	for i, row := range rows {
+		byKey[rowKey(row.Agent, row.Model, row.Platform, byPlatform)] = i
+	}
+	for _, p := range prior {
+		key := rowKey(p.Agent, p.Model, p.Platform, byPlatform)
+		if i, ok := byKey[key]; ok {
+			rows[i] = mergeRow(p, rows[i])
+			continue
+		}
+		byKey[key] = len(rows)
+		rows = append(rows, mergeRow(p, Row{}))
+	}
+	return rows
+}
+
+// mergeRow adds cur, a row built from new results, to prior, the same row in a prior report. Count, Success,
+// PartialScoreSum, Unverified, and Errors add up and the rates are recomputed from them; each average is weighted by
+// its side's Count (a prior average the prior report didn't include weighs nothing). UniqueScenarios is the larger of
+// the two, since the prior report doesn't list its scenarios. The percentile columns can't be merged and are left as
+// cur's.
+func mergeRow(prior JSONRow, cur Row) Row {
+	out := cur
+	out.Agent = prior.Agent
+	out.Model = prior.Model
+	out.Platform = prior.Platform
+	if out.AgentVersion == "" {
+		out.AgentVersion = prior.AgentVersion
+	}
+	priorUnverified := prior.Unverified
+	if prior.SuccessRate == nil && priorUnverified == 0 {
+		// Reports from before the unverified field only show "all unverified", as null rates.
+		priorUnverified = prior.Count
+	}
+	out.Count = prior.Count + cur.Count
+	out.Success = prior.Success + cur.Success
+	out.PartialScoreSum = prior.PartialSuccessScore + cur.PartialScoreSum
+	out.Unverified = priorUnverified + cur.Unverified
+	out.SuccessRate, out.PartialSuccessRate = 0, 0
+	if verified := out.Count - out.Unverified; verified > 0 {
+		out.SuccessRate = float64(out.Success) / float64(verified)
+		out.PartialSuccessRate = out.PartialScoreSum / float64(verified)
+	}
+	out.UniqueScenarios = max(prior.UniqueScenarios, cur.UniqueScenarios)
+	out.CostEstimated = prior.CostEstimated || cur.CostEstimated
+
+	priorWeight, curWeight := float64(prior.Count), float64(cur.Count)
+	merge := func(dst *float64, priorAvg *float64) {
+		if priorAvg == nil || priorWeight == 0 {
+			return
+		}
+		*dst = (*priorAvg*priorWeight + *dst*curWeight) / (priorWeight + curWeight)
+	}
+	merge(&out.AvgCost, &prior.AvgCost)
+	merge(&out.AvgTimeSeconds, &prior.AvgTime)
+	merge(&out.AvgTokInput, prior.AvgTokInput)
+	merge(&out.AvgTokCachedInput, prior.AvgTokCachedInput)
+	merge(&out.AvgTokWriteCached, prior.AvgTokWriteCachedInput)
+	merge(&out.AvgTokOutput, prior.AvgTokOutput)
+	merge(&out.AvgTokTotal, prior.AvgTokTotal)
+	merge(&out.AvgLinesChanged, prior.AvgLinesChanged)
+	merge(&out.AvgSetupSeconds, prior.AvgSetupTime)
+	merge(&out.AvgFirstOutputSeconds, prior.AvgFirstOutput)
+	merge(&out.AvgTranscriptBytes, prior.AvgTranscriptBytes)
+	merge(&out.AvgTranscriptLines, prior.AvgTranscriptLines)
+	merge(&out.AvgInputCost, prior.AvgInputCost)
+	merge(&out.AvgCachedInputCost, prior.AvgCachedInputCost)
+	merge(&out.AvgOutputCost, prior.AvgOutputCost)
+	merge(&out.AvgCostNet, prior.AvgCostNet)
+
+	if prior.Errors != nil {
+		if *prior.Errors > cur.Errors && prior.TopError != nil {
+			out.TopError = *prior.TopError
+		}
+		out.Errors += *prior.Errors
+	}
+	if len(prior.NetworkHosts) > 0 {
+		hosts := map[string]bool{}
+		for _, host := range append(append([]string{}, prior.NetworkHosts...), cur.NetworkHosts...) {
+			hosts[host] = true
+		}
+		out.NetworkHosts = make([]string, 0, len(hosts))
+		for host := range hosts {
+			out.NetworkHosts = append(out.NetworkHosts, host)
+		}
+		sort.Strings(out.NetworkHosts)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// ResultLine is one result in WriteNDJSON output.
+type ResultLine struct {
+	RunID        string    `json:"run_id"`
+	Scenario     string    `json:"scenario"`
+	Agent        string    `json:"agent"`
+	AgentVersion string    `json:"agent_version"`
+	Model        string    `json:"model"`
+	VerifiedAt   time.Time `json:"verified_at"`
+	Success      bool      `json:"success"`
+	PartialScore *float64  `json:"partial_score,omitempty"`
+	// DurationSeconds is the agent's run time.
+	DurationSeconds float64          `json:"duration_seconds"`
+	TokenUsage      types.TokenUsage `json:"token_usage"`
+	// CostEstimated is true if TokenUsage.Cost was estimated from model pricing.
+	CostEstimated      bool     `json:"cost_estimated,omitempty"`
+	LinesChanged       *int     `json:"lines_changed,omitempty"`
+	SetupSeconds       *float64 `json:"setup_seconds,omitempty"`
+	FirstOutputSeconds float64  `json:"first_output_seconds,omitempty"`
+	TranscriptBytes    int      `json:"transcript_bytes,omitempty"`
+	TranscriptLines    int      `json:"transcript_lines,omitempty"`
+	Notes              string   `json:"notes,omitempty"`
+	// Platform is the "os/arch" the agent ran on, if recorded.
+	Platform string `json:"platform,omitempty"`
+	// Unverified is true for runs recorded without verification (exec --no-verify).
+	Unverified bool `json:"unverified,omitempty"`
+	// NetworkHosts are the hosts the agent contacted (agent.record-network), if recorded.
+	NetworkHosts []string `json:"network_hosts,omitempty"`
+}
+
+// WriteNDJSON writes the selected results (after filters, dedup, and --limit, but before grouping into rows) as one
+// JSON object per line, oldest first. Row filters (min/max success rate) don't apply. Ordering, dedup, and the limit need
+// every selected result, so the export is built from the results Run already loaded and is bounded by memory like the
+// other formats; only the encoded output is written a line at a time.
+func (r *Report) WriteNDJSON(w io.Writer) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	order := make([]int, len(r.entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := r.entries[order[i]], r.entries[order[j]]
+		if !a.VerifiedAt.Equal(b.VerifiedAt) {
+			return a.VerifiedAt.Before(b.VerifiedAt)
+		}
+		return a.RunID < b.RunID
+	})
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, i := range order {
+		e := r.entries[i]
+		line := ResultLine{
+			RunID:              e.RunID,
+			Scenario:           e.Scenario,
+			Agent:              e.Agent,
+			AgentVersion:       e.Version,
+			Model:              e.Model,
+			Platform:           e.Platform,
+			VerifiedAt:         e.VerifiedAt,
+			Success:            e.Success,
+			PartialScore:       e.Partial,
+			DurationSeconds:    e.Duration,
+			TokenUsage:         e.TokenUsage,
+			CostEstimated:      e.CostEstimated,
+			LinesChanged:       e.LinesChanged,
+			SetupSeconds:       e.SetupSeconds,
+			FirstOutputSeconds: e.FirstOutputSeconds,
+			TranscriptBytes:    e.TranscriptBytes,
+			TranscriptLines:    e.TranscriptLines,
+			Notes:              e.Notes,
+			Unverified:         e.Unverified,
+			NetworkHosts:       e.NetworkHosts,
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -2,24 +2,23 @@ package report
 
 import (
 	"encoding/csv"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"math"
-	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/codalotl/goagentbench/internal/agents"
+	"github.com/codalotl/goagentbench/internal/fsutil"
+	"github.com/codalotl/goagentbench/internal/results"
+	"github.com/codalotl/goagentbench/internal/semver"
 	"github.com/codalotl/goagentbench/internal/types"
 )
 
-const resultsEnvVar = "GOAGENTBENCH_RESULTS"
-
 type Options struct {
 	RootPath         string
 	Scenarios        []string
@@ -29,11 +28,70 @@ type Options struct {
 	After            *time.Time
 	AllAgentVersions bool
 	IncludeTokens    bool
+	// IncludeLinesChanged adds the avg_lines_changed column.
+	IncludeLinesChanged bool
+	// IncludeSetupTime adds the avg_setup_time column.
+	IncludeSetupTime bool
+	// IncludeFirstOutput adds the avg_first_output column (time until the agent first printed anything).
+	IncludeFirstOutput bool
+	// IncludeTranscriptSize adds the avg_transcript_bytes and avg_transcript_lines columns (how much the agent output).
+	IncludeTranscriptSize bool
+	// CostBreakdown adds avg_input_cost, avg_cached_input_cost, and avg_output_cost columns: each result's tokens of
+	// that type priced with Pricing.
+	CostBreakdown bool
+	// IncludeErrors adds the errors and top_error columns, from the agent error notes recorded with each run.
+	IncludeErrors bool
+	// IncludeNetCost adds the avg_cost_net column: cost minus the agent's measured fixed overhead (run-agent
+	// --measure-overhead).
+	IncludeNetCost bool
+	// IncludeLatency adds the median_time and p90_time columns.
+	IncludeLatency bool
+	// IncludeNetwork adds the network_hosts column: the distinct hosts the row's agents contacted, from runs with
+	// agent.record-network.
+	IncludeNetwork bool
+	// Summary appends a final "ALL" row aggregating every selected result.
+	Summary bool
+	// MinSuccessRate and MaxSuccessRate, when non-nil, drop rows whose success rate is outside [min, max]. They filter
+	// rows after aggregation, so they don't affect the Summary row.
+	MinSuccessRate *float64
+	MaxSuccessRate *float64
+	// Pricing maps model names to pricing used to estimate cost for results that have tokens but no reported cost.
+	Pricing map[string]agents.Pricing
+	// DisplayNames maps model names to friendly names shown in the model column. Rows are still grouped, sorted, and
+	// filtered by the canonical model name.
+	DisplayNames map[string]string
+	// Explain populates Report.Explanations.
+	Explain bool
+	// IndexPath, when set, caches parsed results in this file so later runs only parse new or changed result files. A
+	// missing or stale index just means a full scan.
+	IndexPath string
+	// SinceRunID and PriorReportPath, set together, make an incremental report: PriorReportPath is a report written by
+	// WriteJSON, SinceRunID the newest run it covers, and the report is the prior one's rows merged (see mergeRow) with
+	// rows built only from the results verified after that run. With an index that has the run, only newer result files
+	// are read; otherwise every file is. Filters, dedup, version selection, and Limit apply to the new results only, and
+	// it can't be combined with Explain, Flakiness, or IncludeLatency, which need every result.
+	SinceRunID      string
+	PriorReportPath string
+	// Store is where results are read from. Nil means the filesystem store at the results dir. IndexPath only applies to
+	// filesystem stores.
+	Store results.Store
+	// ResultsDirs, when set (and Store is nil), reads and merges results from each of these dirs instead of the default
+	// results dir. Relative dirs are relative to RootPath. Results are deduplicated by run id across dirs.
+	ResultsDirs []string
+	// Flakiness populates Report.Flaky.
+	Flakiness bool
+	// ByPlatform groups rows by {agent, model, platform} instead of {agent, model}, and adds the platform column. The
+	// platform is the "os/arch" the agent ran on, or PlatformUnknown for results that didn't record it.
+	ByPlatform bool
+	// Currency is the currency cost columns are shown in (default USD). Row costs stay in USD.
+	Currency Currency
 }
 
 type Row struct {
-	Agent              string
-	Model              string
+	Agent string
+	Model string
+	// ModelDisplay, when set, is shown instead of Model (see Options.DisplayNames).
+	ModelDisplay       string
 	AgentVersion       string
 	UniqueScenarios    int
 	Count              int
@@ -48,13 +106,72 @@ type Row struct {
 	AvgTokWriteCached  float64
 	AvgTokOutput       float64
 	AvgTokTotal        float64
+	AvgLinesChanged    float64
+	AvgSetupSeconds    float64
+	// AvgFirstOutputSeconds averages the agent's time to first output over results that recorded it.
+	AvgFirstOutputSeconds float64
+	// AvgTranscriptBytes and AvgTranscriptLines average the agent's transcript size over results that recorded it.
+	AvgTranscriptBytes float64
+	AvgTranscriptLines float64
+	// AvgInputCost, AvgCachedInputCost, and AvgOutputCost split cost by token type (see Options.CostBreakdown). They
+	// average over results whose model has pricing.
+	AvgInputCost       float64
+	AvgCachedInputCost float64
+	AvgOutputCost      float64
+	// Errors is how many results recorded an agent error (non-empty progress notes). TopError is the most common one.
+	Errors   int
+	TopError string
+	// CostEstimated is true if AvgCost includes any estimated (not agent-reported) cost.
+	CostEstimated bool
+	// Platform is the row's "os/arch" with Options.ByPlatform (otherwise "").
+	Platform string
+	// AvgCostNet averages cost minus the measured overhead cost (floored at 0) over results that measured overhead.
+	AvgCostNet float64
+	// MedianTimeSeconds and P90TimeSeconds are percentiles of the same durations AvgTimeSeconds averages.
+	MedianTimeSeconds float64
+	P90TimeSeconds    float64
+	// NetworkHosts are the distinct hosts, sorted, recorded by the row's results with agent.record-network.
+	NetworkHosts []string
+	// Unverified is how many of Count results were recorded without verification. If all were, the row has no
+	// success rates (shown as "n/a").
+	Unverified int
 }
 
 type Report struct {
-	IncludeTokens bool
-	Rows          []Row
+	IncludeTokens         bool
+	IncludeLinesChanged   bool
+	IncludeSetupTime      bool
+	IncludeFirstOutput    bool
+	IncludeTranscriptSize bool
+	CostBreakdown         bool
+	IncludeErrors         bool
+	IncludeNetCost        bool
+	IncludeLatency        bool
+	IncludeNetwork        bool
+	ByPlatform            bool
+	Rows                  []Row
+	// Summary, when non-nil, is written as the last CSV row. Its Agent is SummaryAgent.
+	Summary *Row
+	// Explanations, when Options.Explain is set, describe how each row's results were selected (in row order).
+	Explanations []RowExplanation
+	// Flaky, when Options.Flakiness is set, lists {scenario, agent, model} combos with mixed outcomes.
+	Flaky []FlakyCombo
+	// Filters describes the selection options used (ex: "agents=codex", "limit=1").
+	Filters []string
+	// GeneratedAt is when Run built the report, written by WriteJSON.
+	GeneratedAt time.Time
+	// Currency is the currency WriteCSV, WriteHTML, and WriteJSON show costs in. Row costs are in USD.
+	Currency Currency
+	// entries are the selected results behind Rows, for WriteNDJSON.
+	entries []resultEntry
 }
 
+// SummaryAgent is the agent column value used for the summary row.
+const SummaryAgent = "ALL"
+
+// PlatformUnknown is the platform column value for results that didn't record the system they ran on.
+const PlatformUnknown = "unknown"
+
 func Run(opts Options) (*Report, error) {
 	if strings.TrimSpace(opts.RootPath) == "" {
 		return nil, errors.New("RootPath is required")
@@ -66,11 +183,72 @@ func Run(opts Options) (*Report, error) {
 	if limit < 1 {
 		return nil, fmt.Errorf("limit must be >= 1, got %d", limit)
 	}
+	if err := validateRate("min success rate", opts.MinSuccessRate); err != nil {
+		return nil, err
+	}
+	if err := validateRate("max success rate", opts.MaxSuccessRate); err != nil {
+		return nil, err
+	}
+	if opts.MinSuccessRate != nil && opts.MaxSuccessRate != nil && *opts.MinSuccessRate > *opts.MaxSuccessRate {
+		return nil, fmt.Errorf("min success rate %v is greater than max success rate %v", *opts.MinSuccessRate, *opts.MaxSuccessRate)
+	}
+	if err := opts.Currency.Validate(); err != nil {
+		return nil, err
+	}
+	var prior *JSONReport
+	if opts.SinceRunID != "" || opts.PriorReportPath != "" {
+		if opts.SinceRunID == "" || opts.PriorReportPath == "" {
+			return nil, errors.New("since run and prior report must be set together")
+		}
+		if opts.Explain || opts.Flakiness || opts.IncludeLatency {
+			return nil, errors.New("an incremental report (since run) cannot be combined with explain, flakiness, or latency columns")
+		}
+		var err error
+		if prior, err = readPriorReport(opts.PriorReportPath); err != nil {
+			return nil, err
+		}
+		if opts.Summary && prior.Summary == nil {
+			return nil, fmt.Errorf("prior report %s has no summary row to merge into", opts.PriorReportPath)
+		}
+	}
 
-	entries, err := loadResults(resultsDir(opts.RootPath))
+	var idx *resultIndex
+	if opts.IndexPath != "" {
+		// Held until the index is rewritten, so concurrent reports sharing an index don't clobber each other's writes.
+		unlock, err := fsutil.LockFile(opts.IndexPath)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = unlock() }()
+		idx, err = readResultIndex(opts.IndexPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var stores []results.Store
+	switch {
+	case opts.Store != nil:
+		stores = []results.Store{opts.Store}
+	default:
+		for _, dir := range resultsDirs(opts) {
+			stores = append(stores, results.NewFSStore(dir))
+		}
+	}
+	var entries []resultEntry
+	var err error
+	if opts.SinceRunID != "" {
+		entries, err = loadResultsSince(stores, idx, opts.SinceRunID)
+	} else {
+		entries, err = loadResults(stores, idx)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if idx != nil {
+		if err := idx.write(opts.IndexPath); err != nil {
+			return nil, err
+		}
+	}
 
 	scenarioSet := sliceToSet(opts.Scenarios)
 	agentSet := sliceToSet(opts.Agents)
@@ -87,7 +265,8 @@ func Run(opts Options) (*Report, error) {
 		if agentSet != nil && !agentSet[agent] {
 			continue
 		}
-		if modelSet != nil && !modelSet[model] {
+		baseModel, _, _ := strings.Cut(model, "@")
+		if modelSet != nil && !modelSet[model] && !modelSet[baseModel] {
 			continue
 		}
 		if opts.After != nil && e.VerifiedAt.Before(*opts.After) {
@@ -96,15 +275,24 @@ func Run(opts Options) (*Report, error) {
 		filtered = append(filtered, e)
 	}
 
+	tracker := explainTracker{byPlatform: opts.ByPlatform}
+	tracker.record(0, filtered)
 	filtered = dedupByRunIDKeepLatest(filtered)
+	tracker.record(1, filtered)
 	if !opts.AllAgentVersions {
 		filtered = filterToLatestVersionPerAgentModel(filtered)
 	}
+	tracker.record(2, filtered)
 	filtered = applyLimitPerScenarioAgentModel(filtered, limit)
+	tracker.record(3, filtered)
+	estimateMissingCosts(filtered, opts.Pricing)
+	if opts.CostBreakdown {
+		priceTokenTypes(filtered, opts.Pricing)
+	}
 
 	grouped := map[string][]resultEntry{}
 	for _, e := range filtered {
-		key := agentModelKey(e.Agent, e.Model)
+		key := rowKey(e.Agent, e.Model, e.Platform, opts.ByPlatform)
 		grouped[key] = append(grouped[key], e)
 	}
 
@@ -114,8 +302,15 @@ func Run(opts Options) (*Report, error) {
 		if !ok {
 			continue
 		}
+		if opts.ByPlatform {
+			row.Platform = platformLabel(group[0].Platform)
+		}
 		rows = append(rows, row)
 	}
+	if prior != nil {
+		rows = mergePriorRows(prior.Rows, rows, opts.ByPlatform)
+	}
+	rows = filterRowsBySuccessRate(rows, opts.MinSuccessRate, opts.MaxSuccessRate)
 
 	sort.Slice(rows, func(i, j int) bool {
 		if rows[i].SuccessRate != rows[j].SuccessRate {
@@ -127,19 +322,175 @@ func Run(opts Options) (*Report, error) {
 		if rows[i].Agent != rows[j].Agent {
 			return rows[i].Agent < rows[j].Agent
 		}
-		return rows[i].Model < rows[j].Model
+		if rows[i].Model != rows[j].Model {
+			return rows[i].Model < rows[j].Model
+		}
+		return rows[i].Platform < rows[j].Platform
 	})
+	for i := range rows {
+		rows[i].ModelDisplay = displayModel(rows[i].Model, opts.DisplayNames)
+	}
+
+	rep := &Report{
+		IncludeTokens:         opts.IncludeTokens,
+		IncludeLinesChanged:   opts.IncludeLinesChanged,
+		IncludeSetupTime:      opts.IncludeSetupTime,
+		IncludeFirstOutput:    opts.IncludeFirstOutput,
+		IncludeTranscriptSize: opts.IncludeTranscriptSize,
+		CostBreakdown:         opts.CostBreakdown,
+		IncludeErrors:         opts.IncludeErrors,
+		IncludeNetCost:        opts.IncludeNetCost,
+		IncludeLatency:        opts.IncludeLatency,
+		IncludeNetwork:        opts.IncludeNetwork,
+		ByPlatform:            opts.ByPlatform,
+		Currency:              opts.Currency,
+		Rows:                  rows,
+		Filters:               describeFilters(opts, limit),
+		GeneratedAt:           time.Now(),
+		entries:               filtered,
+	}
+	if opts.Summary {
+		rep.Summary = buildSummaryRow(filtered)
+		if prior != nil {
+			summary := mergeRow(*prior.Summary, *rep.Summary)
+			summary.Agent, summary.Model, summary.AgentVersion = SummaryAgent, "", ""
+			rep.Summary = &summary
+		}
+	}
+	if opts.Explain {
+		rep.Explanations = tracker.explain(rows, grouped)
+	}
+	if opts.Flakiness {
+		rep.Flaky = findFlakyCombos(filtered)
+	}
+	return rep, nil
+}
+
+func validateRate(name string, rate *float64) error {
+	if rate == nil {
+		return nil
+	}
+	if *rate < 0 || *rate > 1 {
+		return fmt.Errorf("%s must be between 0 and 1, got %v", name, *rate)
+	}
+	return nil
+}
 
-	return &Report{
-		IncludeTokens: opts.IncludeTokens,
-		Rows:          rows,
-	}, nil
+// filterRowsBySuccessRate keeps rows whose SuccessRate is within the optional [lo, hi] bounds (inclusive).
+func filterRowsBySuccessRate(rows []Row, lo, hi *float64) []Row {
+	if lo == nil && hi == nil {
+		return rows
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		if lo != nil && row.SuccessRate < *lo {
+			continue
+		}
+		if hi != nil && row.SuccessRate > *hi {
+			continue
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// estimateMissingCosts fills in TokenUsage.Cost for entries that have token counts but no reported cost, using the
+// entry's model pricing. Reported costs are always preferred.
+func estimateMissingCosts(entries []resultEntry, pricing map[string]agents.Pricing) {
+	if len(pricing) == 0 {
+		return
+	}
+	for i := range entries {
+		e := &entries[i]
+		baseModel, _, _ := strings.Cut(e.Model, "@")
+		p, ok := pricing[baseModel]
+		if !ok {
+			continue
+		}
+		if o := e.Overhead; o != nil && o.Cost == 0 && (o.Input != 0 || o.CachedInput != 0 || o.Output != 0) {
+			// Copied, since the entry may share it with the index.
+			estimated := *o
+			estimated.Cost = p.Cost(o.Input, o.CachedInput, o.Output)
+			e.Overhead = &estimated
+		}
+		u := e.TokenUsage
+		if u.Cost != 0 || (u.Input == 0 && u.CachedInput == 0 && u.Output == 0) {
+			continue
+		}
+		e.TokenUsage.Cost = p.Cost(u.Input, u.CachedInput, u.Output)
+		e.CostEstimated = true
+	}
+}
+
+// priceTokenTypes sets the per-token-type costs of entries whose model has pricing.
+func priceTokenTypes(entries []resultEntry, pricing map[string]agents.Pricing) {
+	for i := range entries {
+		e := &entries[i]
+		baseModel, _, _ := strings.Cut(e.Model, "@")
+		p, ok := pricing[baseModel]
+		if !ok {
+			continue
+		}
+		u := e.TokenUsage
+		input, cached, output := p.Breakdown(u.Input, u.CachedInput, u.Output)
+		e.TypeCosts = &tokenTypeCosts{input: input, cachedInput: cached, output: output}
+	}
+}
+
+// buildSummaryRow aggregates all entries into a single row. Entries have already been filtered to the selected agent
+// versions, so every entry is included.
+func buildSummaryRow(entries []resultEntry) *Row {
+	row, ok := buildRow(entries, true)
+	if !ok {
+		row = Row{}
+	}
+	row.Agent = SummaryAgent
+	row.Model = ""
+	row.AgentVersion = ""
+	return &row
 }
 
 func (r *Report) WriteCSV(w io.Writer) error {
 	if w == nil {
 		return errors.New("writer is nil")
 	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.header()); err != nil {
+		return err
+	}
+	for _, row := range r.rowsWithSummary() {
+		if err := cw.Write(r.record(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ModelLabel returns the model name to show for row: ModelDisplay if set, otherwise Model.
+func (row Row) ModelLabel() string {
+	if row.ModelDisplay != "" {
+		return row.ModelDisplay
+	}
+	return row.Model
+}
+
+// displayModel returns the display name for model (keeping any "@<reasoning>" override suffix), or "" if names has
+// none.
+func displayModel(model string, names map[string]string) string {
+	base, level, hasLevel := strings.Cut(model, "@")
+	name := strings.TrimSpace(names[base])
+	if name == "" {
+		return ""
+	}
+	if hasLevel {
+		return name + "@" + level
+	}
+	return name
+}
+
+// header returns the column names for the report's rows, honoring the Include* options.
+func (r *Report) header() []string {
 	header := []string{
 		"agent",
 		"model",
@@ -151,8 +502,12 @@ func (r *Report) WriteCSV(w io.Writer) error {
 		"success_rate",
 		"partial_success_rate",
 		"avg_cost",
+		"cost_estimated",
 		"avg_time",
 	}
+	if r.ByPlatform {
+		header = append(header[:3:3], append([]string{"platform"}, header[3:]...)...)
+	}
 	if r.IncludeTokens {
 		header = append(header,
 			"avg_tok_input",
@@ -162,41 +517,109 @@ func (r *Report) WriteCSV(w io.Writer) error {
 			"avg_tok_total",
 		)
 	}
+	if r.IncludeLinesChanged {
+		header = append(header, "avg_lines_changed")
+	}
+	if r.IncludeSetupTime {
+		header = append(header, "avg_setup_time")
+	}
+	if r.IncludeFirstOutput {
+		header = append(header, "avg_first_output")
+	}
+	if r.IncludeTranscriptSize {
+		header = append(header, "avg_transcript_bytes", "avg_transcript_lines")
+	}
+	if r.CostBreakdown {
+		header = append(header, "avg_input_cost", "avg_cached_input_cost", "avg_output_cost")
+	}
+	if r.IncludeErrors {
+		header = append(header, "errors", "top_error")
+	}
+	if r.IncludeNetCost {
+		header = append(header, "avg_cost_net")
+	}
+	if r.IncludeLatency {
+		header = append(header, "median_time", "p90_time")
+	}
+	if r.IncludeNetwork {
+		header = append(header, "network_hosts")
+	}
+	return header
+}
 
-	cw := csv.NewWriter(w)
-	if err := cw.Write(header); err != nil {
-		return err
+// record formats row as the values for header.
+func (r *Report) record(row Row) []string {
+	record := []string{
+		row.Agent,
+		row.ModelLabel(),
+		row.AgentVersion,
+		strconv.Itoa(row.UniqueScenarios),
+		strconv.Itoa(row.Count),
+		strconv.Itoa(row.Success),
+		types.FormatFloat(row.PartialScoreSum),
+		row.formatRate(row.SuccessRate),
+		row.formatRate(row.PartialSuccessRate),
+		r.formatMoney(row.AvgCost),
+		strconv.FormatBool(row.CostEstimated),
+		types.FormatFloat(row.AvgTimeSeconds),
+	}
+	if r.ByPlatform {
+		record = append(record[:3:3], append([]string{row.Platform}, record[3:]...)...)
+	}
+	if r.IncludeTokens {
+		record = append(record,
+			types.FormatFloat(row.AvgTokInput),
+			types.FormatFloat(row.AvgTokCachedInput),
+			types.FormatFloat(row.AvgTokWriteCached),
+			types.FormatFloat(row.AvgTokOutput),
+			types.FormatFloat(row.AvgTokTotal),
+		)
+	}
+	if r.IncludeLinesChanged {
+		record = append(record, types.FormatFloat(row.AvgLinesChanged))
+	}
+	if r.IncludeSetupTime {
+		record = append(record, types.FormatFloat(row.AvgSetupSeconds))
+	}
+	if r.IncludeFirstOutput {
+		record = append(record, types.FormatFloat(row.AvgFirstOutputSeconds))
+	}
+	if r.IncludeTranscriptSize {
+		record = append(record, types.FormatFloat(row.AvgTranscriptBytes), types.FormatFloat(row.AvgTranscriptLines))
+	}
+	if r.CostBreakdown {
+		record = append(record, r.formatMoney(row.AvgInputCost), r.formatMoney(row.AvgCachedInputCost), r.formatMoney(row.AvgOutputCost))
+	}
+	if r.IncludeErrors {
+		record = append(record, strconv.Itoa(row.Errors), row.TopError)
+	}
+	if r.IncludeNetCost {
+		record = append(record, r.formatMoney(row.AvgCostNet))
 	}
+	if r.IncludeLatency {
+		record = append(record, types.FormatFloat(row.MedianTimeSeconds), types.FormatFloat(row.P90TimeSeconds))
+	}
+	if r.IncludeNetwork {
+		record = append(record, strings.Join(row.NetworkHosts, " "))
+	}
+	return record
+}
 
-	for _, row := range r.Rows {
-		record := []string{
-			row.Agent,
-			row.Model,
-			row.AgentVersion,
-			strconv.Itoa(row.UniqueScenarios),
-			strconv.Itoa(row.Count),
-			strconv.Itoa(row.Success),
-			formatFloat(row.PartialScoreSum),
-			formatFloat(row.SuccessRate),
-			formatFloat(row.PartialSuccessRate),
-			formatFloat(row.AvgCost),
-			formatFloat(row.AvgTimeSeconds),
-		}
-		if r.IncludeTokens {
-			record = append(record,
-				formatFloat(row.AvgTokInput),
-				formatFloat(row.AvgTokCachedInput),
-				formatFloat(row.AvgTokWriteCached),
-				formatFloat(row.AvgTokOutput),
-				formatFloat(row.AvgTokTotal),
-			)
-		}
-		if err := cw.Write(record); err != nil {
-			return err
-		}
+// formatRate formats one of row's success rates, or "n/a" if none of its results were verified.
+func (row Row) formatRate(rate float64) string {
+	if row.Count > 0 && row.Unverified == row.Count {
+		return "n/a"
 	}
-	cw.Flush()
-	return cw.Error()
+	return types.FormatFloat(rate)
+}
+
+// rowsWithSummary returns Rows followed by Summary, if any.
+func (r *Report) rowsWithSummary() []Row {
+	rows := r.Rows
+	if r.Summary != nil {
+		rows = append(rows[:len(rows):len(rows)], *r.Summary)
+	}
+	return rows
 }
 
 type resultEntry struct {
@@ -210,102 +633,177 @@ type resultEntry struct {
 	Partial    *float64
 	Duration   float64
 	TokenUsage types.TokenUsage
+	// CostEstimated is true when TokenUsage.Cost was computed from Options.Pricing.
+	CostEstimated bool
+	// LinesChanged is lines added + deleted, or nil if the result predates diff measurement.
+	LinesChanged *int
+	// SetupSeconds is the workspace setup duration, or nil if it wasn't recorded.
+	SetupSeconds *float64
+	// FirstOutputSeconds is the agent's time to first output (0 if not recorded).
+	FirstOutputSeconds float64
+	// TranscriptBytes and TranscriptLines are the agent's transcript size (0 if not recorded).
+	TranscriptBytes int
+	TranscriptLines int
+	// Notes is the agent error recorded in the run's progress, if any, collapsed to one line.
+	Notes string
+	// Platform is the "os/arch" the agent ran on, or "" if the result didn't record it.
+	Platform string
+	// Overhead is the run's measured fixed overhead (run-agent --measure-overhead), or nil if it wasn't measured.
+	Overhead *types.TokenUsage
+	// Unverified is true for results recorded without verification (exec --no-verify).
+	Unverified bool
+	// NetworkHosts are the hosts the agent contacted (agent.record-network), if recorded.
+	NetworkHosts []string
+	// TypeCosts is set by priceTokenTypes. It's derived from Options.Pricing, so it's never cached in the index.
+	TypeCosts *tokenTypeCosts `json:"-"`
 }
 
-func loadResults(dir string) ([]resultEntry, error) {
-	if _, err := os.Stat(dir); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil
-		}
-		return nil, err
-	}
+// tokenTypeCosts is a result's cost split by token type.
+type tokenTypeCosts struct {
+	input       float64
+	cachedInput float64
+	output      float64
+}
 
+// loadResults reads every result in stores, in order. For filesystem stores, if idx is non-nil, files whose size and
+// mod time match an index record are taken from the index instead of being re-parsed, and idx is updated to describe
+// the current files.
+func loadResults(stores []results.Store, idx *resultIndex) ([]resultEntry, error) {
 	var out []resultEntry
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		if d.IsDir() {
-			if filepath.Clean(path) == filepath.Join(dir, "smoke") {
-				return fs.SkipDir
+	seen := map[string]indexedFile{}
+	for _, store := range stores {
+		fsStore, ok := store.(*results.FSStore)
+		if !ok {
+			records, err := store.List()
+			if err != nil {
+				return nil, err
 			}
-			return nil
-		}
-		if !strings.HasSuffix(d.Name(), ".verify.json") {
-			return nil
-		}
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		var rep types.VerificationReport
-		if err := json.Unmarshal(data, &rep); err != nil {
-			return fmt.Errorf("parse %s: %w", path, err)
-		}
-
-		verifiedAt := rep.VerifiedAt
-		if verifiedAt.IsZero() {
-			info, err := os.Stat(path)
-			if err == nil {
-				verifiedAt = info.ModTime()
+			for _, rec := range records {
+				if entry, ok := entryFromRecord(rec); ok {
+					out = append(out, entry)
+				}
 			}
+			continue
 		}
 
-		scenarioName := strings.TrimSpace(rep.Scenario)
-		if scenarioName == "" {
-			scenarioName = scenarioFromPath(dir, path)
-		}
-		if scenarioName == "smoke" {
-			return nil
+		files, err := fsStore.Files()
+		if err != nil {
+			return nil, err
 		}
-
-		var duration float64
-		var usage types.TokenUsage
-		if rep.Progress != nil {
-			duration = rep.Progress.DurationSeconds
-			usage = rep.Progress.TokenUsage
+		for _, f := range files {
+			key := filepath.ToSlash(f.Path)
+			cached, err := readIndexedFile(fsStore, f, idx)
+			if err != nil {
+				return nil, err
+			}
+			seen[key] = cached
+			if cached.Entry != nil {
+				out = append(out, *cached.Entry)
+			}
 		}
-
-		out = append(out, resultEntry{
-			RunID:      strings.TrimSpace(rep.RunID),
-			Scenario:   scenarioName,
-			Agent:      strings.TrimSpace(rep.Agent),
-			Model:      strings.TrimSpace(rep.Model),
-			Version:    strings.TrimSpace(rep.AgentVersion),
-			VerifiedAt: verifiedAt,
-			Success:    rep.Success,
-			Partial:    rep.PartialScore,
-			Duration:   duration,
-			TokenUsage: usage,
-		})
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	}
+	if idx != nil {
+		idx.Files = seen
 	}
 	return out, nil
 }
 
-func scenarioFromPath(resultsDir, filePath string) string {
-	rel, err := filepath.Rel(resultsDir, filePath)
+// readIndexedFile returns f's index record: idx's, if f is unchanged since it was indexed, or else f parsed. f.Info
+// must be set.
+func readIndexedFile(fsStore *results.FSStore, f results.File, idx *resultIndex) (indexedFile, error) {
+	if cached, hit := idx.lookup(filepath.ToSlash(f.Path), f.Info); hit {
+		return cached, nil
+	}
+	rec, err := fsStore.Read(f)
 	if err != nil {
-		return ""
+		return indexedFile{}, err
 	}
-	parts := strings.Split(rel, string(filepath.Separator))
-	if len(parts) == 0 {
-		return ""
+	entry, ok := entryFromRecord(rec)
+	cached := indexedFile{Size: f.Info.Size(), ModTime: f.Info.ModTime(), Skip: !ok}
+	if ok {
+		cached.Entry = &entry
 	}
-	return strings.TrimSpace(parts[0])
+	return cached, nil
 }
 
-func resultsDir(rootPath string) string {
-	if env := strings.TrimSpace(os.Getenv(resultsEnvVar)); env != "" {
-		if filepath.IsAbs(env) {
-			return filepath.Clean(env)
+// entryFromRecord converts a stored report to a resultEntry. ok is false for results that are excluded from reports
+// (smoke).
+func entryFromRecord(rec results.Record) (resultEntry, bool) {
+	rep := rec.Report
+	verifiedAt := rep.VerifiedAt
+	if verifiedAt.IsZero() {
+		verifiedAt = rec.ModTime
+	}
+
+	scenarioName := strings.TrimSpace(rep.Scenario)
+	if scenarioName == "" {
+		scenarioName = results.ScenarioFromKey(rec.Key)
+	}
+	if scenarioName == "smoke" {
+		return resultEntry{}, false
+	}
+
+	var duration, firstOutput float64
+	var transcriptBytes, transcriptLines int
+	var notes string
+	var networkHosts []string
+	var usage types.TokenUsage
+	var overhead *types.TokenUsage
+	model := strings.TrimSpace(rep.Model)
+	if rep.Progress != nil {
+		duration = rep.Progress.DurationSeconds
+		firstOutput = rep.Progress.FirstOutputSeconds
+		transcriptBytes = rep.Progress.TranscriptBytes
+		transcriptLines = rep.Progress.TranscriptLines
+		notes = strings.Join(strings.Fields(rep.Progress.Notes), " ")
+		usage = rep.Progress.TokenUsage
+		overhead = rep.Progress.OverheadTokenUsage
+		networkHosts = rep.Progress.NetworkHosts
+		// Runs with a --reasoning override are reported separately from the model's configured level.
+		if rep.Progress.ReasoningOverride && rep.Progress.ReasoningLevel != "" {
+			model += "@" + rep.Progress.ReasoningLevel
+		}
+	}
+
+	var platform string
+	if rep.System != nil && rep.System.OS != "" && rep.System.Arch != "" {
+		platform = rep.System.OS + "/" + rep.System.Arch
+	}
+
+	var linesChanged *int
+	if rep.LinesAdded != nil || rep.LinesDeleted != nil {
+		n := 0
+		if rep.LinesAdded != nil {
+			n += *rep.LinesAdded
+		}
+		if rep.LinesDeleted != nil {
+			n += *rep.LinesDeleted
 		}
-		return filepath.Join(rootPath, filepath.Clean(env))
+		linesChanged = &n
 	}
-	return filepath.Join(rootPath, "results")
+
+	return resultEntry{
+		RunID:              strings.TrimSpace(rep.RunID),
+		Scenario:           scenarioName,
+		Agent:              strings.TrimSpace(rep.Agent),
+		Model:              model,
+		Version:            strings.TrimSpace(rep.AgentVersion),
+		Platform:           platform,
+		Overhead:           overhead,
+		VerifiedAt:         verifiedAt,
+		Success:            rep.Success,
+		Partial:            rep.PartialScore,
+		Duration:           duration,
+		TokenUsage:         usage,
+		LinesChanged:       linesChanged,
+		SetupSeconds:       rep.SetupSeconds,
+		FirstOutputSeconds: firstOutput,
+		TranscriptBytes:    transcriptBytes,
+		TranscriptLines:    transcriptLines,
+		Notes:              notes,
+		Unverified:         rep.Unverified,
+		NetworkHosts:       networkHosts,
+	}, true
 }
 
 func sliceToSet(items []string) map[string]bool {
@@ -327,6 +825,22 @@ func agentModelKey(agent, model string) string {
 	return strings.TrimSpace(agent) + "\x00" + strings.TrimSpace(model)
 }
 
+// rowKey returns the key results are grouped into rows by: {agent, model}, plus platform if byPlatform.
+func rowKey(agent, model, platform string, byPlatform bool) string {
+	if !byPlatform {
+		return agentModelKey(agent, model)
+	}
+	return agentModelKey(agent, model) + "\x00" + platformLabel(platform)
+}
+
+// platformLabel returns platform, or PlatformUnknown if it's empty.
+func platformLabel(platform string) string {
+	if platform == "" {
+		return PlatformUnknown
+	}
+	return platform
+}
+
 func scenarioAgentModelKey(sc, agent, model string) string {
 	return strings.TrimSpace(sc) + "\x00" + strings.TrimSpace(agent) + "\x00" + strings.TrimSpace(model)
 }
@@ -352,10 +866,15 @@ func dedupByRunIDKeepLatest(entries []resultEntry) []resultEntry {
 	return out
 }
 
+// applyLimitPerScenarioAgentModel keeps the latest limit verified results, and the latest limit unverified ones, per
+// {scenario, agent, model}: a newer exec --no-verify run doesn't displace the verified result it can't replace.
 func applyLimitPerScenarioAgentModel(entries []resultEntry, limit int) []resultEntry {
 	grouped := map[string][]resultEntry{}
 	for _, e := range entries {
 		key := scenarioAgentModelKey(e.Scenario, e.Agent, e.Model)
+		if e.Unverified {
+			key += "\x00unverified"
+		}
 		grouped[key] = append(grouped[key], e)
 	}
 	out := make([]resultEntry, 0, len(entries))
@@ -396,6 +915,7 @@ func buildRow(group []resultEntry, allAgentVersions bool) (Row, bool) {
 	versions := map[string]bool{}
 
 	successCount := 0
+	verifiedCount := 0
 	partialSum := 0.0
 	var costs []float64
 	var times []float64
@@ -404,19 +924,33 @@ func buildRow(group []resultEntry, allAgentVersions bool) (Row, bool) {
 	var tokWriteCached []float64
 	var tokOut []float64
 	var tokTotal []float64
+	var linesChanged []float64
+	var setupTimes []float64
+	var firstOutputs []float64
+	var transcriptBytes, transcriptLines []float64
+	var inputCosts, cachedInputCosts, outputCosts []float64
+	var netCosts []float64
+	errorCounts := map[string]int{}
+	networkHosts := map[string]bool{}
+	costEstimated := false
 
 	for _, e := range group {
 		uniqueScenarios[e.Scenario] = true
 		if strings.TrimSpace(e.Version) != "" {
 			versions[e.Version] = true
 		}
-		if e.Success {
-			successCount++
+		// Unverified results have no outcome: they count toward Count and the averages, but not the success rates.
+		if !e.Unverified {
+			verifiedCount++
+			if e.Success {
+				successCount++
+			}
+			partialSum += partialScore(e)
 		}
-		partialSum += partialScore(e)
 
 		if e.TokenUsage.Cost != 0 {
 			costs = append(costs, e.TokenUsage.Cost)
+			costEstimated = costEstimated || e.CostEstimated
 		}
 		if e.Duration != 0 {
 			times = append(times, e.Duration)
@@ -436,17 +970,54 @@ func buildRow(group []resultEntry, allAgentVersions bool) (Row, bool) {
 		if e.TokenUsage.Total != 0 {
 			tokTotal = append(tokTotal, float64(e.TokenUsage.Total))
 		}
+		// Unlike tokens, zero lines changed is a real measurement; only results without a diff stat are missing.
+		if e.LinesChanged != nil {
+			linesChanged = append(linesChanged, float64(*e.LinesChanged))
+		}
+		if e.SetupSeconds != nil {
+			setupTimes = append(setupTimes, *e.SetupSeconds)
+		}
+		if e.FirstOutputSeconds != 0 {
+			firstOutputs = append(firstOutputs, e.FirstOutputSeconds)
+		}
+		// Results from before transcript sizes were recorded have 0; an agent that said nothing is rare enough to
+		// treat the same.
+		if e.TranscriptBytes != 0 {
+			transcriptBytes = append(transcriptBytes, float64(e.TranscriptBytes))
+			transcriptLines = append(transcriptLines, float64(e.TranscriptLines))
+		}
+		if e.Notes != "" {
+			errorCounts[e.Notes]++
+		}
+		for _, host := range e.NetworkHosts {
+			networkHosts[host] = true
+		}
+		if c := e.TypeCosts; c != nil {
+			inputCosts = append(inputCosts, c.input)
+			cachedInputCosts = append(cachedInputCosts, c.cachedInput)
+			outputCosts = append(outputCosts, c.output)
+		}
+		if e.Overhead != nil {
+			netCosts = append(netCosts, math.Max(0, e.TokenUsage.Cost-e.Overhead.Cost))
+		}
 	}
 
 	count := len(group)
 	successRate := 0.0
-	if count > 0 {
-		successRate = float64(successCount) / float64(count)
+	if verifiedCount > 0 {
+		successRate = float64(successCount) / float64(verifiedCount)
 	}
 	partialRate := 0.0
-	if count > 0 {
-		partialRate = partialSum / float64(count)
+	if verifiedCount > 0 {
+		partialRate = partialSum / float64(verifiedCount)
+	}
+
+	errorCount, topError := mostCommon(errorCounts)
+	var hostList []string
+	for host := range networkHosts {
+		hostList = append(hostList, host)
 	}
+	sort.Strings(hostList)
 
 	versionList := uniqueVersionsSorted(versions)
 	versionValue := ""
@@ -460,25 +1031,53 @@ func buildRow(group []resultEntry, allAgentVersions bool) (Row, bool) {
 	}
 
 	return Row{
-		Agent:              agent,
-		Model:              model,
-		AgentVersion:       versionValue,
-		UniqueScenarios:    len(uniqueScenarios),
-		Count:              count,
-		Success:            successCount,
-		PartialScoreSum:    partialSum,
-		SuccessRate:        successRate,
-		PartialSuccessRate: partialRate,
-		AvgCost:            avgOrZero(costs),
-		AvgTimeSeconds:     avgOrZero(times),
-		AvgTokInput:        avgOrZero(tokIn),
-		AvgTokCachedInput:  avgOrZero(tokCached),
-		AvgTokWriteCached:  avgOrZero(tokWriteCached),
-		AvgTokOutput:       avgOrZero(tokOut),
-		AvgTokTotal:        avgOrZero(tokTotal),
+		Agent:                 agent,
+		Model:                 model,
+		AgentVersion:          versionValue,
+		UniqueScenarios:       len(uniqueScenarios),
+		Count:                 count,
+		Success:               successCount,
+		PartialScoreSum:       partialSum,
+		SuccessRate:           successRate,
+		PartialSuccessRate:    partialRate,
+		AvgCost:               avgOrZero(costs),
+		AvgTimeSeconds:        avgOrZero(times),
+		AvgTokInput:           avgOrZero(tokIn),
+		AvgTokCachedInput:     avgOrZero(tokCached),
+		AvgTokWriteCached:     avgOrZero(tokWriteCached),
+		AvgTokOutput:          avgOrZero(tokOut),
+		AvgTokTotal:           avgOrZero(tokTotal),
+		AvgLinesChanged:       avgOrZero(linesChanged),
+		AvgSetupSeconds:       avgOrZero(setupTimes),
+		AvgFirstOutputSeconds: avgOrZero(firstOutputs),
+		AvgTranscriptBytes:    avgOrZero(transcriptBytes),
+		AvgTranscriptLines:    avgOrZero(transcriptLines),
+		AvgInputCost:          avgOrZero(inputCosts),
+		AvgCachedInputCost:    avgOrZero(cachedInputCosts),
+		AvgOutputCost:         avgOrZero(outputCosts),
+		Errors:                errorCount,
+		TopError:              topError,
+		CostEstimated:         costEstimated,
+		AvgCostNet:            avgOrZero(netCosts),
+		MedianTimeSeconds:     percentileOrZero(times, 0.5),
+		P90TimeSeconds:        percentileOrZero(times, 0.9),
+		NetworkHosts:          hostList,
+		Unverified:            count - verifiedCount,
 	}, true
 }
 
+// mostCommon returns the total of counts and the key with the highest count (the smallest such key on ties).
+func mostCommon(counts map[string]int) (int, string) {
+	total, top := 0, ""
+	for k, n := range counts {
+		total += n
+		if top == "" || n > counts[top] || (n == counts[top] && k < top) {
+			top = k
+		}
+	}
+	return total, top
+}
+
 func partialScore(e resultEntry) float64 {
 	if e.Partial != nil {
 		return *e.Partial
@@ -500,6 +1099,20 @@ func avgOrZero(vals []float64) float64 {
 	return sum / float64(len(vals))
 }
 
+// percentileOrZero returns the p-th (0-1) percentile of vals, interpolating linearly between the closest ranks, or 0 if
+// vals is empty.
+func percentileOrZero(vals []float64, p float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
 func selectLatestVersion(entries []resultEntry) string {
 	var semvers []string
 	var stringsOnly []string
@@ -510,7 +1123,7 @@ func selectLatestVersion(entries []resultEntry) string {
 			continue
 		}
 		seen[v] = true
-		if isSemverLike(v) {
+		if semver.IsValid(v) {
 			semvers = append(semvers, v)
 		} else {
 			stringsOnly = append(stringsOnly, v)
@@ -518,7 +1131,7 @@ func selectLatestVersion(entries []resultEntry) string {
 	}
 	if len(semvers) > 0 {
 		sort.Slice(semvers, func(i, j int) bool {
-			return compareSemver(semvers[i], semvers[j]) < 0
+			return semver.Compare(semvers[i], semvers[j]) < 0
 		})
 		return semvers[len(semvers)-1]
 	}
@@ -558,119 +1171,15 @@ func uniqueVersionsSorted(set map[string]bool) []string {
 	semvers := make([]string, 0, len(set))
 	other := make([]string, 0, len(set))
 	for v := range set {
-		if isSemverLike(v) {
+		if semver.IsValid(v) {
 			semvers = append(semvers, v)
 		} else {
 			other = append(other, v)
 		}
 	}
 	sort.Slice(semvers, func(i, j int) bool {
-		return compareSemver(semvers[i], semvers[j]) < 0
+		return semver.Compare(semvers[i], semvers[j]) < 0
 	})
 	sort.Strings(other)
 	return append(semvers, other...)
 }
-
-type parsedVersion struct {
-	major  int
-	minor  int
-	patch  int
-	pre    string
-	hasPre bool
-}
-
-func isSemverLike(v string) bool {
-	_, ok := parseSemver(v)
-	return ok
-}
-
-func compareSemver(a, b string) int {
-	pa, oka := parseSemver(a)
-	pb, okb := parseSemver(b)
-	if !oka || !okb {
-		return strings.Compare(a, b)
-	}
-	if pa.major != pb.major {
-		return cmpInt(pa.major, pb.major)
-	}
-	if pa.minor != pb.minor {
-		return cmpInt(pa.minor, pb.minor)
-	}
-	if pa.patch != pb.patch {
-		return cmpInt(pa.patch, pb.patch)
-	}
-	if pa.hasPre != pb.hasPre {
-		if pa.hasPre {
-			return -1
-		}
-		return 1
-	}
-	if !pa.hasPre {
-		return 0
-	}
-	return strings.Compare(pa.pre, pb.pre)
-}
-
-func parseSemver(raw string) (parsedVersion, bool) {
-	s := strings.TrimSpace(raw)
-	s = strings.TrimPrefix(s, "v")
-	if s == "" {
-		return parsedVersion{}, false
-	}
-	main := s
-	pre := ""
-	if idx := strings.IndexAny(s, "+-"); idx >= 0 {
-		main = s[:idx]
-		if s[idx] == '-' {
-			rest := s[idx+1:]
-			if plus := strings.Index(rest, "+"); plus >= 0 {
-				pre = rest[:plus]
-			} else {
-				pre = rest
-			}
-		}
-	}
-	parts := strings.Split(main, ".")
-	if len(parts) != 3 {
-		return parsedVersion{}, false
-	}
-	maj, err1 := strconv.Atoi(parts[0])
-	min, err2 := strconv.Atoi(parts[1])
-	pat, err3 := strconv.Atoi(parts[2])
-	if err1 != nil || err2 != nil || err3 != nil {
-		return parsedVersion{}, false
-	}
-	p := parsedVersion{major: maj, minor: min, patch: pat}
-	if strings.TrimSpace(pre) != "" {
-		p.pre = pre
-		p.hasPre = true
-	}
-	return p, true
-}
-
-func cmpInt(a, b int) int {
-	switch {
-	case a < b:
-		return -1
-	case a > b:
-		return 1
-	default:
-		return 0
-	}
-}
-
-func formatFloat(v float64) string {
-	// Compensate for common binary floating-point representation issues so values
-	// like 1.005 reliably round to 1.01 at 2 decimal places.
-	rounded := math.Round((v+math.Copysign(1e-9, v))*100) / 100
-	if rounded == 0 {
-		return "0"
-	}
-	s := strconv.FormatFloat(rounded, 'f', 2, 64)
-	s = strings.TrimRight(s, "0")
-	s = strings.TrimRight(s, ".")
-	if s == "-0" {
-		return "0"
-	}
-	return s
-}
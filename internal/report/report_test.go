@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/codalotl/goagentbench/internal/agents"
+	"github.com/codalotl/goagentbench/internal/results"
 	"github.com/codalotl/goagentbench/internal/types"
+	"github.com/codalotl/goagentbench/internal/verify"
 )
 
 func TestRunAppliesLimitAndDedup(t *testing.T) {
@@ -156,7 +161,7 @@ func TestWriteCSVHeaders(t *testing.T) {
 	records, err := cr.ReadAll()
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(records), 1)
-	require.Equal(t, []string{"agent", "model", "agent_version", "unique_scenarios", "count", "success", "partial_success_score", "success_rate", "partial_success_rate", "avg_cost", "avg_time"}, records[0])
+	require.Equal(t, []string{"agent", "model", "agent_version", "unique_scenarios", "count", "success", "partial_success_score", "success_rate", "partial_success_rate", "avg_cost", "cost_estimated", "avg_time"}, records[0])
 }
 
 func TestWriteCSVRoundsHundredths(t *testing.T) {
@@ -207,13 +212,13 @@ func TestWriteCSVRoundsHundredths(t *testing.T) {
 func TestFormatFloat_TrimsTrailingZeros(t *testing.T) {
 	t.Parallel()
 
-	require.Equal(t, "1", formatFloat(1.0))
-	require.Equal(t, "1.2", formatFloat(1.2))
-	require.Equal(t, "0.5", formatFloat(0.5))
-	require.Equal(t, "0", formatFloat(0.0))
-	require.Equal(t, "0", formatFloat(-0.0))
-	require.Equal(t, "0", formatFloat(-0.004))
-	require.Equal(t, "-1.2", formatFloat(-1.2))
+	require.Equal(t, "1", types.FormatFloat(1.0))
+	require.Equal(t, "1.2", types.FormatFloat(1.2))
+	require.Equal(t, "0.5", types.FormatFloat(0.5))
+	require.Equal(t, "0", types.FormatFloat(0.0))
+	require.Equal(t, "0", types.FormatFloat(-0.0))
+	require.Equal(t, "0", types.FormatFloat(-0.004))
+	require.Equal(t, "-1.2", types.FormatFloat(-1.2))
 }
 
 func TestRunSkipsSmokeScenario(t *testing.T) {
@@ -297,3 +302,1333 @@ func writeReportFile(t *testing.T, dir, name string, rep types.VerificationRepor
 	require.NoError(t, err)
 	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
 }
+
+func TestRunSummaryRowAggregatesAllRows(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+
+	write := func(sc, agent, runID string, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     sc,
+			Agent:        agent,
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      success,
+		})
+	}
+	write("s1", "agent-a", "run_1", true)
+	write("s2", "agent-a", "run_2", false)
+	write("s1", "agent-b", "run_3", true)
+	write("s3", "agent-b", "run_4", true)
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, Summary: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 2)
+	require.NotNil(t, rep.Summary)
+	require.Equal(t, SummaryAgent, rep.Summary.Agent)
+	require.Equal(t, 4, rep.Summary.Count)
+	require.Equal(t, 3, rep.Summary.UniqueScenarios)
+	require.Equal(t, 3, rep.Summary.Success)
+	require.InDelta(t, 0.75, rep.Summary.SuccessRate, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	last := records[len(records)-1]
+	require.Equal(t, []string{"ALL", "", "", "3", "4", "3", "3", "0.75", "0.75", "0", "false", "0"}, last)
+
+	noSummary, err := Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Nil(t, noSummary.Summary)
+}
+
+func TestRunByPlatformGroupsRows(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+
+	write := func(sc, runID string, system *types.SystemInfo, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     sc,
+			Agent:        "agent-a",
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      success,
+			System:       system,
+		})
+	}
+	linux := &types.SystemInfo{OS: "linux", Arch: "amd64", GoVersion: "go1.24.4"}
+	darwin := &types.SystemInfo{OS: "darwin", Arch: "arm64", GoVersion: "go1.24.4"}
+	write("s1", "run_1", linux, true)
+	write("s2", "run_2", linux, true)
+	write("s3", "run_3", darwin, false)
+	write("s4", "run_4", nil, true)
+
+	combined, err := Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, combined.Rows, 1)
+	require.Equal(t, 4, combined.Rows[0].Count)
+	require.Empty(t, combined.Rows[0].Platform)
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, ByPlatform: true, Explain: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 3)
+	require.Equal(t, "linux/amd64", rep.Rows[0].Platform)
+	require.Equal(t, 2, rep.Rows[0].Count)
+	require.Equal(t, PlatformUnknown, rep.Rows[1].Platform)
+	require.Equal(t, 1, rep.Rows[1].Count)
+	require.Equal(t, "darwin/arm64", rep.Rows[2].Platform)
+	require.Equal(t, 0, rep.Rows[2].Success)
+	require.Len(t, rep.Explanations, 3)
+	require.Equal(t, "darwin/arm64", rep.Explanations[2].Platform)
+	require.Equal(t, []string{"run_3"}, rep.Explanations[2].RunIDs)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"agent", "model", "agent_version", "platform", "unique_scenarios"}, records[0][:5])
+	require.Equal(t, []string{"agent-a", "m", "0.1.0", "linux/amd64", "2"}, records[1][:5])
+}
+
+func TestRunNetCostSubtractsOverhead(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	write := func(runID string, usage types.TokenUsage, overhead *types.TokenUsage) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     "demo-" + runID,
+			Agent:        "agent-a",
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      true,
+			Progress:     &types.RunProgress{TokenUsage: usage, OverheadTokenUsage: overhead},
+		})
+	}
+	write("run_1", types.TokenUsage{Cost: 1.0}, &types.TokenUsage{Cost: 0.2})
+	// Overhead without a reported cost is priced like the run's tokens.
+	write("run_2", types.TokenUsage{Cost: 0.5}, &types.TokenUsage{Input: 100})
+	// Runs without a measured overhead don't count toward avg_cost_net.
+	write("run_3", types.TokenUsage{Cost: 3.0}, nil)
+
+	rep, err := Run(Options{
+		RootPath:       root,
+		Limit:          10,
+		IncludeNetCost: true,
+		Pricing:        map[string]agents.Pricing{"m": {InputPerToken: 0.001}},
+	})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.InDelta(t, 1.5, rep.Rows[0].AvgCost, 1e-9)
+	require.InDelta(t, (0.8+0.4)/2, rep.Rows[0].AvgCostNet, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "avg_cost_net", records[0][len(records[0])-1])
+	require.Equal(t, "0.6", records[1][len(records[1])-1])
+}
+
+func TestRunUnverifiedResults(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	for i, unverified := range []bool{false, true, true} {
+		runID := fmt.Sprintf("run_%d", i)
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     "demo",
+			Agent:        "agent-a",
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      !unverified,
+			Unverified:   unverified,
+			Progress:     &types.RunProgress{DurationSeconds: float64(10 * (i + 1)), TokenUsage: types.TokenUsage{Cost: float64(i + 1)}},
+		})
+	}
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, Flakiness: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	row := rep.Rows[0]
+	require.Equal(t, 3, row.Count)
+	require.Equal(t, 1, row.Success)
+	// Only the verified result has an outcome; every result counts toward cost and time.
+	require.Equal(t, 1.0, row.SuccessRate)
+	require.Equal(t, 1.0, row.PartialSuccessRate)
+	require.InDelta(t, 2, row.AvgCost, 1e-9)
+	require.InDelta(t, 20, row.AvgTimeSeconds, 1e-9)
+	// Unverified results aren't failures, so the scenario isn't flaky.
+	require.Empty(t, rep.Flaky)
+}
+
+func TestRunUnverifiedResultsDontDisplaceVerified(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	write := func(sc, runID string, age time.Duration, unverified bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: sc, Agent: "agent-a", AgentVersion: "0.1.0", Model: "m",
+			VerifiedAt: now.Add(-age), Success: !unverified, Unverified: unverified,
+		})
+	}
+	// With the default --limit of 1, the newer --no-verify run doesn't hide the verified one.
+	write("demo", "run_1", 2*time.Hour, false)
+	write("demo", "run_2", time.Hour, true)
+	write("demo", "run_3", 3*time.Hour, true)
+
+	rep, err := Run(Options{RootPath: root})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.Equal(t, 2, rep.Rows[0].Count)
+	require.Equal(t, 1, rep.Rows[0].Success)
+	require.Equal(t, 1, rep.Rows[0].Unverified)
+	require.Equal(t, 1.0, rep.Rows[0].SuccessRate)
+
+	// A row of only unverified results has no success rates.
+	write("other", "run_4", time.Hour, true)
+	rep, err = Run(Options{RootPath: root, Scenarios: []string{"other"}})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"n/a", "n/a"}, records[1][7:9])
+	buf.Reset()
+	require.NoError(t, rep.WriteJSON(&buf))
+	require.Contains(t, buf.String(), `"success_rate": null`)
+}
+
+func TestRunLatencyPercentiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	for i, seconds := range []float64{40, 10, 0, 30, 20} {
+		runID := fmt.Sprintf("run_%d", i)
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     "demo-" + runID,
+			Agent:        "agent-a",
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      true,
+			Progress:     &types.RunProgress{DurationSeconds: seconds},
+		})
+	}
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeLatency: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	// The 0s result is missing a duration, so it's excluded like it is from the average.
+	require.InDelta(t, 25, rep.Rows[0].AvgTimeSeconds, 1e-9)
+	require.InDelta(t, 25, rep.Rows[0].MedianTimeSeconds, 1e-9)
+	require.InDelta(t, 37, rep.Rows[0].P90TimeSeconds, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	n := len(records[0])
+	require.Equal(t, []string{"median_time", "p90_time"}, records[0][n-2:])
+	require.Equal(t, []string{"25", "37"}, records[1][n-2:])
+
+	rep.IncludeLatency = false
+	buf.Reset()
+	require.NoError(t, rep.WriteCSV(&buf))
+	require.NotContains(t, buf.String(), "median_time")
+}
+
+func TestPercentileOrZero(t *testing.T) {
+	t.Parallel()
+	require.Zero(t, percentileOrZero(nil, 0.5))
+	require.Equal(t, 7.0, percentileOrZero([]float64{7}, 0.9))
+	require.InDelta(t, 2.5, percentileOrZero([]float64{4, 1, 3, 2}, 0.5), 1e-9)
+	require.InDelta(t, 3.7, percentileOrZero([]float64{4, 1, 3, 2}, 0.9), 1e-9)
+	require.Equal(t, 4.0, percentileOrZero([]float64{4, 1, 3, 2}, 1))
+}
+
+func TestRunSeparatesReasoningOverrides(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+
+	writeReportFile(t, dir, "a.verify.json", types.VerificationReport{
+		RunID:        "run_a",
+		Scenario:     "demo",
+		Agent:        "codex",
+		AgentVersion: "0.1.0",
+		Model:        "gpt",
+		VerifiedAt:   now,
+		Success:      true,
+		Progress:     &types.RunProgress{ReasoningLevel: "high"},
+	})
+	writeReportFile(t, dir, "b.verify.json", types.VerificationReport{
+		RunID:        "run_b",
+		Scenario:     "demo",
+		Agent:        "codex",
+		AgentVersion: "0.1.0",
+		Model:        "gpt",
+		VerifiedAt:   now,
+		Success:      false,
+		Progress:     &types.RunProgress{ReasoningLevel: "low", ReasoningOverride: true},
+	})
+
+	rep, err := Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 2)
+	require.Equal(t, "gpt", rep.Rows[0].Model)
+	require.Equal(t, "gpt@low", rep.Rows[1].Model)
+
+	rep, err = Run(Options{RootPath: root, Limit: 10, Models: []string{"gpt"}})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 2)
+}
+
+func TestRunAppliesDisplayNamesOnlyToRendering(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+
+	write := func(runID, model string, progress *types.RunProgress) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: model,
+			VerifiedAt: now, Success: runID == "run_a", Progress: progress,
+		})
+	}
+	write("run_a", "gpt-5.1-codex-high", nil)
+	write("run_b", "gpt-5.1-codex-high", &types.RunProgress{ReasoningLevel: "low", ReasoningOverride: true})
+	write("run_c", "gpt-5.1", nil)
+
+	rep, err := Run(Options{
+		RootPath:     root,
+		Limit:        10,
+		DisplayNames: map[string]string{"gpt-5.1-codex-high": "GPT-5.1 Codex", "gpt-5.1": "GPT-5.1 Codex"},
+	})
+	require.NoError(t, err)
+	// Grouping still uses the canonical names, even when two share a display name.
+	require.Len(t, rep.Rows, 3)
+	require.Equal(t, "gpt-5.1-codex-high", rep.Rows[0].Model)
+	require.Equal(t, "GPT-5.1 Codex", rep.Rows[0].ModelLabel())
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	var models []string
+	for _, rec := range records[1:] {
+		models = append(models, rec[1])
+	}
+	require.Equal(t, []string{"GPT-5.1 Codex", "GPT-5.1 Codex", "GPT-5.1 Codex@low"}, models)
+
+	rep, err = Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, "gpt-5.1-codex-high", rep.Rows[0].ModelLabel())
+}
+
+func TestRunFiltersRowsBySuccessRate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+
+	write := func(sc, agent, runID string, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID:        runID,
+			Scenario:     sc,
+			Agent:        agent,
+			AgentVersion: "0.1.0",
+			Model:        "m",
+			VerifiedAt:   now,
+			Success:      success,
+		})
+	}
+	write("s1", "perfect", "run_1", true)
+	write("s2", "perfect", "run_2", true)
+	write("s1", "half", "run_3", true)
+	write("s2", "half", "run_4", false)
+	write("s1", "zero", "run_5", false)
+
+	agents := func(rows []Row) []string {
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.Agent)
+		}
+		return out
+	}
+	rate := func(v float64) *float64 { return &v }
+
+	rep, err := Run(Options{RootPath: root, MaxSuccessRate: rate(0.99)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"half", "zero"}, agents(rep.Rows))
+
+	rep, err = Run(Options{RootPath: root, MinSuccessRate: rate(1)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"perfect"}, agents(rep.Rows))
+
+	rep, err = Run(Options{RootPath: root, MinSuccessRate: rate(0.5), MaxSuccessRate: rate(0.5), Summary: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"half"}, agents(rep.Rows))
+	require.Equal(t, 5, rep.Summary.Count)
+
+	_, err = Run(Options{RootPath: root, MinSuccessRate: rate(0.8), MaxSuccessRate: rate(0.2)})
+	require.Error(t, err)
+	_, err = Run(Options{RootPath: root, MaxSuccessRate: rate(1.5)})
+	require.Error(t, err)
+}
+
+func TestWriteCSVIncludesAvgLinesChanged(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	ints := func(v int) *int { return &v }
+
+	writeReportFile(t, dir, "a.verify.json", types.VerificationReport{
+		RunID: "run_a", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now, Success: true, LinesAdded: ints(10), LinesDeleted: ints(2),
+	})
+	writeReportFile(t, dir, "b.verify.json", types.VerificationReport{
+		RunID: "run_b", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now.Add(-time.Hour), Success: true, LinesAdded: ints(0), LinesDeleted: ints(0),
+	})
+	writeReportFile(t, dir, "c.verify.json", types.VerificationReport{
+		RunID: "run_c", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now.Add(-2 * time.Hour), Success: true, // predates diff measurement
+	})
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeLinesChanged: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.InDelta(t, 6, rep.Rows[0].AvgLinesChanged, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "avg_lines_changed", records[0][len(records[0])-1])
+	require.Equal(t, "6", records[1][len(records[1])-1])
+}
+
+func TestWriteCSVIncludesAvgSetupTime(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	floats := func(v float64) *float64 { return &v }
+
+	writeReportFile(t, dir, "a.verify.json", types.VerificationReport{
+		RunID: "run_a", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now, Success: true, SetupSeconds: floats(12),
+	})
+	writeReportFile(t, dir, "b.verify.json", types.VerificationReport{
+		RunID: "run_b", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now.Add(-time.Hour), Success: true, SetupSeconds: floats(3),
+	})
+	writeReportFile(t, dir, "c.verify.json", types.VerificationReport{
+		RunID: "run_c", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+		VerifiedAt: now.Add(-2 * time.Hour), Success: true, // no .setup-meta.json
+	})
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeSetupTime: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.InDelta(t, 7.5, rep.Rows[0].AvgSetupSeconds, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "avg_setup_time", records[0][len(records[0])-1])
+	require.Equal(t, "7.5", records[1][len(records[1])-1])
+}
+
+func TestRunEstimatesMissingCostFromPricing(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+
+	write := func(runID, agent string, at time.Time, usage types.TokenUsage) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: agent, AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: at, Success: true, Progress: &types.RunProgress{TokenUsage: usage},
+		})
+	}
+	// Reported cost is kept; a zero cost with tokens is estimated; a zero cost without tokens stays missing.
+	write("run_reported", "reported", now, types.TokenUsage{Input: 1000, Output: 1000, Cost: 5})
+	write("run_est1", "estimated", now, types.TokenUsage{Input: 1_000_000, CachedInput: 1_000_000, Output: 1_000_000})
+	write("run_est2", "estimated", now.Add(-time.Hour), types.TokenUsage{})
+
+	pricing := map[string]agents.Pricing{
+		"gpt": {InputPerToken: 1.0 / 1_000_000, CachedInputPerToken: 0.5 / 1_000_000, OutputPerToken: 2.0 / 1_000_000},
+	}
+	rep, err := Run(Options{RootPath: root, Limit: 10, Pricing: pricing})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 2)
+	byAgent := map[string]Row{}
+	for _, r := range rep.Rows {
+		byAgent[r.Agent] = r
+	}
+	require.InDelta(t, 5, byAgent["reported"].AvgCost, 1e-9)
+	require.False(t, byAgent["reported"].CostEstimated)
+	require.InDelta(t, 3.5, byAgent["estimated"].AvgCost, 1e-9)
+	require.True(t, byAgent["estimated"].CostEstimated)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	require.Contains(t, buf.String(), "estimated,gpt,0.1.0,1,2,2,2,1,1,3.5,true,0\n")
+
+	rep, err = Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	for _, r := range rep.Rows {
+		require.False(t, r.CostEstimated)
+	}
+}
+
+func TestRunExplainCountsEachStage(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+
+	write := func(file, runID, version string, at time.Time) {
+		t.Helper()
+		writeReportFile(t, dir, file+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: version, Model: "gpt",
+			VerifiedAt: at, Success: true,
+		})
+	}
+	write("a", "run_a", "0.2.0", now)
+	write("a-dup", "run_a", "0.2.0", now.Add(-time.Minute)) // duplicate run_id
+	write("b", "run_b", "0.2.0", now.Add(-time.Hour))       // dropped by --limit=1
+	write("c", "run_c", "0.1.0", now.Add(-2*time.Hour))     // older agent version
+
+	rep, err := Run(Options{RootPath: root, Explain: true})
+	require.NoError(t, err)
+	require.Equal(t, []RowExplanation{{
+		Agent:        "codex",
+		Model:        "gpt",
+		Matched:      4,
+		AfterDedup:   3,
+		AfterVersion: 2,
+		AfterLimit:   1,
+		RunIDs:       []string{"run_a"},
+	}}, rep.Explanations)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteExplain(&buf))
+	require.Equal(t, "codex / gpt: matched=4 after_dedup=3 after_version=2 after_limit=1\n  runs: run_a\n", buf.String())
+
+	rep, err = Run(Options{RootPath: root})
+	require.NoError(t, err)
+	require.Nil(t, rep.Explanations)
+}
+
+func TestRunWithIndexMatchesFullScan(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	indexPath := filepath.Join(root, "cache", "index.json")
+	write := func(sc, runID string, at time.Time, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: sc, Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: at, Success: success, Progress: &types.RunProgress{DurationSeconds: 10},
+		})
+	}
+	write("s1", "run_1", now.Add(-3*time.Hour), true)
+	write("s2", "run_2", now.Add(-2*time.Hour), false)
+	write("smoke", "run_smoke", now, true)
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IndexPath: indexPath})
+	require.NoError(t, err)
+	require.FileExists(t, indexPath)
+	require.Len(t, rep.Rows, 1)
+	require.Equal(t, 2, rep.Rows[0].Count)
+
+	// Add a result and change an existing one; the indexed run must match a fresh full scan.
+	write("s3", "run_3", now.Add(-time.Hour), true)
+	write("s2", "run_2", now.Add(-2*time.Hour), true)
+	indexed, err := Run(Options{RootPath: root, Limit: 10, IndexPath: indexPath})
+	require.NoError(t, err)
+	full, err := Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, full.Rows, indexed.Rows)
+	require.Equal(t, 3, indexed.Rows[0].Count)
+	require.Equal(t, 3, indexed.Rows[0].Success)
+
+	// Unchanged files are served from the index without re-parsing.
+	path := filepath.Join(root, "results", "s1", "run_1.verify.json")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte(" "), len(data)), 0o644))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	indexed, err = Run(Options{RootPath: root, Limit: 10, IndexPath: indexPath})
+	require.NoError(t, err)
+	require.Equal(t, full.Rows, indexed.Rows)
+}
+
+func TestMergeRow(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(v float64) *float64 { return &v }
+	errs, topError := 1, "rate limited"
+	prior := JSONRow{
+		Agent: "codex", Model: "gpt", AgentVersion: "0.1.0", UniqueScenarios: 3, Count: 4, Success: 2,
+		PartialSuccessScore: 2.5, SuccessRate: ptr(0.5), PartialSuccessRate: ptr(0.625), AvgCost: 1, AvgTime: 30,
+		AvgTokTotal: ptr(1000), Errors: &errs, TopError: &topError, NetworkHosts: []string{"github.com"},
+	}
+	cur := Row{
+		Agent: "codex", Model: "gpt", AgentVersion: "0.2.0", UniqueScenarios: 2, Count: 2, Success: 1,
+		PartialScoreSum: 1, AvgCost: 4, AvgTimeSeconds: 60, AvgTokTotal: 4000, AvgLinesChanged: 12,
+		Errors: 0, NetworkHosts: []string{"api.openai.com"},
+	}
+
+	got := mergeRow(prior, cur)
+	require.Equal(t, 6, got.Count)
+	require.Equal(t, 3, got.Success)
+	require.InDelta(t, 0.5, got.SuccessRate, 1e-9)
+	require.InDelta(t, 3.5, got.PartialScoreSum, 1e-9)
+	require.InDelta(t, 3.5/6, got.PartialSuccessRate, 1e-9)
+	// Averages are weighted by count: (4*1 + 2*4) / 6.
+	require.InDelta(t, 2, got.AvgCost, 1e-9)
+	require.InDelta(t, 40, got.AvgTimeSeconds, 1e-9)
+	require.InDelta(t, 2000, got.AvgTokTotal, 1e-9)
+	// The prior report didn't include lines changed, so only the new results count.
+	require.InDelta(t, 12, got.AvgLinesChanged, 1e-9)
+	require.Equal(t, 3, got.UniqueScenarios)
+	require.Equal(t, "0.2.0", got.AgentVersion)
+	require.Equal(t, 1, got.Errors)
+	require.Equal(t, "rate limited", got.TopError)
+	require.Equal(t, []string{"api.openai.com", "github.com"}, got.NetworkHosts)
+
+	// A prior row with no new results is kept as is.
+	only := mergeRow(prior, Row{})
+	require.Equal(t, 4, only.Count)
+	require.InDelta(t, 0.5, only.SuccessRate, 1e-9)
+	require.InDelta(t, 1, only.AvgCost, 1e-9)
+	require.Equal(t, "0.1.0", only.AgentVersion)
+
+	// Unverified results don't count toward the recomputed rates.
+	prior.Unverified = 2
+	got = mergeRow(prior, cur)
+	require.Equal(t, 2, got.Unverified)
+	require.InDelta(t, 3.0/4, got.SuccessRate, 1e-9)
+}
+
+func TestRunSinceRunMergesPriorReport(t *testing.T) {
+	t.Parallel()
+
+	for _, withIndex := range []bool{true, false} {
+		root := t.TempDir()
+		now := time.Now()
+		indexPath := ""
+		if withIndex {
+			indexPath = filepath.Join(root, "cache", "index.json")
+		}
+		write := func(agent, runID string, age time.Duration, success bool, cost float64) {
+			t.Helper()
+			writeReportFile(t, filepath.Join(root, "results", "demo-"+runID), runID+".verify.json", types.VerificationReport{
+				RunID: runID, Scenario: "demo-" + runID, Agent: agent, AgentVersion: "0.1.0", Model: "gpt",
+				VerifiedAt: now.Add(-age), Success: success,
+				Progress: &types.RunProgress{DurationSeconds: 10, TokenUsage: types.TokenUsage{Cost: cost}},
+			})
+		}
+		write("codex", "run_1", 6*time.Hour, true, 1)
+		write("codex", "run_2", 5*time.Hour, false, 1)
+		write("codex", "run_3", 4*time.Hour, true, 1)
+		write("codex", "run_4", 3*time.Hour, false, 1)
+		write("claude", "run_5", 3*time.Hour, true, 2)
+
+		prior, err := Run(Options{RootPath: root, Summary: true, IndexPath: indexPath})
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, prior.WriteJSON(&buf))
+		priorPath := filepath.Join(root, "report.json")
+		require.NoError(t, os.WriteFile(priorPath, buf.Bytes(), 0o644))
+
+		write("codex", "run_6", 2*time.Hour, true, 4)
+		write("codex", "run_7", time.Hour, false, 4)
+		write("cursor", "run_8", time.Hour, true, 3)
+		if withIndex {
+			// Already-reported files aren't read again: an unreadable one doesn't matter.
+			require.NoError(t, os.WriteFile(filepath.Join(root, "results", "demo-run_1", "run_1.verify.json"), []byte("{"), 0o644))
+		}
+
+		rep, err := Run(Options{RootPath: root, Summary: true, IndexPath: indexPath, SinceRunID: "run_5", PriorReportPath: priorPath})
+		require.NoError(t, err)
+		rows := map[string]Row{}
+		for _, row := range rep.Rows {
+			rows[row.Agent] = row
+		}
+		require.Len(t, rows, 3)
+		codex := rows["codex"]
+		require.Equal(t, 6, codex.Count)
+		require.Equal(t, 3, codex.Success)
+		require.InDelta(t, 0.5, codex.SuccessRate, 1e-9)
+		require.InDelta(t, 2, codex.AvgCost, 1e-9) // (4*1 + 2*4) / 6
+		require.Equal(t, 1, rows["claude"].Count)
+		require.InDelta(t, 2, rows["claude"].AvgCost, 1e-9)
+		require.Equal(t, 1, rows["cursor"].Count)
+		require.NotNil(t, rep.Summary)
+		require.Equal(t, SummaryAgent, rep.Summary.Agent)
+		require.Equal(t, 8, rep.Summary.Count)
+		require.Equal(t, 5, rep.Summary.Success)
+	}
+}
+
+func TestRunSinceRunErrors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeReportFile(t, filepath.Join(root, "results", "demo"), "run_1.verify.json", types.VerificationReport{
+		RunID: "run_1", Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt", VerifiedAt: time.Now(),
+	})
+	priorPath := filepath.Join(root, "report.json")
+	require.NoError(t, os.WriteFile(priorPath, []byte(`{"rows": []}`), 0o644))
+
+	_, err := Run(Options{RootPath: root, SinceRunID: "run_1"})
+	require.EqualError(t, err, "since run and prior report must be set together")
+	_, err = Run(Options{RootPath: root, SinceRunID: "run_missing", PriorReportPath: priorPath})
+	require.EqualError(t, err, `since run: no result with run id "run_missing"`)
+	_, err = Run(Options{RootPath: root, SinceRunID: "run_1", PriorReportPath: priorPath, Summary: true})
+	require.ErrorContains(t, err, "has no summary row")
+	_, err = Run(Options{RootPath: root, SinceRunID: "run_1", PriorReportPath: priorPath, IncludeLatency: true})
+	require.ErrorContains(t, err, "cannot be combined with explain, flakiness, or latency columns")
+
+	rep, err := Run(Options{RootPath: root, SinceRunID: "run_1", PriorReportPath: priorPath})
+	require.NoError(t, err)
+	require.Empty(t, rep.Rows)
+}
+
+func TestRunFlakinessFlagsMixedOutcomes(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	n := 0
+	write := func(sc, agent string, outcomes ...bool) {
+		t.Helper()
+		for _, success := range outcomes {
+			n++
+			runID := fmt.Sprintf("run_%d", n)
+			writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+				RunID: runID, Scenario: sc, Agent: agent, AgentVersion: "0.1.0", Model: "m",
+				VerifiedAt: now.Add(-time.Duration(n) * time.Minute), Success: success,
+			})
+		}
+	}
+	write("even", "a", true, false, true, false)
+	write("mostly", "a", true, true, true, false)
+	write("stable", "a", true, true)
+	write("hard", "a", false, false)
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, Flakiness: true})
+	require.NoError(t, err)
+	require.Equal(t, []FlakyCombo{
+		{Scenario: "even", Agent: "a", Model: "m", Runs: 4, Successes: 2, PassRatio: 0.5, Flakiness: 1},
+		{Scenario: "mostly", Agent: "a", Model: "m", Runs: 4, Successes: 3, PassRatio: 0.75, Flakiness: 0.5},
+	}, rep.Flaky)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteFlakinessCSV(&buf))
+	require.Equal(t, "scenario,agent,model,runs,success,pass_ratio,flakiness\neven,a,m,4,2,0.5,1\nmostly,a,m,4,3,0.75,0.5\n", buf.String())
+
+	// With the default --limit=1 there is only one result per combo, so nothing is flaky.
+	rep, err = Run(Options{RootPath: root, Flakiness: true})
+	require.NoError(t, err)
+	require.Empty(t, rep.Flaky)
+}
+
+func TestRunReadsFromStore(t *testing.T) {
+	t.Parallel()
+	store := &results.MemoryStore{}
+	now := time.Now()
+	for i, success := range []bool{true, false} {
+		require.NoError(t, store.Write(&types.VerificationReport{
+			RunID: fmt.Sprintf("run_%d", i), Scenario: "demo", Agent: "a", AgentVersion: "1", Model: "m",
+			VerifiedAt: now.Add(-time.Duration(i) * time.Minute), Success: success,
+		}))
+	}
+	require.NoError(t, store.Write(&types.VerificationReport{RunID: "smoke", Scenario: "smoke", Agent: "a", Model: "m", VerifiedAt: now}))
+
+	// RootPath has no results dir; everything comes from the store.
+	rep, err := Run(Options{RootPath: t.TempDir(), Limit: 5, Store: store})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.Equal(t, 2, rep.Rows[0].Count)
+	require.Equal(t, 1, rep.Rows[0].Success)
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+	rep := &Report{
+		Rows: []Row{
+			{Agent: "codex", Model: "gpt-5<x>", AgentVersion: "1.0.0", UniqueScenarios: 2, Count: 4, Success: 3, SuccessRate: 0.75, AvgCost: 0.5},
+		},
+		Summary: &Row{Agent: SummaryAgent, Count: 4, Success: 3, SuccessRate: 0.75},
+		Filters: []string{"agents=codex", "limit=1"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteHTML(&buf, time.Date(2025, 12, 3, 10, 0, 0, 0, time.UTC)))
+	out := buf.String()
+	require.Contains(t, out, "<th>success_rate</th>")
+	require.Contains(t, out, "<td>codex</td><td>gpt-5&lt;x&gt;</td><td>1.0.0</td>")
+	require.Contains(t, out, "<td>0.75</td>")
+	require.Contains(t, out, "<tfoot>\n<tr><td>ALL</td>")
+	require.Contains(t, out, "Generated 2025-12-03T10:00:00Z")
+	require.Contains(t, out, "<code>agents=codex limit=1</code>")
+	require.Contains(t, out, "<script>")
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+	rep := &Report{
+		IncludeTokens: true,
+		Rows: []Row{
+			{Agent: "codex", Model: "gpt-5", ModelDisplay: "GPT-5", AgentVersion: "1.0.0", UniqueScenarios: 2, Count: 4, Success: 3, PartialScoreSum: 3.5, SuccessRate: 0.75, PartialSuccessRate: 0.875, AvgCost: 0.5, CostEstimated: true, AvgTimeSeconds: 12, AvgTokInput: 100, AvgTokTotal: 150, AvgTokOutput: 50},
+			{Agent: "claude", Model: "sonnet", AgentVersion: "2.0.0", UniqueScenarios: 1, Count: 1},
+		},
+		Summary:     &Row{Agent: SummaryAgent, UniqueScenarios: 2, Count: 5, Success: 3, SuccessRate: 0.6},
+		Filters:     []string{"limit=1"},
+		GeneratedAt: time.Date(2025, 12, 3, 10, 0, 0, 0, time.UTC),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteJSON(&buf))
+	require.Equal(t, `{
+  "generated_at": "2025-12-03T10:00:00Z",
+  "filters": [
+    "limit=1"
+  ],
+  "rows": [
+    {
+      "agent": "codex",
+      "model": "gpt-5",
+      "model_display": "GPT-5",
+      "agent_version": "1.0.0",
+      "unique_scenarios": 2,
+      "count": 4,
+      "success": 3,
+      "partial_success_score": 3.5,
+      "success_rate": 0.75,
+      "partial_success_rate": 0.875,
+      "avg_cost": 0.5,
+      "cost_estimated": true,
+      "avg_time": 12,
+      "avg_tok_input": 100,
+      "avg_tok_cached_input": 0,
+      "avg_tok_write_cached_input": 0,
+      "avg_tok_output": 50,
+      "avg_tok_total": 150
+    },
+    {
+      "agent": "claude",
+      "model": "sonnet",
+      "agent_version": "2.0.0",
+      "unique_scenarios": 1,
+      "count": 1,
+      "success": 0,
+      "partial_success_score": 0,
+      "success_rate": 0,
+      "partial_success_rate": 0,
+      "avg_cost": 0,
+      "avg_time": 0,
+      "avg_tok_input": 0,
+      "avg_tok_cached_input": 0,
+      "avg_tok_write_cached_input": 0,
+      "avg_tok_output": 0,
+      "avg_tok_total": 0
+    }
+  ],
+  "summary": {
+    "agent": "ALL",
+    "model": "",
+    "agent_version": "",
+    "unique_scenarios": 2,
+    "count": 5,
+    "success": 3,
+    "partial_success_score": 0,
+    "success_rate": 0.6,
+    "partial_success_rate": 0,
+    "avg_cost": 0,
+    "avg_time": 0,
+    "avg_tok_input": 0,
+    "avg_tok_cached_input": 0,
+    "avg_tok_write_cached_input": 0,
+    "avg_tok_output": 0,
+    "avg_tok_total": 0
+  }
+}
+`, buf.String())
+
+	// Without IncludeTokens the token fields are dropped.
+	rep.IncludeTokens = false
+	buf.Reset()
+	require.NoError(t, rep.WriteJSON(&buf))
+	require.NotContains(t, buf.String(), "avg_tok_")
+}
+
+func TestRunWriteJSONMatchesCSVOrder(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	write := func(agent, runID string, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", "demo"), runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: agent, AgentVersion: "0.1.0", Model: "m",
+			VerifiedAt: now, Success: success,
+		})
+	}
+	write("a", "run_1", false)
+	write("b", "run_2", true)
+	write("c", "run_3", true)
+
+	rep, err := Run(Options{RootPath: root})
+	require.NoError(t, err)
+	var csvBuf, jsonBuf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&csvBuf))
+	require.NoError(t, rep.WriteJSON(&jsonBuf))
+	records, err := csv.NewReader(&csvBuf).ReadAll()
+	require.NoError(t, err)
+	var got JSONReport
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &got))
+	require.Len(t, got.Rows, len(records)-1)
+	for i, row := range got.Rows {
+		require.Equal(t, records[i+1][0], row.Agent)
+	}
+	require.False(t, got.GeneratedAt.IsZero())
+}
+
+func TestDiffSummaries(t *testing.T) {
+	before := t.TempDir()
+	after := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(before, "report.csv"), []byte(
+		"agent,model,agent_version,unique_scenarios,count,success,partial_success_score,success_rate,partial_success_rate,avg_cost,avg_time\n"+
+			"codex,gpt-5,1.0,4,4,2,2,0.5,0.5,0.4,120\n"+
+			"claude,sonnet,2.0,4,4,3,3,0.75,0.75,1.2*,90\n"+
+			"crush,old,0.1,4,4,1,1,0.25,0.25,0.1,30\n"), 0o644))
+	// A different column set and order still loads. Summaries from before cost_estimated marked estimates with "*".
+	require.NoError(t, os.WriteFile(filepath.Join(after, "report.csv"), []byte(
+		"agent,model,success_rate,avg_time,avg_cost,cost_estimated,avg_tok_total\n"+
+			"codex,gpt-5,0.75,100,0.5,false,1000\n"+
+			"claude,sonnet,0.5,90,1.2,true,2000\n"+
+			"cursor,new,1,10,0,false,10\n"), 0o644))
+
+	beforeRows, err := LoadSummary(before)
+	require.NoError(t, err)
+	require.Equal(t, SummaryRow{Agent: "claude", Model: "sonnet", SuccessRate: 0.75, AvgCost: 1.2, AvgTimeSeconds: 90, CostEstimated: true}, beforeRows[1])
+	afterRows, err := LoadSummary(after)
+	require.NoError(t, err)
+	require.False(t, afterRows[0].CostEstimated)
+	require.True(t, afterRows[1].CostEstimated)
+
+	rows := DiffSummaries(beforeRows, afterRows)
+	require.Len(t, rows, 4)
+	var changes []string
+	for _, d := range rows {
+		changes = append(changes, d.Agent+":"+d.Change())
+	}
+	require.Equal(t, []string{"codex:improved", "claude:regressed", "cursor:new", "crush:removed"}, changes)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDiff(&buf, rows))
+	out := buf.String()
+	require.Contains(t, out, "50% -> 75% (+25pp)")
+	require.Contains(t, out, "$0.4 -> $0.5 (+$0.1)")
+	require.Contains(t, out, "120s -> 100s (-20s)")
+	require.Contains(t, out, "$1.2 -> $1.2 (0)")
+
+	_, err = LoadSummary(t.TempDir())
+	require.Error(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(after, "report.csv"), []byte("agent,model\ncodex,gpt-5\n"), 0o644))
+	_, err = LoadSummary(after)
+	require.ErrorContains(t, err, "missing success_rate column")
+}
+
+func TestReportCurrency(t *testing.T) {
+	t.Parallel()
+	rep := &Report{
+		CostBreakdown: true,
+		Rows: []Row{
+			{Agent: "codex", Model: "gpt-5", AgentVersion: "1.0.0", Count: 1, AvgCost: 2, CostEstimated: true, AvgInputCost: 1.5, AvgCachedInputCost: 0.1, AvgOutputCost: 0.4},
+		},
+		Currency: Currency{Code: "eur", PerUSD: 0.5},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, "codex,gpt-5,1.0.0,0,1,0,0,0,0,€1,true,0,€0.75,€0.05,€0.2", lines[1])
+
+	// The stored Row cost stays USD; JSON converts and names the currency.
+	require.Equal(t, 2.0, rep.Rows[0].AvgCost)
+	buf.Reset()
+	require.NoError(t, rep.WriteJSON(&buf))
+	require.Contains(t, buf.String(), `"avg_cost": 1,`)
+	require.Contains(t, buf.String(), `"currency": "EUR"`)
+
+	// A converted summary still loads for report diff.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.csv"), []byte(lines[0]+"\n"+lines[1]+"\n"), 0o644))
+	rows, err := LoadSummary(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, rows[0].AvgCost)
+	require.True(t, rows[0].CostEstimated)
+
+	require.Equal(t, "CHF ", Currency{Code: "CHF", PerUSD: 0.9}.Symbol())
+	require.Equal(t, 3.0, Currency{}.Convert(3))
+	_, err = Run(Options{RootPath: t.TempDir(), Currency: Currency{Code: "EUR"}})
+	require.ErrorContains(t, err, "currency EUR needs a positive rate")
+}
+
+func TestWriteCSVIncludesAvgFirstOutput(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	for i, first := range []float64{4, 2, 0} {
+		writeReportFile(t, dir, fmt.Sprintf("%d.verify.json", i), types.VerificationReport{
+			RunID: fmt.Sprintf("run_%d", i), Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now.Add(-time.Duration(i) * time.Hour), Success: true,
+			Progress: &types.RunProgress{FirstOutputSeconds: first},
+		})
+	}
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeFirstOutput: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	// The result without a measurement (0) is left out of the average.
+	require.InDelta(t, 3, rep.Rows[0].AvgFirstOutputSeconds, 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "avg_first_output", records[0][len(records[0])-1])
+	require.Equal(t, "3", records[1][len(records[1])-1])
+}
+
+func TestRunCostBreakdown(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	write := func(runID, model string, at time.Time, usage types.TokenUsage) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: model,
+			VerifiedAt: at, Success: true, Progress: &types.RunProgress{TokenUsage: usage},
+		})
+	}
+	write("run_1", "gpt", now, types.TokenUsage{Input: 1_000_000, CachedInput: 4_000_000, Output: 500_000, Cost: 9})
+	write("run_2", "gpt", now.Add(-time.Hour), types.TokenUsage{Input: 3_000_000, Output: 1_500_000})
+	write("run_3", "unpriced", now, types.TokenUsage{Input: 1_000_000})
+
+	pricing := map[string]agents.Pricing{
+		"gpt": {InputPerToken: 1.0 / 1_000_000, CachedInputPerToken: 0.5 / 1_000_000, OutputPerToken: 2.0 / 1_000_000},
+	}
+	rep, err := Run(Options{RootPath: root, Limit: 10, Pricing: pricing, CostBreakdown: true})
+	require.NoError(t, err)
+	byModel := map[string]Row{}
+	for _, r := range rep.Rows {
+		byModel[r.Model] = r
+	}
+	gpt := byModel["gpt"]
+	require.InDelta(t, 2, gpt.AvgInputCost, 1e-9)
+	require.InDelta(t, 1, gpt.AvgCachedInputCost, 1e-9)
+	require.InDelta(t, 2, gpt.AvgOutputCost, 1e-9)
+	require.Zero(t, byModel["unpriced"].AvgInputCost)
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	header := records[0]
+	require.Equal(t, []string{"avg_input_cost", "avg_cached_input_cost", "avg_output_cost"}, header[len(header)-3:])
+	for _, rec := range records[1:] {
+		if rec[1] == "gpt" {
+			require.Equal(t, []string{"2", "1", "2"}, rec[len(rec)-3:])
+		}
+	}
+}
+
+func TestRunMergesResultsDirs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	write := func(dir, runID string, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(dir, "demo"), runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now, Success: success,
+		})
+	}
+	machineA := filepath.Join(root, "machine-a")
+	machineB := t.TempDir()
+	write(machineA, "run_a", true)
+	write(machineA, "run_shared", true)
+	write(machineB, "run_b", false)
+	write(machineB, "run_shared", true) // the same run copied to both machines
+	write(filepath.Join(root, "results"), "run_default", true)
+
+	indexPath := filepath.Join(root, "index.json")
+	for range 2 { // the second run reads from the index
+		rep, err := Run(Options{RootPath: root, Limit: 10, ResultsDirs: []string{"machine-a", machineB}, IndexPath: indexPath})
+		require.NoError(t, err)
+		require.Len(t, rep.Rows, 1)
+		require.Equal(t, 3, rep.Rows[0].Count)
+		require.Equal(t, 2, rep.Rows[0].Success)
+	}
+
+	rep, err := Run(Options{RootPath: root, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.Equal(t, 1, rep.Rows[0].Count)
+}
+
+func TestWriteCSVIncludesErrors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	write := func(runID, agent, notes string) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: agent, AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now, Progress: &types.RunProgress{Notes: notes},
+		})
+	}
+	write("run_1", "codex", "401 Unauthorized:\n  invalid api key")
+	write("run_2", "codex", "401 Unauthorized: invalid api key")
+	write("run_3", "codex", "signal: killed")
+	write("run_4", "codex", "")
+	write("run_5", "claude", "")
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeErrors: true})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	header := records[0]
+	require.Equal(t, []string{"errors", "top_error"}, header[len(header)-2:])
+	require.Equal(t, "claude", records[1][0])
+	require.Equal(t, []string{"0", ""}, records[1][len(header)-2:])
+	require.Equal(t, "codex", records[2][0])
+	require.Equal(t, []string{"3", "401 Unauthorized: invalid api key"}, records[2][len(header)-2:])
+}
+
+func TestWriteCSVIncludesNetworkHosts(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	write := func(runID string, hosts ...string) {
+		t.Helper()
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now, Progress: &types.RunProgress{NetworkHosts: hosts},
+		})
+	}
+	write("run_1", "api.openai.com", "proxy.golang.org")
+	write("run_2", "api.openai.com", "github.com")
+	write("run_3")
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeNetwork: true})
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	require.Equal(t, []string{"api.openai.com", "github.com", "proxy.golang.org"}, rep.Rows[0].NetworkHosts)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "network_hosts", records[0][len(records[0])-1])
+	require.Equal(t, "api.openai.com github.com proxy.golang.org", records[1][len(records[1])-1])
+}
+
+func TestWriteNDJSONWritesSelectedResults(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	write := func(sc, agent, runID string, age time.Duration, success bool) {
+		t.Helper()
+		writeReportFile(t, filepath.Join(root, "results", sc), runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: sc, Agent: agent, AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now.Add(-age), Success: success,
+			Progress: &types.RunProgress{
+				DurationSeconds: 10, TokenUsage: types.TokenUsage{Total: 100, Cost: 0.5}, NetworkHosts: []string{"github.com"},
+			},
+		})
+	}
+	write("a", "codex", "run_1", 3*time.Hour, false)
+	write("a", "codex", "run_2", 2*time.Hour, true)
+	write("a", "codex", "run_3", time.Hour, true)
+	write("b", "codex", "run_4", time.Hour, true)
+	write("a", "claude", "run_5", time.Hour, true) // filtered out by --agents
+
+	rep, err := Run(Options{RootPath: root, Agents: []string{"codex"}, Limit: 2})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteNDJSON(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3) // scenario a keeps its 2 newest runs; b has 1
+	var runIDs []string
+	for _, line := range lines {
+		var got ResultLine
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		require.Equal(t, "codex", got.Agent)
+		require.Equal(t, 0.5, got.TokenUsage.Cost)
+		require.Equal(t, []string{"github.com"}, got.NetworkHosts)
+		runIDs = append(runIDs, got.RunID)
+	}
+	require.Equal(t, []string{"run_2", "run_3", "run_4"}, runIDs)
+}
+
+func TestWriteCSVIncludesTranscriptSize(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now()
+	dir := filepath.Join(root, "results", "demo")
+	for i, size := range []int{1000, 3000, 0} { // the last result predates transcript sizes
+		runID := fmt.Sprintf("run_%d", i)
+		writeReportFile(t, dir, runID+".verify.json", types.VerificationReport{
+			RunID: runID, Scenario: "demo", Agent: "codex", AgentVersion: "0.1.0", Model: "gpt",
+			VerifiedAt: now.Add(time.Duration(i) * time.Minute), Success: true,
+			Progress: &types.RunProgress{TranscriptBytes: size, TranscriptLines: size / 100},
+		})
+	}
+
+	rep, err := Run(Options{RootPath: root, Limit: 10, IncludeTranscriptSize: true})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	header := records[0]
+	require.Equal(t, []string{"avg_transcript_bytes", "avg_transcript_lines"}, header[len(header)-2:])
+	require.Equal(t, []string{"2000", "20"}, records[1][len(header)-2:])
+}
+
+func TestMatchingFilesChecksFiltersAgainstPaths(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	touch := func(rel string) {
+		t.Helper()
+		path := filepath.Join(root, "results", filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		// Not valid JSON: MatchingFiles doesn't parse result files.
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	}
+	touch("sc1/2025-12-20-run_1-codex-gpt-5.2-high.verify.json")
+	touch("sc1/2025-12-20-run_2-cursor-agent-gpt-5.2-high.verify.json")
+	touch("sc1/2025-12-01-run_3-codex-gpt-5.2-high.verify.json")
+	touch("sc1/2025-12-20-run_4-codex-gpt-5.1.verify.json")
+	touch("sc1/legacy.verify.json")
+	touch("sc2/2025-12-20-run_5-codex-gpt-5.2-high.verify.json")
+	touch("sc1/nested/2025-12-20-run_7-codex-gpt-5.2-high.verify.json")
+	touch("smoke/2025-12-20-run_6-codex-gpt-5.2-high.verify.json")
+
+	after := time.Date(2025, 12, 15, 0, 0, 0, 0, time.Local)
+	files, err := MatchingFiles(Options{
+		RootPath:  root,
+		Scenarios: []string{"sc1"},
+		Agents:    []string{"codex"},
+		Models:    []string{"gpt-5.2-high"},
+		After:     &after,
+	})
+	require.NoError(t, err)
+	var rels []string
+	for _, f := range files {
+		rel, err := filepath.Rel(filepath.Join(root, "results"), f)
+		require.NoError(t, err)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	require.ElementsMatch(t, []string{
+		"sc1/2025-12-20-run_1-codex-gpt-5.2-high.verify.json",
+		"sc1/legacy.verify.json",
+	}, rels)
+
+	all, err := MatchingFiles(Options{RootPath: root})
+	require.NoError(t, err)
+	require.Len(t, all, 7)
+}
+
+func TestPartialScoreDisplayMatchesVerifySummary(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	score := 2.0 / 3.0
+	result := types.VerificationReport{
+		RunID:        "run_1",
+		Scenario:     "demo",
+		Agent:        "codex",
+		AgentVersion: "0.1.0",
+		Model:        "gpt",
+		VerifiedAt:   time.Now(),
+		PartialScore: &score,
+	}
+	writeReportFile(t, filepath.Join(root, "results", "demo"), "run_1.verify.json", result)
+
+	rep, err := Run(Options{RootPath: root})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteCSV(&buf))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "0.67", records[1][6]) // partial_success_score
+	require.Equal(t, "0.67", records[1][8]) // partial_success_rate
+	require.Contains(t, verify.SummaryString(&result), "Partial success: 0.67\n")
+}
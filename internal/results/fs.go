@@ -0,0 +1,136 @@
+package results
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// FSStore stores reports as <Dir>/<scenario>/<date>-<run_id>-<agent>-<model>.verify.json files.
+type FSStore struct {
+	Dir string
+}
+
+// NewFSStore returns a filesystem store rooted at dir.
+func NewFSStore(dir string) *FSStore {
+	return &FSStore{Dir: dir}
+}
+
+// File is a result file found by Files.
+type File struct {
+	Key  string // slash-separated path relative to Dir
+	Path string
+	Info fs.FileInfo
+}
+
+func (s *FSStore) Write(report *types.VerificationReport) error {
+	filename := fmt.Sprintf("%s-%s-%s-%s.verify.json",
+		report.VerifiedAt.Format("2006-01-02"),
+		safePart(report.RunID, "run"),
+		safePart(report.Agent, "agent"),
+		safePart(report.Model, "model"))
+	outDir := filepath.Join(s.Dir, report.Scenario)
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, filename), data, 0o644)
+}
+
+func (s *FSStore) List() ([]Record, error) {
+	files, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Record, 0, len(files))
+	for _, f := range files {
+		rec, err := s.Read(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// Files lists the .verify.json files under Dir without parsing them, skipping the smoke dir. A missing Dir has no files.
+func (s *FSStore) Files() ([]File, error) {
+	return s.files(true)
+}
+
+// Paths is Files without stating each file: every File's Info is nil.
+func (s *FSStore) Paths() ([]File, error) {
+	return s.files(false)
+}
+
+func (s *FSStore) files(stat bool) ([]File, error) {
+	if _, err := os.Stat(s.Dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []File
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if filepath.Clean(path) == filepath.Join(s.Dir, "smoke") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".verify.json") {
+			return nil
+		}
+		var info fs.FileInfo
+		if stat {
+			var err error
+			if info, err = d.Info(); err != nil {
+				return err
+			}
+		}
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		out = append(out, File{Key: filepath.ToSlash(rel), Path: path, Info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Read parses f.
+func (s *FSStore) Read(f File) (Record, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return Record{}, err
+	}
+	var rep types.VerificationReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return Record{}, fmt.Errorf("parse %s: %w", f.Path, err)
+	}
+	return Record{Key: f.Key, ModTime: f.Info.ModTime(), Report: rep}, nil
+}
+
+func safePart(value, fallback string) string {
+	val := strings.TrimSpace(value)
+	if val == "" {
+		return fallback
+	}
+	val = strings.ReplaceAll(val, string(os.PathSeparator), "_")
+	return val
+}
@@ -0,0 +1,133 @@
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// maxImportLineBytes caps one NDJSON record (reports with test output can be large).
+const maxImportLineBytes = 64 * 1024 * 1024
+
+// ImportSchemaVersion is the schema_version Import requires of each record.
+const ImportSchemaVersion = 1
+
+// ImportStats is the outcome of Import.
+type ImportStats struct {
+	Imported int
+	// Skipped counts malformed records, and Existing records whose run_id is already in the store. Problems has one
+	// "line N: ..." entry for each.
+	Skipped  int
+	Existing int
+	Problems []string
+}
+
+// Import reads NDJSON from r, one types.VerificationReport per line (blank lines are ignored) plus a schema_version
+// field that must be ImportSchemaVersion, and writes each valid record to store. A record is malformed, and skipped,
+// if it isn't a JSON object in that schema (unknown fields are rejected, so a record from an incompatible schema
+// doesn't import half-parsed), if it lacks schema_version, run_id, scenario, agent, model, or verified_at, or if its
+// scenario isn't one report shows (ex: "smoke"). A record whose run_id is already in store (or earlier in r) is skipped
+// too, rather than replacing the stored result. Transcripts are dropped, as verify does. Only read and store errors
+// fail the import.
+func Import(r io.Reader, store Store) (ImportStats, error) {
+	var stats ImportStats
+	existing, err := store.List()
+	if err != nil {
+		return stats, err
+	}
+	runIDs := map[string]bool{}
+	for _, rec := range existing {
+		runIDs[rec.Report.RunID] = true
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		report, err := parseImportRecord(line)
+		if err != nil {
+			stats.Skipped++
+			stats.Problems = append(stats.Problems, fmt.Sprintf("line %d: %v", lineNo, err))
+			continue
+		}
+		if runIDs[report.RunID] {
+			stats.Existing++
+			stats.Problems = append(stats.Problems, fmt.Sprintf("line %d: run_id %q is already in the results", lineNo, report.RunID))
+			continue
+		}
+		if err := store.Write(report); err != nil {
+			return stats, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		runIDs[report.RunID] = true
+		stats.Imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("line %d: %w", lineNo+1, err)
+	}
+	return stats, nil
+}
+
+// parseImportRecord decodes and validates one Import record.
+func parseImportRecord(line []byte) (*types.VerificationReport, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	var record struct {
+		SchemaVersion *int `json:"schema_version"`
+		types.VerificationReport
+	}
+	if err := dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("more than one JSON value")
+	}
+	report := record.VerificationReport
+	var missing []string
+	if record.SchemaVersion == nil {
+		missing = append(missing, "schema_version")
+	}
+	for _, f := range []struct{ name, value string }{
+		{"run_id", report.RunID},
+		{"scenario", report.Scenario},
+		{"agent", report.Agent},
+		{"model", report.Model},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if report.VerifiedAt.IsZero() {
+		missing = append(missing, "verified_at")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	if *record.SchemaVersion != ImportSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema_version %d (want %d)", *record.SchemaVersion, ImportSchemaVersion)
+	}
+	// The scenario names the record's dir in the results dir, so it must stay inside it, and not be the smoke dir
+	// (or the results dir itself), which report skips.
+	scenario := path.Clean(filepath.ToSlash(report.Scenario))
+	if !filepath.IsLocal(filepath.FromSlash(scenario)) || scenario == "." || ScenarioFromKey(scenario) == "smoke" {
+		return nil, fmt.Errorf("invalid scenario %q", report.Scenario)
+	}
+	report.Scenario = scenario
+	if report.Progress != nil {
+		progress := *report.Progress
+		progress.Transcripts = nil
+		report.Progress = &progress
+	}
+	return &report, nil
+}
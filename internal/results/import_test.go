@@ -0,0 +1,42 @@
+package results
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	store := &MemoryStore{}
+	batch := strings.Join([]string{
+		`{"schema_version":1,"run_id":"r1","scenario":"nested/demo","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z","tests":[]}`,
+		`{"schema_version":1,"run_id":"r2","scenario":"../escape","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z"}`,
+		`{"schema_version":1,"run_id":"r3","scenario":"demo","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z"} {}`,
+		`[]`,
+		`{"scenario":"demo","agent":"a"}`,
+		`{"schema_version":2,"run_id":"r4","scenario":"demo","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z"}`,
+		`{"schema_version":1,"run_id":"r5","scenario":"smoke/demo","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z"}`,
+		`{"schema_version":1,"run_id":"r6","scenario":"./","agent":"a","model":"m","verified_at":"2025-12-03T10:00:00Z"}`,
+		`{"schema_version":1,"run_id":"r1","scenario":"demo","agent":"a","model":"m","verified_at":"2025-12-04T10:00:00Z"}`,
+	}, "\n")
+	stats, err := Import(strings.NewReader(batch), store)
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Imported)
+	require.Equal(t, 7, stats.Skipped)
+	require.Equal(t, 1, stats.Existing)
+	require.Len(t, stats.Problems, 8)
+	require.Equal(t, `line 2: invalid scenario "../escape"`, stats.Problems[0])
+	require.Equal(t, "line 3: more than one JSON value", stats.Problems[1])
+	require.Contains(t, stats.Problems[2], "line 4: json: cannot unmarshal array")
+	require.Equal(t, "line 5: missing schema_version, run_id, model, verified_at", stats.Problems[3])
+	require.Equal(t, "line 6: unsupported schema_version 2 (want 1)", stats.Problems[4])
+	require.Equal(t, `line 7: invalid scenario "smoke/demo"`, stats.Problems[5])
+	require.Equal(t, `line 8: invalid scenario "./"`, stats.Problems[6])
+	require.Equal(t, `line 9: run_id "r1" is already in the results`, stats.Problems[7])
+
+	records, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "r1", records[0].Report.RunID)
+}
@@ -0,0 +1,36 @@
+package results
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// MemoryStore keeps reports in memory. It's useful in tests and as a template for other stores.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (s *MemoryStore) Write(report *types.VerificationReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := fmt.Sprintf("%s/%d-%s.verify.json", report.Scenario, len(s.records), report.RunID)
+	s.records = append(s.records, Record{Key: key, ModTime: time.Now(), Report: *report})
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		if ScenarioFromKey(r.Key) == "smoke" {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,49 @@
+// Package results stores and lists verification reports.
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// EnvVar overrides the default results dir (<root>/results). Relative values are relative to the root.
+const EnvVar = "GOAGENTBENCH_RESULTS"
+
+// Record is one stored verification report.
+type Record struct {
+	// Key identifies the record within its store. For FSStore it's the slash-separated path relative to the results dir
+	// (ex: "tui_build/2025-12-03-run_1-codex-gpt.verify.json"), whose first element is the scenario.
+	Key     string
+	ModTime time.Time
+	Report  types.VerificationReport
+}
+
+// Store persists verification reports. FSStore is the default; other implementations (ex: remote) can be plugged into
+// verify and report.
+type Store interface {
+	// Write stores report. Transcripts must already be stripped if they shouldn't be stored.
+	Write(report *types.VerificationReport) error
+	// List returns every stored record, excluding smoke results.
+	List() ([]Record, error)
+}
+
+// Dir returns the results dir for rootPath, honoring EnvVar.
+func Dir(rootPath string) string {
+	if env := strings.TrimSpace(os.Getenv(EnvVar)); env != "" {
+		if filepath.IsAbs(env) {
+			return filepath.Clean(env)
+		}
+		return filepath.Join(rootPath, filepath.Clean(env))
+	}
+	return filepath.Join(rootPath, "results")
+}
+
+// ScenarioFromKey returns the first element of a record key, which stores use as the scenario name.
+func ScenarioFromKey(key string) string {
+	first, _, _ := strings.Cut(key, "/")
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,52 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"fs":     func(t *testing.T) Store { return NewFSStore(filepath.Join(t.TempDir(), "results")) },
+		"memory": func(t *testing.T) Store { return &MemoryStore{} },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			records, err := store.List()
+			require.NoError(t, err)
+			assert.Empty(t, records)
+
+			verifiedAt := time.Date(2025, 12, 3, 10, 0, 0, 0, time.UTC)
+			require.NoError(t, store.Write(&types.VerificationReport{RunID: "run_1", Scenario: "demo", Agent: "codex", Model: "gpt", VerifiedAt: verifiedAt, Success: true}))
+			require.NoError(t, store.Write(&types.VerificationReport{RunID: "run_2", Scenario: "smoke", Agent: "codex", Model: "gpt", VerifiedAt: verifiedAt}))
+
+			records, err = store.List()
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			assert.Equal(t, "demo", ScenarioFromKey(records[0].Key))
+			assert.Equal(t, "run_1", records[0].Report.RunID)
+			assert.True(t, records[0].Report.Success)
+			assert.True(t, records[0].Report.VerifiedAt.Equal(verifiedAt))
+			assert.False(t, records[0].ModTime.IsZero())
+		})
+	}
+}
+
+func TestFSStoreWriteLayout(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFSStore(dir)
+	rep := &types.VerificationReport{RunID: "run_1", Scenario: "demo", Agent: "codex", Model: "gpt-5", VerifiedAt: time.Date(2025, 12, 3, 10, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Write(rep))
+
+	data, err := os.ReadFile(filepath.Join(dir, "demo", "2025-12-03-run_1-codex-gpt-5.verify.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"run_id\": \"run_1\"")
+}
@@ -0,0 +1,55 @@
+package scenario
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CheckSinglePackage returns an error if classification.single-package is true but the verify.tests and partial-tests
+// targets aren't all in one package. A target in a verify.copy destination dir (ex: hidden tests copied into
+// internal/foo/gab) counts as being in the destination's parent package. It's a soft check: callers warn rather than
+// fail.
+func CheckSinglePackage(sc *Scenario) error {
+	if sc == nil || sc.Classification.SinglePackage == nil || !*sc.Classification.SinglePackage {
+		return nil
+	}
+	copyDirs := map[string]bool{}
+	for _, c := range sc.Verify.Copy {
+		copyDirs[filepath.Clean(c.To)] = true
+	}
+	pkgs := map[string]bool{}
+	for _, field := range []struct {
+		name    string
+		entries StringList
+	}{{"verify.tests", sc.Verify.Tests}, {"verify.partial-tests", sc.Verify.PartialTests}} {
+		targets, err := parseTestTargets(field.name, field.entries)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			if strings.Contains(t.Target, "...") {
+				return fmt.Errorf("classification.single-package is true, but %s target %q matches multiple packages", field.name, t.Target)
+			}
+			dir := t.Target
+			if hasGlob(dir) || strings.HasSuffix(dir, ".go") {
+				dir = filepath.Dir(dir)
+			}
+			dir = filepath.Clean(dir)
+			if copyDirs[dir] {
+				dir = filepath.Dir(dir)
+			}
+			pkgs[filepath.ToSlash(dir)] = true
+		}
+	}
+	if len(pkgs) <= 1 {
+		return nil
+	}
+	names := make([]string, 0, len(pkgs))
+	for pkg := range pkgs {
+		names = append(names, pkg)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("classification.single-package is true, but tests span %d packages: %s", len(names), strings.Join(names, ", "))
+}
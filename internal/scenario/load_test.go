@@ -28,3 +28,38 @@ agent:
 	require.Equal(t, "internal/cli", sc.Agent.Package)
 }
 
+func TestLoad_MergesTestsFile(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scenario.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: demo
+repo: github.com/example/repo
+commit: 1234567
+classification:
+  type: feature
+agent:
+  instructions: hello
+verify:
+  tests: ./inline
+  tests-file: tests.txt
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tests.txt"), []byte("# curated packages\n./a\n\n./b -run TestB\n"), 0o644))
+
+	sc, err := scenario.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, scenario.StringList{"./inline", "./a", "./b -run TestB"}, sc.Verify.Tests)
+	require.NoError(t, scenario.Validate(sc, dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tests.txt"), []byte("./a\n/abs/path\n"), 0o644))
+	require.ErrorContains(t, scenario.Validate(sc, dir), `verify.tests-file tests.txt entry "/abs/path"`)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "tests.txt")))
+	_, err = scenario.Load(path)
+	require.ErrorContains(t, err, "verify.tests-file does not exist: tests.txt")
+
+	// The tests file must be inside the scenario dir.
+	require.NoError(t, os.WriteFile(path, []byte("name: demo\nverify:\n  tests-file: ../outside.txt\n"), 0o644))
+	_, err = scenario.Load(path)
+	require.ErrorContains(t, err, `verify.tests-file: path "../outside.txt" escapes base directory`)
+}
@@ -0,0 +1,144 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// MatrixInstance is one concrete scenario expanded from a scenario's matrix.
+type MatrixInstance struct {
+	// Params maps each matrix parameter to its value in this instance.
+	Params   map[string]string
+	Scenario *Scenario
+}
+
+// ID identifies the instance by its parameters, sorted by name (ex: "func=Add,pkg=mathx").
+func (m MatrixInstance) ID() string {
+	keys := make([]string, 0, len(m.Params))
+	for k := range m.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + m.Params[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// ExpandMatrix returns one instance per combination of s.Matrix values (parameters in name order, each parameter's
+// values in file order). Each instance is s without the matrix, with {{.param}} templates in name, instructions, and
+// verify test targets (of s and of each stage) replaced by the instance's values. It returns nil if s has no matrix.
+func (s Scenario) ExpandMatrix() ([]MatrixInstance, error) {
+	if len(s.Matrix) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(s.Matrix))
+	for k := range s.Matrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	combos := []map[string]string{{}}
+	for _, k := range keys {
+		if strings.TrimSpace(k) == "" {
+			return nil, errors.New("matrix parameter names cannot be empty")
+		}
+		values := s.Matrix[k]
+		if len(values) == 0 {
+			return nil, fmt.Errorf("matrix.%s needs at least one value", k)
+		}
+		seen := map[string]bool{}
+		var next []map[string]string
+		for _, v := range values {
+			if seen[v] {
+				return nil, fmt.Errorf("matrix.%s has duplicate value %q", k, v)
+			}
+			seen[v] = true
+			for _, c := range combos {
+				combo := make(map[string]string, len(c)+1)
+				for ck, cv := range c {
+					combo[ck] = cv
+				}
+				combo[k] = v
+				next = append(next, combo)
+			}
+		}
+		combos = next
+	}
+	out := make([]MatrixInstance, 0, len(combos))
+	for _, params := range combos {
+		inst := MatrixInstance{Params: params}
+		sc, err := s.instantiate(params)
+		if err != nil {
+			return nil, fmt.Errorf("matrix (%s): %w", inst.ID(), err)
+		}
+		inst.Scenario = sc
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
+// instantiate returns a copy of s without its matrix, with params substituted into its templated fields.
+func (s Scenario) instantiate(params map[string]string) (*Scenario, error) {
+	sc := s
+	sc.Matrix = nil
+	var err error
+	sub := func(field, text string) string {
+		if err != nil || !strings.Contains(text, "{{") {
+			return text
+		}
+		tmpl, parseErr := template.New(field).Option("missingkey=error").Parse(text)
+		if parseErr != nil {
+			err = fmt.Errorf("%s: %w", field, parseErr)
+			return text
+		}
+		var b strings.Builder
+		if execErr := tmpl.Execute(&b, params); execErr != nil {
+			err = fmt.Errorf("%s: %w", field, execErr)
+			return text
+		}
+		return b.String()
+	}
+	subList := func(field string, list StringList) StringList {
+		if list == nil {
+			return nil
+		}
+		out := make(StringList, len(list))
+		for i, v := range list {
+			out[i] = sub(field, v)
+		}
+		return out
+	}
+	subVerify := func(prefix string, v VerifyConfig) VerifyConfig {
+		v.Tests = subList(prefix+"tests", v.Tests)
+		v.PartialTests = subList(prefix+"partial-tests", v.PartialTests)
+		v.MustFail = subList(prefix+"must-fail", v.MustFail)
+		v.MustModify = subList(prefix+"must-modify", v.MustModify)
+		if v.MustModifyAnyOf != nil {
+			groups := make([]StringList, len(v.MustModifyAnyOf))
+			for i, group := range v.MustModifyAnyOf {
+				groups[i] = subList(prefix+"must-modify-any-of", group)
+			}
+			v.MustModifyAnyOf = groups
+		}
+		return v
+	}
+	sc.Name = sub("name", sc.Name)
+	sc.Agent.Instructions = sub("agent.instructions", sc.Agent.Instructions)
+	sc.Verify = subVerify("verify.", sc.Verify)
+	if s.Stages != nil {
+		sc.Stages = make([]Stage, len(s.Stages))
+		for i, st := range s.Stages {
+			st.Instructions = sub(fmt.Sprintf("stages[%d].instructions", i), st.Instructions)
+			st.Verify = subVerify(fmt.Sprintf("stages[%d].verify.", i), st.Verify)
+			sc.Stages[i] = st
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
@@ -1,26 +1,69 @@
 package scenario
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/codalotl/goagentbench/internal/cmdshim"
+	"github.com/codalotl/goagentbench/internal/fsutil"
+	"github.com/codalotl/goagentbench/internal/semver"
 )
 
 // Scenario represents the scenario.yml file contents.
 type Scenario struct {
-	Name           string         `yaml:"name"`
-	Repo           string         `yaml:"repo"`
-	Commit         string         `yaml:"commit"`
-	Classification Classification `yaml:"classification"`
-	Setup          *SetupConfig   `yaml:"setup"`
-	Agent          AgentConfig    `yaml:"agent"`
-	Verify         VerifyConfig   `yaml:"verify"`
+	Name   string `yaml:"name"`
+	Repo   string `yaml:"repo"`
+	Commit string `yaml:"commit"`
+	// AllowedGoVersions, if set, is a constraint on the Go toolchain verify runs with (ex: ">=1.21,<1.24"; see
+	// semver.ParseGoConstraint). verify refuses to run on a toolchain outside it.
+	AllowedGoVersions string         `yaml:"allowed-go-versions"`
+	Classification    Classification `yaml:"classification"`
+	Setup             *SetupConfig   `yaml:"setup"`
+	Agent             AgentConfig    `yaml:"agent"`
+	Verify            VerifyConfig   `yaml:"verify"`
+	// Stages, if set, replace agent.instructions and verify: each stage's instructions are sent in turn (resuming the
+	// agent's session), and each stage is verified before the next starts.
+	Stages []Stage `yaml:"stages"`
+	// Matrix, if set, makes this a family of scenarios: one instance per combination of parameter values, with
+	// {{.param}} templates substituted (see ExpandMatrix).
+	Matrix map[string][]string `yaml:"matrix"`
+}
+
+// Stage is one step of a multi-step (stacked) scenario.
+type Stage struct {
+	Name         string       `yaml:"name"`
+	Instructions string       `yaml:"instructions"`
+	Verify       VerifyConfig `yaml:"verify"`
+}
+
+// StageScenarios returns one scenario per stage: s with the stage's instructions and verify block (and no stages), so
+// it can be run and verified like a single-stage scenario. It returns nil if s has no stages.
+func (s Scenario) StageScenarios() []*Scenario {
+	out := make([]*Scenario, 0, len(s.Stages))
+	for _, st := range s.Stages {
+		stageSc := s
+		stageSc.Agent.Instructions = st.Instructions
+		stageSc.Verify = st.Verify
+		stageSc.Stages = nil
+		out = append(out, &stageSc)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
 }
 
 type Classification struct {
@@ -33,12 +76,71 @@ type Classification struct {
 type SetupConfig struct {
 	Copy  []CopyStep `yaml:"copy"`
 	Patch StringList `yaml:"patch"`
-	Exec  StringList `yaml:"exec"`
+	Exec  ExecSteps  `yaml:"exec"`
+}
+
+// ExecStep is a setup.exec entry: either a plain shell command or {cmd, expect-stdout-contains}.
+type ExecStep struct {
+	Cmd string `yaml:"cmd"`
+	// ExpectStdoutContains, if set, fails setup unless the command's stdout contains it.
+	ExpectStdoutContains string `yaml:"expect-stdout-contains"`
+}
+
+// UnmarshalYAML makes ExecStep accept a plain string as shorthand for {cmd: <string>}.
+func (e *ExecStep) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&e.Cmd)
+	}
+	type plain ExecStep
+	return value.Decode((*plain)(e))
+}
+
+// ExecSteps allows unmarshalling a single step or a list of them, like StringList.
+type ExecSteps []ExecStep
+
+// UnmarshalYAML makes ExecSteps accept a string, a {cmd, ...} mapping, or a list of either.
+func (s *ExecSteps) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		var v string
+		if err := value.Decode(&v); err != nil {
+			return err
+		}
+		if v != "" {
+			*s = ExecSteps{{Cmd: v}}
+		}
+		return nil
+	case yaml.MappingNode:
+		var step ExecStep
+		if err := value.Decode(&step); err != nil {
+			return err
+		}
+		*s = ExecSteps{step}
+		return nil
+	case yaml.SequenceNode:
+		var steps []ExecStep
+		if err := value.Decode(&steps); err != nil {
+			return err
+		}
+		*s = steps
+		return nil
+	case 0:
+		return nil
+	default:
+		return fmt.Errorf("expected string, mapping, or list, got %v", value.Kind)
+	}
 }
 
 type CopyStep struct {
 	From string `yaml:"from"`
 	To   string `yaml:"to"`
+	// Overwrite controls whether verify.copy may replace existing files. Defaults to true; setup.copy never overwrites.
+	Overwrite *bool `yaml:"overwrite,omitempty"`
+}
+
+// OverwriteEnabled reports whether the step may replace existing files (Overwrite, defaulting to true).
+func (c CopyStep) OverwriteEnabled() bool {
+	return c.Overwrite == nil || *c.Overwrite
 }
 
 type AgentConfig struct {
@@ -46,16 +148,258 @@ type AgentConfig struct {
 	Instructions                     string `yaml:"instructions"`
 	AllowMultipleTurns               bool   `yaml:"allow-multiple-turns"`
 	AllowMultipleTurnsOnFailedVerify bool   `yaml:"allow-multiple-turns-on-failed-verify"`
+	RecordNetwork                    bool   `yaml:"record-network"`
+	// SkipBaseInstructions opts out of the repo-level base instructions (instructions_preamble.md) preamble.
+	SkipBaseInstructions bool `yaml:"skip-base-instructions"`
+	// SetupCommands are shell commands run in the workspace right before the agent starts (ex: agent-specific config, or
+	// warming the module cache). Unlike setup.exec, they're not part of the base state the agent's diff is measured
+	// against, and they're not timed as part of the run.
+	SetupCommands StringList `yaml:"setup-commands"`
+	// DenyCommands are blocked for the agent by shim scripts prepended to its PATH (see package cmdshim for limits).
+	DenyCommands []string `yaml:"deny-commands"`
+	// Env is set only in the agent's environment (not setup or verify). Values are secrets: they are never printed.
+	Env SecretEnv `yaml:"env"`
+	// TokenBudget, when > 0, stops the run after the turn where the run's total tokens exceed it.
+	TokenBudget int `yaml:"token-budget"`
+}
+
+// SecretEnv is a map of environment variables whose values are masked when marshaled to JSON.
+type SecretEnv map[string]string
+
+var envKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// MarshalJSON masks every value so secrets don't end up in printed or persisted scenario JSON.
+func (e SecretEnv) MarshalJSON() ([]byte, error) {
+	masked := make(map[string]string, len(e))
+	for k := range e {
+		masked[k] = "***"
+	}
+	return json.Marshal(masked)
+}
+
+// Keys returns the variable names, sorted.
+func (e SecretEnv) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entries returns KEY=VALUE entries sorted by key, suitable for exec.Cmd.Env.
+func (e SecretEnv) Entries() []string {
+	out := make([]string, 0, len(e))
+	for _, k := range e.Keys() {
+		out = append(out, k+"="+e[k])
+	}
+	return out
+}
+
+// Validate returns an error if any key is not a valid environment variable name.
+func (e SecretEnv) Validate(field string) error {
+	for _, k := range e.Keys() {
+		if !envKeyRe.MatchString(k) {
+			return fmt.Errorf("%s: invalid environment variable name %q", field, k)
+		}
+	}
+	return nil
 }
 
 type VerifyConfig struct {
-	MustModify   StringList `yaml:"must-modify"`
-	NoModify     []string   `yaml:"no-modify"`
+	MustModify StringList `yaml:"must-modify"`
+	// MustModifyAnyOf is a list of groups of must-modify style rules: each group passes if at least one of its rules
+	// matches a modified file (ex: the fix may land in either of two files).
+	MustModifyAnyOf []StringList `yaml:"must-modify-any-of"`
+	NoModify        []string     `yaml:"no-modify"`
+	// ProtectTests fails verification if any committed _test.go file was modified or deleted.
+	ProtectTests bool       `yaml:"protect-tests"`
 	Copy         []CopyStep `yaml:"copy"`
 	Tests        StringList `yaml:"tests"`
+	// TestsFile is a file (relative to the scenario dir) listing more tests entries, one per line. Load merges them
+	// into Tests.
+	TestsFile    string     `yaml:"tests-file"`
 	PartialTests StringList `yaml:"partial-tests"`
+	// Commands are shell commands (ex: external graders) that must pass, run after Tests with verify.copy applied.
+	Commands []VerifyCommand `yaml:"commands"`
+	// MustFail lists test targets (same format as Tests) that must still have at least one failing test.
+	MustFail StringList `yaml:"must-fail"`
+	// MustBeExecutable lists workspace files that must have an execute bit set after the agent runs.
+	MustBeExecutable StringList `yaml:"must-be-executable"`
+	ModTidy          bool       `yaml:"mod-tidy"`
+	// Generate fails verification if `go generate ./...` (run in a copy of the workspace) would change any file.
+	Generate bool `yaml:"generate"`
+	// Retries re-runs a failing verify.tests entry up to this many more times; any passing attempt passes it (as flaky).
+	Retries int `yaml:"retries"`
+	// Timeout caps the wall-clock time of all verify.tests, verify.commands, must-fail, and partial-tests entries
+	// together (ex: "10m"). Entries not finished by then fail; 0 means no limit.
+	Timeout time.Duration `yaml:"timeout"`
+	// TestTimeout caps each go test invocation (ex: "5m"), so one hanging entry fails without blocking the rest; 0
+	// means no limit.
+	TestTimeout time.Duration `yaml:"test-timeout"`
+	// MemoryLimitMB, when > 0, runs each go test binary under an address-space limit of this many MB (Linux only), so a
+	// runaway solution fails its tests instead of exhausting the host's memory.
+	MemoryLimitMB int `yaml:"memory-limit-mb"`
+	// NoNewDeps fails verification if go.mod gained direct requirements not listed in NoNewDepsAllow.
+	NoNewDeps      bool     `yaml:"no-new-deps"`
+	NoNewDepsAllow []string `yaml:"no-new-deps-allow"`
+	// PartialMode controls how partial-tests are scored: PartialModePerTest (default) or PartialModePerEntry.
+	PartialMode string `yaml:"partial-mode"`
+	// NoStdoutNoise runs Tests with -json and fails verification if passing tests print lines go test didn't (ex:
+	// leftover debug prints). The check is heuristic and conservative.
+	NoStdoutNoise bool `yaml:"no-stdout-noise"`
+	// Shuffle runs every go test invocation with -shuffle (the seed is recorded in the report).
+	Shuffle bool `yaml:"shuffle"`
+	// GOOS and GOARCH set the target for go test. If the host can't run the target, tests are only built.
+	GOOS   string `yaml:"goos"`
+	GOARCH string `yaml:"goarch"`
+	// PostHook is a shell command run after verification regardless of outcome, with GAB_SUCCESS=true/false set.
+	PostHook string `yaml:"post-hook"`
+	// RaceMode runs go test with -race: RaceModeOff (default), RaceModeWarn, or RaceModeFail.
+	RaceMode string `yaml:"race-mode"`
+	// PartialMinTests, when > 0, fails verification if a partial-tests entry ran fewer tests than this (ex: a -run
+	// pattern typo that matches nothing), rather than scoring it as if the tests failed.
+	PartialMinTests int `yaml:"partial-min-tests"`
+	// NoDelete lists files/dirs/globs (matched like NoModify) the agent may modify but not delete.
+	NoDelete []string `yaml:"no-delete"`
+	// PartialWeights are the weights of the PartialTests entries, in order: an entry is a plain string (weight 1) or
+	// {test, weight}. Nil if no entry set a weight.
+	PartialWeights []float64 `yaml:"-"`
 }
 
+// UnmarshalYAML lets verify.partial-tests entries be {test, weight} mappings as well as strings: the tests go in
+// PartialTests and the weights in PartialWeights.
+func (v *VerifyConfig) UnmarshalYAML(value *yaml.Node) error {
+	rest := *value
+	var partial *yaml.Node
+	if value.Kind == yaml.MappingNode {
+		rest.Content = nil
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "partial-tests" {
+				partial = value.Content[i+1]
+				continue
+			}
+			rest.Content = append(rest.Content, value.Content[i], value.Content[i+1])
+		}
+	}
+	type plain VerifyConfig
+	if err := rest.Decode((*plain)(v)); err != nil {
+		return err
+	}
+	if partial == nil {
+		return nil
+	}
+	var entries []partialTest
+	switch partial.Kind {
+	case yaml.ScalarNode:
+		var entry partialTest
+		if err := partial.Decode(&entry); err != nil {
+			return err
+		}
+		if entry.Test != "" {
+			entries = append(entries, entry)
+		}
+	case yaml.SequenceNode:
+		if err := partial.Decode(&entries); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("partial-tests: expected string or list, got %v", partial.Kind)
+	}
+	v.PartialTests = nil
+	weights := make([]float64, 0, len(entries))
+	weighted := false
+	for _, entry := range entries {
+		v.PartialTests = append(v.PartialTests, entry.Test)
+		if entry.Weight == nil {
+			weights = append(weights, 1)
+			continue
+		}
+		weights = append(weights, *entry.Weight)
+		weighted = true
+	}
+	if weighted {
+		v.PartialWeights = weights
+	}
+	return nil
+}
+
+// PartialWeight returns the weight of PartialTests[i] (1 unless the entry set one).
+func (v VerifyConfig) PartialWeight(i int) float64 {
+	if i < len(v.PartialWeights) {
+		return v.PartialWeights[i]
+	}
+	return 1
+}
+
+// partialTest is a verify.partial-tests entry: either a plain test entry string or {test, weight}.
+type partialTest struct {
+	Test   string   `yaml:"test"`
+	Weight *float64 `yaml:"weight"`
+}
+
+// UnmarshalYAML makes partialTest accept a plain string as shorthand for {test: <string>}.
+func (p *partialTest) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&p.Test)
+	}
+	type plain partialTest
+	if err := value.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	if p.Test == "" {
+		return errors.New("partial-tests: a {test, weight} entry needs test")
+	}
+	return nil
+}
+
+// VerifyCommand is a verify.commands entry: either a plain command string or {cmd, ok-exit}.
+type VerifyCommand struct {
+	Cmd string `yaml:"cmd"`
+	// OkExit lists the exit codes that count as a pass. Empty means only 0.
+	OkExit []int `yaml:"ok-exit"`
+}
+
+// UnmarshalYAML makes VerifyCommand accept a plain string as shorthand for {cmd: <string>}.
+func (c *VerifyCommand) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.Cmd)
+	}
+	type plain VerifyCommand
+	return value.Decode((*plain)(c))
+}
+
+// Passes reports whether exit code counts as a pass.
+func (c VerifyCommand) Passes(code int) bool {
+	if len(c.OkExit) == 0 {
+		return code == 0
+	}
+	return slices.Contains(c.OkExit, code)
+}
+
+const (
+	// PartialModePerTest scores partial success as passed tests / total tests across all partial-tests entries.
+	PartialModePerTest = "per-test"
+	// PartialModePerEntry averages each partial-tests entry's own pass fraction, so every entry weighs the same.
+	PartialModePerEntry = "per-entry"
+)
+
+const (
+	// RaceModeOff runs go test without -race.
+	RaceModeOff = "off"
+	// RaceModeWarn runs go test with -race and records detected races, but a test that fails only because of a race
+	// still passes.
+	RaceModeWarn = "warn"
+	// RaceModeFail runs go test with -race, so a detected race fails its test.
+	RaceModeFail = "fail"
+)
+
+// knownGOOS and knownGOARCH are the values accepted for verify.goos/goarch (from `go tool dist list`).
+var (
+	knownGOOS   = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js", "linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows"}
+	knownGOARCH = []string{"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le", "mipsle", "ppc64", "ppc64le", "riscv64", "s390x", "wasm"}
+)
+
 // TestTarget represents a go test target and optional -run pattern.
 type TestTarget struct {
 	Target string
@@ -102,11 +446,116 @@ func Load(path string) (*Scenario, error) {
 	if err := yaml.Unmarshal(data, &sc); err != nil {
 		return nil, err
 	}
+	scenarioDir := filepath.Dir(path)
+	if err := mergeTestsFile(&sc.Verify, scenarioDir); err != nil {
+		return nil, err
+	}
+	for i := range sc.Stages {
+		if err := mergeTestsFile(&sc.Stages[i].Verify, scenarioDir); err != nil {
+			return nil, fmt.Errorf("stages[%d]: %w", i, err)
+		}
+	}
 	return &sc, nil
 }
 
-// Validate checks required fields and referenced files.
+// mergeTestsFile appends the entries of v.TestsFile (if set) to v.Tests.
+func mergeTestsFile(v *VerifyConfig, scenarioDir string) error {
+	if v.TestsFile == "" {
+		return nil
+	}
+	entries, err := readTestsFile(scenarioDir, v.TestsFile)
+	if err != nil {
+		return err
+	}
+	v.Tests = append(v.Tests, entries...)
+	return nil
+}
+
+// readTestsFile reads the tests entries in a verify.tests-file: one per line, skipping blank lines and # comments.
+func readTestsFile(scenarioDir, name string) (StringList, error) {
+	path, err := fsutil.SafeJoin(scenarioDir, name)
+	if err != nil {
+		return nil, fmt.Errorf("verify.tests-file: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("verify.tests-file does not exist: %s", name)
+		}
+		return nil, fmt.Errorf("read verify.tests-file: %w", err)
+	}
+	var entries StringList
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, nil
+}
+
+// Validate checks required fields and referenced files. A scenario with a matrix is valid if every instance is.
 func Validate(sc *Scenario, scenarioDir string) error {
+	instances, err := sc.ExpandMatrix()
+	if err != nil {
+		return err
+	}
+	for _, inst := range instances {
+		if err := validateInstance(inst.Scenario, scenarioDir); err != nil {
+			return fmt.Errorf("matrix (%s): %w", inst.ID(), err)
+		}
+	}
+	if instances == nil {
+		if err := validateInstance(sc, scenarioDir); err != nil {
+			return err
+		}
+	}
+	if err := checkRemoteCommit(sc.Repo, sc.Commit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateInstance checks a scenario without a matrix.
+func validateInstance(sc *Scenario, scenarioDir string) error {
+	if len(sc.Stages) > 0 {
+		return validateStages(sc, scenarioDir)
+	}
+	return validate(sc, scenarioDir)
+}
+
+// validateStages checks a staged scenario: stages replace agent.instructions and verify, and each stage must be valid
+// as a single-stage scenario.
+func validateStages(sc *Scenario, scenarioDir string) error {
+	if strings.TrimSpace(sc.Agent.Instructions) != "" {
+		return errors.New("agent.instructions can't be combined with stages; set instructions on each stage")
+	}
+	if !reflect.ValueOf(sc.Verify).IsZero() {
+		return errors.New("verify can't be combined with stages; set verify on each stage")
+	}
+	seen := map[string]bool{}
+	for i, stageSc := range sc.StageScenarios() {
+		name := strings.TrimSpace(sc.Stages[i].Name)
+		if name == "" {
+			return fmt.Errorf("stages[%d]: name is required", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("stages[%d]: duplicate stage name %q", i, name)
+		}
+		seen[name] = true
+		if strings.TrimSpace(sc.Stages[i].Instructions) == "" {
+			return fmt.Errorf("stages[%d] (%s): instructions is required", i, name)
+		}
+		if err := validate(stageSc, scenarioDir); err != nil {
+			return fmt.Errorf("stages[%d] (%s): %w", i, name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks everything but the remote commit.
+func validate(sc *Scenario, scenarioDir string) error {
 	if sc.Name == "" {
 		return errors.New("scenario name is required")
 	}
@@ -119,18 +568,58 @@ func Validate(sc *Scenario, scenarioDir string) error {
 	if sc.Classification.Type == "" {
 		return errors.New("classification.type is required")
 	}
+	if sc.AllowedGoVersions != "" {
+		if _, err := semver.ParseGoConstraint(sc.AllowedGoVersions); err != nil {
+			return fmt.Errorf("allowed-go-versions: %w", err)
+		}
+	}
 	if strings.TrimSpace(sc.Agent.Instructions) == "" {
 		return errors.New("agent.instructions is required")
 	}
+	if err := sc.Agent.Env.Validate("agent.env"); err != nil {
+		return err
+	}
+	for _, cmd := range sc.Agent.SetupCommands {
+		if strings.TrimSpace(cmd) == "" {
+			return errors.New("agent.setup-commands entries cannot be empty")
+		}
+	}
+	if sc.Agent.TokenBudget < 0 {
+		return fmt.Errorf("agent.token-budget must be >= 0, got %d", sc.Agent.TokenBudget)
+	}
+	for _, name := range sc.Agent.DenyCommands {
+		if err := cmdshim.ValidateName(name); err != nil {
+			return fmt.Errorf("agent.deny-commands: %w", err)
+		}
+	}
 	if _, err := sc.TestTargets(); err != nil {
 		return err
 	}
+	if sc.Verify.TestsFile != "" {
+		entries, err := readTestsFile(scenarioDir, sc.Verify.TestsFile)
+		if err != nil {
+			return err
+		}
+		if _, err := parseTestTargets("verify.tests-file "+sc.Verify.TestsFile, entries); err != nil {
+			return err
+		}
+	}
 	if _, err := sc.PartialTestTargets(); err != nil {
 		return err
 	}
+	if _, err := sc.MustFailTestTargets(); err != nil {
+		return err
+	}
 	if err := validateCopySteps(sc.Setup, scenarioDir); err != nil {
 		return err
 	}
+	if sc.Setup != nil {
+		for _, c := range sc.Setup.Copy {
+			if c.Overwrite != nil {
+				return fmt.Errorf("setup.copy does not support overwrite (setup copies never overwrite): %s", c.From)
+			}
+		}
+	}
 	if err := validateCopySteps(&SetupConfig{Copy: sc.Verify.Copy}, scenarioDir); err != nil {
 		return err
 	}
@@ -146,9 +635,70 @@ func Validate(sc *Scenario, scenarioDir string) error {
 	if err := validateMustModify(sc.Verify.MustModify); err != nil {
 		return err
 	}
-	if err := checkRemoteCommit(sc.Repo, sc.Commit); err != nil {
+	if err := validateMustModifyAnyOf(sc.Verify.MustModifyAnyOf); err != nil {
 		return err
 	}
+	for _, p := range sc.Verify.MustBeExecutable {
+		rel := strings.TrimSpace(p)
+		if rel == "" {
+			return errors.New("verify.must-be-executable entries cannot be empty")
+		}
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(filepath.Clean(rel), ".."+string(filepath.Separator)) {
+			return fmt.Errorf("verify.must-be-executable %q must be relative to the workspace", rel)
+		}
+	}
+	for _, c := range sc.Verify.Commands {
+		if strings.TrimSpace(c.Cmd) == "" {
+			return errors.New("verify.commands entries cannot be empty")
+		}
+		for _, code := range c.OkExit {
+			if code < 0 || code > 255 {
+				return fmt.Errorf("verify.commands %q: ok-exit code %d is out of range 0-255", c.Cmd, code)
+			}
+		}
+	}
+	if sc.Verify.Retries < 0 {
+		return fmt.Errorf("verify.retries must be >= 0, got %d", sc.Verify.Retries)
+	}
+	if sc.Verify.MemoryLimitMB < 0 {
+		return fmt.Errorf("verify.memory-limit-mb must be >= 0, got %d", sc.Verify.MemoryLimitMB)
+	}
+	if sc.Verify.Timeout < 0 {
+		return fmt.Errorf("verify.timeout must be >= 0, got %s", sc.Verify.Timeout)
+	}
+	if sc.Verify.TestTimeout < 0 {
+		return fmt.Errorf("verify.test-timeout must be >= 0, got %s", sc.Verify.TestTimeout)
+	}
+	if sc.Verify.PartialMinTests < 0 {
+		return fmt.Errorf("verify.partial-min-tests must be >= 0, got %d", sc.Verify.PartialMinTests)
+	}
+	for i, w := range sc.Verify.PartialWeights {
+		if w < 0 || math.IsNaN(w) {
+			return fmt.Errorf("verify.partial-tests %q: weight must be >= 0, got %v", sc.Verify.PartialTests[i], w)
+		}
+	}
+	if sc.Verify.PartialMinTests > 0 && len(sc.Verify.PartialTests) == 0 {
+		return errors.New("verify.partial-min-tests requires verify.partial-tests")
+	}
+	if len(sc.Verify.NoNewDepsAllow) > 0 && !sc.Verify.NoNewDeps {
+		return errors.New("verify.no-new-deps-allow requires verify.no-new-deps")
+	}
+	if sc.Verify.GOOS != "" && !slices.Contains(knownGOOS, sc.Verify.GOOS) {
+		return fmt.Errorf("verify.goos %q is not a known GOOS (%s)", sc.Verify.GOOS, strings.Join(knownGOOS, ", "))
+	}
+	if sc.Verify.GOARCH != "" && !slices.Contains(knownGOARCH, sc.Verify.GOARCH) {
+		return fmt.Errorf("verify.goarch %q is not a known GOARCH (%s)", sc.Verify.GOARCH, strings.Join(knownGOARCH, ", "))
+	}
+	switch sc.Verify.PartialMode {
+	case "", PartialModePerTest, PartialModePerEntry:
+	default:
+		return fmt.Errorf("verify.partial-mode must be %q or %q, got %q", PartialModePerTest, PartialModePerEntry, sc.Verify.PartialMode)
+	}
+	switch sc.Verify.RaceMode {
+	case "", RaceModeOff, RaceModeWarn, RaceModeFail:
+	default:
+		return fmt.Errorf("verify.race-mode must be %q, %q, or %q, got %q", RaceModeOff, RaceModeWarn, RaceModeFail, sc.Verify.RaceMode)
+	}
 	return nil
 }
 
@@ -197,13 +747,27 @@ func validateExecSteps(cfg *SetupConfig) error {
 		return nil
 	}
 	for _, entry := range cfg.Exec {
-		if strings.TrimSpace(entry) == "" {
+		if strings.TrimSpace(entry.Cmd) == "" {
 			return errors.New("setup.exec entries cannot be empty")
 		}
 	}
 	return nil
 }
 
+func validateMustModifyAnyOf(groups []StringList) error {
+	for i, group := range groups {
+		if len(group) == 0 {
+			return fmt.Errorf("verify.must-modify-any-of[%d] cannot be empty", i)
+		}
+		for _, v := range group {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("verify.must-modify-any-of[%d] entries cannot be empty", i)
+			}
+		}
+	}
+	return nil
+}
+
 func validateMustModify(entries StringList) error {
 	// Allow empty slice.
 	for _, v := range entries {
@@ -262,6 +826,34 @@ func (s Scenario) PartialTestTargets() ([]TestTarget, error) {
 	return parseTestTargets("verify.partial-tests", s.Verify.PartialTests)
 }
 
+// MustFailTestTargets returns parsed verify.must-fail entries.
+func (s Scenario) MustFailTestTargets() ([]TestTarget, error) {
+	return parseTestTargets("verify.must-fail", s.Verify.MustFail)
+}
+
+// WithRunFilter returns entries with each one's -run pattern replaced by pattern (see verify --run). It's an error if an
+// entry's target can't be combined with -run (ex: a glob or package pattern).
+func WithRunFilter(field string, entries StringList, pattern string) (StringList, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("-run pattern cannot be empty")
+	}
+	if strings.ContainsAny(pattern, "'\r\n") {
+		return nil, fmt.Errorf("-run pattern %q cannot contain single quotes or newlines", pattern)
+	}
+	targets, err := parseTestTargets(field, entries)
+	if err != nil || len(targets) == 0 {
+		return nil, err
+	}
+	out := make(StringList, 0, len(targets))
+	for i, target := range targets {
+		if err := validateTestTarget(target.Target, true); err != nil {
+			return nil, fmt.Errorf("%s entry %q: %w", field, entries[i], err)
+		}
+		out = append(out, fmt.Sprintf("%s -run '%s'", target.Target, pattern))
+	}
+	return out, nil
+}
+
 func parseTestTargets(field string, entries StringList) ([]TestTarget, error) {
 	if len(entries) == 0 {
 		return nil, nil
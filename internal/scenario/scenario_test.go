@@ -1,10 +1,14 @@
 package scenario_test
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestScenarioTestTargets_Valid(t *testing.T) {
@@ -158,7 +162,7 @@ func TestValidate_EmptyExecEntries(t *testing.T) {
 		Classification: scenario.Classification{Type: "build-package"},
 		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
 		Setup: &scenario.SetupConfig{
-			Exec: scenario.StringList{"   "},
+			Exec: scenario.ExecSteps{{Cmd: "   "}},
 		},
 	}
 
@@ -166,3 +170,352 @@ func TestValidate_EmptyExecEntries(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "setup.exec entries cannot be empty")
 }
+
+func TestValidate_EmptyAgentSetupCommands(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	sc := &scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing", SetupCommands: scenario.StringList{"go mod download"}},
+	}
+	require.NoError(t, scenario.Validate(sc, t.TempDir()))
+
+	sc.Agent.SetupCommands = append(sc.Agent.SetupCommands, " ")
+	require.ErrorContains(t, scenario.Validate(sc, t.TempDir()), "agent.setup-commands entries cannot be empty")
+}
+
+func TestValidate_PartialMode(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+
+	for _, mode := range []string{"", scenario.PartialModePerTest, scenario.PartialModePerEntry} {
+		sc := base
+		sc.Verify.PartialMode = mode
+		require.NoError(t, scenario.Validate(&sc, t.TempDir()), mode)
+	}
+
+	sc := base
+	sc.Verify.PartialMode = "per-package"
+	err := scenario.Validate(&sc, t.TempDir())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "verify.partial-mode")
+}
+
+func TestValidate_RaceMode(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+
+	for _, mode := range []string{"", scenario.RaceModeOff, scenario.RaceModeWarn, scenario.RaceModeFail} {
+		sc := base
+		sc.Verify.RaceMode = mode
+		require.NoError(t, scenario.Validate(&sc, t.TempDir()), mode)
+	}
+
+	sc := base
+	sc.Verify.RaceMode = "on"
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), `verify.race-mode must be "off", "warn", or "fail", got "on"`)
+}
+
+func TestValidate_MustModifyAnyOf(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+
+	sc := base
+	sc.Verify.MustModifyAnyOf = []scenario.StringList{{"a.go", "b.go"}, {"docs/"}}
+	require.NoError(t, scenario.Validate(&sc, t.TempDir()))
+
+	sc.Verify.MustModifyAnyOf = []scenario.StringList{{"a.go"}, {}}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "verify.must-modify-any-of[1] cannot be empty")
+
+	sc.Verify.MustModifyAnyOf = []scenario.StringList{{"a.go", " "}}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "verify.must-modify-any-of[0] entries cannot be empty")
+}
+
+func TestValidate_AllowedGoVersions(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	sc := scenario.Scenario{
+		Name:              "demo",
+		Repo:              "github.com/example/repo",
+		Commit:            "1234567",
+		AllowedGoVersions: ">=1.21,<1.24",
+		Classification:    scenario.Classification{Type: "build-package"},
+		Agent:             scenario.AgentConfig{Instructions: "do the thing"},
+	}
+	require.NoError(t, scenario.Validate(&sc, t.TempDir()))
+
+	sc.AllowedGoVersions = ">=1.21,<latest"
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), `allowed-go-versions: invalid Go version "latest"`)
+}
+
+func TestValidate_GOOSGOARCH(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+
+	sc := base
+	sc.Verify.GOOS, sc.Verify.GOARCH = "windows", "arm64"
+	require.NoError(t, scenario.Validate(&sc, t.TempDir()))
+
+	sc = base
+	sc.Verify.GOOS = "win32"
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "verify.goos")
+
+	sc = base
+	sc.Verify.GOARCH = "x86_64"
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "verify.goarch")
+}
+
+func TestSecretEnvMasksValuesInJSON(t *testing.T) {
+	env := scenario.SecretEnv{"API_KEY": "sk-secret"}
+	data, err := json.Marshal(scenario.AgentConfig{Instructions: "x", Env: env})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "sk-secret")
+	require.Contains(t, string(data), `"API_KEY":"***"`)
+
+	require.NoError(t, env.Validate("agent.env"))
+	require.Error(t, scenario.SecretEnv{"1BAD": "v"}.Validate("agent.env"))
+}
+
+func TestValidate_CopyOverwrite(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	scenarioDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(scenarioDir, "extra.txt"), []byte("x"), 0o644))
+	noOverwrite := false
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+
+	sc := base
+	sc.Verify.Copy = []scenario.CopyStep{{From: "extra.txt", To: ".", Overwrite: &noOverwrite}}
+	require.NoError(t, scenario.Validate(&sc, scenarioDir))
+	require.False(t, sc.Verify.Copy[0].OverwriteEnabled())
+	require.True(t, scenario.CopyStep{From: "extra.txt", To: "."}.OverwriteEnabled())
+
+	sc = base
+	sc.Setup = &scenario.SetupConfig{Copy: []scenario.CopyStep{{From: "extra.txt", To: ".", Overwrite: &noOverwrite}}}
+	require.ErrorContains(t, scenario.Validate(&sc, scenarioDir), "setup.copy does not support overwrite")
+}
+
+func TestValidate_Stages(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	base := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Stages: []scenario.Stage{
+			{Name: "a", Instructions: "fix A", Verify: scenario.VerifyConfig{Tests: scenario.StringList{"./pkg -run TestA"}}},
+			{Name: "b", Instructions: "fix B", Verify: scenario.VerifyConfig{Tests: scenario.StringList{"./pkg"}}},
+		},
+	}
+
+	sc := base
+	require.NoError(t, scenario.Validate(&sc, t.TempDir()))
+	stages := sc.StageScenarios()
+	require.Len(t, stages, 2)
+	require.Equal(t, "fix B", stages[1].Agent.Instructions)
+	require.Equal(t, scenario.StringList{"./pkg"}, stages[1].Verify.Tests)
+	require.Empty(t, stages[1].Stages)
+	require.Nil(t, scenario.Scenario{}.StageScenarios())
+
+	sc = base
+	sc.Agent.Instructions = "do the thing"
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "agent.instructions can't be combined with stages")
+
+	sc = base
+	sc.Verify.Tests = scenario.StringList{"./pkg"}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "verify can't be combined with stages")
+
+	sc = base
+	sc.Stages = []scenario.Stage{base.Stages[0], base.Stages[0]}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), `duplicate stage name "a"`)
+
+	sc = base
+	sc.Stages = []scenario.Stage{{Name: "a", Instructions: "fix A", Verify: scenario.VerifyConfig{Tests: scenario.StringList{"/abs"}}}}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "stages[0] (a):")
+}
+
+func TestVerifyCommandUnmarshal(t *testing.T) {
+	var cfg scenario.VerifyConfig
+	raw := "commands:\n  - ./check.sh\n  - cmd: ./grade.sh\n    ok-exit: [0, 2]\n"
+	require.NoError(t, yaml.Unmarshal([]byte(raw), &cfg))
+	require.Equal(t, []scenario.VerifyCommand{
+		{Cmd: "./check.sh"},
+		{Cmd: "./grade.sh", OkExit: []int{0, 2}},
+	}, cfg.Commands)
+	require.True(t, cfg.Commands[0].Passes(0))
+	require.False(t, cfg.Commands[0].Passes(2))
+	require.True(t, cfg.Commands[1].Passes(2))
+	require.False(t, cfg.Commands[1].Passes(1))
+}
+
+func TestPartialTestsUnmarshal(t *testing.T) {
+	var cfg scenario.VerifyConfig
+	raw := "tests: ./a\npartial-tests:\n  - ./p\n  - test: ./q -run TestHard\n    weight: 3\n"
+	require.NoError(t, yaml.Unmarshal([]byte(raw), &cfg))
+	require.Equal(t, scenario.StringList{"./a"}, cfg.Tests)
+	require.Equal(t, scenario.StringList{"./p", "./q -run TestHard"}, cfg.PartialTests)
+	require.Equal(t, []float64{1, 3}, cfg.PartialWeights)
+	require.Equal(t, 1.0, cfg.PartialWeight(0))
+	require.Equal(t, 3.0, cfg.PartialWeight(1))
+
+	// Without weights, entries are plain strings (or one string) and PartialWeights stays nil.
+	cfg = scenario.VerifyConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte("partial-tests: ./p\n"), &cfg))
+	require.Equal(t, scenario.StringList{"./p"}, cfg.PartialTests)
+	require.Nil(t, cfg.PartialWeights)
+	require.Equal(t, 1.0, cfg.PartialWeight(0))
+
+	cfg = scenario.VerifyConfig{}
+	require.Error(t, yaml.Unmarshal([]byte("partial-tests:\n  - weight: 2\n"), &cfg))
+}
+
+func TestValidate_PartialWeights(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	sc := scenario.Scenario{
+		Name:           "demo",
+		Repo:           "github.com/example/repo",
+		Commit:         "1234567",
+		Classification: scenario.Classification{Type: "build-package"},
+		Agent:          scenario.AgentConfig{Instructions: "do the thing"},
+	}
+	sc.Verify.PartialTests = scenario.StringList{"./p", "./q"}
+	sc.Verify.PartialWeights = []float64{0, 2}
+	require.NoError(t, scenario.Validate(&sc, t.TempDir()))
+
+	sc.Verify.PartialWeights = []float64{1, -1}
+	err := scenario.Validate(&sc, t.TempDir())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `verify.partial-tests "./q": weight must be >= 0`)
+}
+
+func TestExecStepsUnmarshal(t *testing.T) {
+	var cfg scenario.SetupConfig
+	raw := "exec:\n  - make gen\n  - cmd: go test ./bug\n    expect-stdout-contains: FAIL\n"
+	require.NoError(t, yaml.Unmarshal([]byte(raw), &cfg))
+	require.Equal(t, scenario.ExecSteps{
+		{Cmd: "make gen"},
+		{Cmd: "go test ./bug", ExpectStdoutContains: "FAIL"},
+	}, cfg.Exec)
+
+	cfg = scenario.SetupConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte("exec: make gen\n"), &cfg))
+	require.Equal(t, scenario.ExecSteps{{Cmd: "make gen"}}, cfg.Exec)
+}
+
+func TestExpandMatrix(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	var base scenario.Scenario
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: fix {{.func}}
+repo: github.com/example/repo
+commit: "1234567"
+classification:
+  type: build-package
+matrix:
+  func: [Add, Sub]
+  pkg: [mathx]
+agent:
+  instructions: Fix {{.func}} in {{.pkg}}.
+verify:
+  tests:
+    - ./{{.pkg}} -run Test{{.func}}
+  partial-tests: ./{{.pkg}}
+`), &base))
+
+	instances, err := base.ExpandMatrix()
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	require.Equal(t, "func=Add,pkg=mathx", instances[0].ID())
+	require.Equal(t, "func=Sub,pkg=mathx", instances[1].ID())
+	sub := instances[1].Scenario
+	require.Equal(t, "fix Sub", sub.Name)
+	require.Equal(t, "Fix Sub in mathx.", sub.Agent.Instructions)
+	require.Equal(t, scenario.StringList{"./mathx -run TestSub"}, sub.Verify.Tests)
+	require.Equal(t, scenario.StringList{"./mathx"}, sub.Verify.PartialTests)
+	require.Nil(t, sub.Matrix)
+	// The base scenario is unchanged.
+	require.Equal(t, scenario.StringList{"./{{.pkg}} -run Test{{.func}}"}, base.Verify.Tests)
+	require.NoError(t, scenario.Validate(&base, t.TempDir()))
+
+	none, err := scenario.Scenario{}.ExpandMatrix()
+	require.NoError(t, err)
+	require.Nil(t, none)
+
+	sc := base
+	sc.Agent.Instructions = "Fix {{.fn}}."
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), `matrix (func=Add,pkg=mathx): agent.instructions:`)
+
+	sc = base
+	sc.Matrix = map[string][]string{"func": {"Add", "Add"}, "pkg": {"mathx"}}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), `matrix.func has duplicate value "Add"`)
+
+	sc = base
+	sc.Matrix = map[string][]string{"func": {"Add"}, "pkg": {}}
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "matrix.pkg needs at least one value")
+
+	sc = base
+	sc.Classification.Type = ""
+	require.ErrorContains(t, scenario.Validate(&sc, t.TempDir()), "matrix (func=Add,pkg=mathx): classification.type is required")
+}
+
+func TestWithRunFilter(t *testing.T) {
+	got, err := scenario.WithRunFilter("verify.tests", scenario.StringList{"./pkg", "./other -run TestOld", "pkg/a_test.go"}, "TestNew|TestX")
+	require.NoError(t, err)
+	require.Equal(t, scenario.StringList{"./pkg -run 'TestNew|TestX'", "./other -run 'TestNew|TestX'", "pkg/a_test.go -run 'TestNew|TestX'"}, got)
+
+	_, err = scenario.WithRunFilter("verify.tests", scenario.StringList{"pkg/*_test.go"}, "TestNew")
+	require.ErrorContains(t, err, `glob target "pkg/*_test.go" cannot be combined with -run`)
+	_, err = scenario.WithRunFilter("verify.tests", scenario.StringList{"./pkg"}, "it's")
+	require.ErrorContains(t, err, "cannot contain single quotes")
+	_, err = scenario.WithRunFilter("verify.tests", scenario.StringList{"./pkg"}, " ")
+	require.ErrorContains(t, err, "cannot be empty")
+}
+
+func TestCheckSinglePackage(t *testing.T) {
+	single := true
+	sc := scenario.Scenario{Classification: scenario.Classification{SinglePackage: &single}}
+	sc.Verify.Copy = []scenario.CopyStep{{From: "gab_test.go", To: "internal/foo/gab"}}
+	sc.Verify.Tests = scenario.StringList{"./internal/foo", "internal/foo -run TestX", "./internal/foo/gab", "internal/foo/*_test.go"}
+	sc.Verify.PartialTests = scenario.StringList{"./internal/foo/foo_test.go"}
+	require.NoError(t, scenario.CheckSinglePackage(&sc))
+
+	sc.Verify.PartialTests = scenario.StringList{"./internal/bar"}
+	require.EqualError(t, scenario.CheckSinglePackage(&sc), "classification.single-package is true, but tests span 2 packages: internal/bar, internal/foo")
+
+	sc.Verify.PartialTests = nil
+	sc.Verify.Tests = scenario.StringList{"./..."}
+	require.ErrorContains(t, scenario.CheckSinglePackage(&sc), `verify.tests target "./..." matches multiple packages`)
+
+	single = false
+	require.NoError(t, scenario.CheckSinglePackage(&sc))
+}
@@ -0,0 +1,196 @@
+// Package semver parses and compares semver-like versions, such as agent versions and Go toolchain versions.
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type parsedVersion struct {
+	major  int
+	minor  int
+	patch  int
+	pre    string
+	hasPre bool
+}
+
+// IsValid reports whether v is a MAJOR.MINOR.PATCH version, optionally with a "v" prefix, -prerelease, and +build.
+func IsValid(v string) bool {
+	_, ok := parseSemver(v)
+	return ok
+}
+
+// Compare returns -1, 0, or 1 as a is older than, the same as, or newer than b. A prerelease is older than its release;
+// build metadata is ignored. If either isn't valid, they're compared as strings.
+func Compare(a, b string) int {
+	pa, oka := parseSemver(a)
+	pb, okb := parseSemver(b)
+	if !oka || !okb {
+		return strings.Compare(a, b)
+	}
+	if pa.major != pb.major {
+		return cmpInt(pa.major, pb.major)
+	}
+	if pa.minor != pb.minor {
+		return cmpInt(pa.minor, pb.minor)
+	}
+	if pa.patch != pb.patch {
+		return cmpInt(pa.patch, pb.patch)
+	}
+	if pa.hasPre != pb.hasPre {
+		if pa.hasPre {
+			return -1
+		}
+		return 1
+	}
+	if !pa.hasPre {
+		return 0
+	}
+	return strings.Compare(pa.pre, pb.pre)
+}
+
+func parseSemver(raw string) (parsedVersion, bool) {
+	s := strings.TrimSpace(raw)
+	s = strings.TrimPrefix(s, "v")
+	if s == "" {
+		return parsedVersion{}, false
+	}
+	main := s
+	pre := ""
+	if idx := strings.IndexAny(s, "+-"); idx >= 0 {
+		main = s[:idx]
+		if s[idx] == '-' {
+			rest := s[idx+1:]
+			if plus := strings.Index(rest, "+"); plus >= 0 {
+				pre = rest[:plus]
+			} else {
+				pre = rest
+			}
+		}
+	}
+	parts := strings.Split(main, ".")
+	if len(parts) != 3 {
+		return parsedVersion{}, false
+	}
+	maj, err1 := strconv.Atoi(parts[0])
+	min, err2 := strconv.Atoi(parts[1])
+	pat, err3 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return parsedVersion{}, false
+	}
+	p := parsedVersion{major: maj, minor: min, patch: pat}
+	if strings.TrimSpace(pre) != "" {
+		p.pre = pre
+		p.hasPre = true
+	}
+	return p, true
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GoVersion converts a Go toolchain version (ex: "go1.22.3", "1.21", "go1.23rc1") to semver ("1.22.3", "1.21.0",
+// "1.23.0-rc1"). It returns false if raw isn't a Go version.
+func GoVersion(raw string) (string, bool) {
+	s := strings.TrimPrefix(strings.TrimSpace(raw), "go")
+	pre := ""
+	if idx := strings.IndexAny(s, "abcdefghijklmnopqrstuvwxyz"); idx >= 0 {
+		s, pre = s[:idx], s[idx:]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", false
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	v := strings.Join(parts, ".")
+	if pre != "" {
+		v += "-" + pre
+	}
+	if !IsValid(v) {
+		return "", false
+	}
+	return v, true
+}
+
+// Constraint is a comma-separated list of Go version bounds that must all hold (ex: ">=1.21,<1.24"). Each bound is an
+// operator (>=, >, <=, <, =) and a Go version; a bare version means =.
+type Constraint struct {
+	raw    string
+	bounds []bound
+}
+
+type bound struct {
+	op      string
+	version string // semver
+}
+
+// ParseGoConstraint parses a Go version constraint.
+func ParseGoConstraint(raw string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(raw)}
+	if c.raw == "" {
+		return c, errors.New("empty version constraint")
+	}
+	for _, part := range strings.Split(c.raw, ",") {
+		part = strings.TrimSpace(part)
+		op := "="
+		for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		v, ok := GoVersion(part)
+		if !ok {
+			return c, fmt.Errorf("invalid Go version %q in constraint %q", part, c.raw)
+		}
+		c.bounds = append(c.bounds, bound{op: op, version: v})
+	}
+	return c, nil
+}
+
+// Allows reports whether the Go version goVersion (ex: "go1.22.3") satisfies every bound. An unparseable version is not
+// allowed.
+func (c Constraint) Allows(goVersion string) bool {
+	v, ok := GoVersion(goVersion)
+	if !ok {
+		return false
+	}
+	for _, b := range c.bounds {
+		cmp := Compare(v, b.version)
+		var held bool
+		switch b.op {
+		case ">=":
+			held = cmp >= 0
+		case ">":
+			held = cmp > 0
+		case "<=":
+			held = cmp <= 0
+		case "<":
+			held = cmp < 0
+		default:
+			held = cmp == 0
+		}
+		if !held {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the constraint as written.
+func (c Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,56 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/semver"
+)
+
+func TestCompare(t *testing.T) {
+	require.True(t, semver.IsValid("v1.2.3"))
+	require.False(t, semver.IsValid("1.2"))
+	require.Equal(t, -1, semver.Compare("1.2.3", "1.10.0"))
+	require.Equal(t, 0, semver.Compare("v1.2.3", "1.2.3+build"))
+	require.Equal(t, -1, semver.Compare("1.2.3-rc1", "1.2.3"))
+	require.Equal(t, 1, semver.Compare("2.0.0", "1.9.9"))
+}
+
+func TestGoVersion(t *testing.T) {
+	for raw, want := range map[string]string{
+		"go1.22.3":  "1.22.3",
+		"1.21":      "1.21.0",
+		"go1.23rc1": "1.23.0-rc1",
+	} {
+		got, ok := semver.GoVersion(raw)
+		require.True(t, ok, raw)
+		require.Equal(t, want, got, raw)
+	}
+	for _, raw := range []string{"", "go1", "devel +abc", "1.2.3.4"} {
+		_, ok := semver.GoVersion(raw)
+		require.False(t, ok, raw)
+	}
+}
+
+func TestParseGoConstraint(t *testing.T) {
+	c, err := semver.ParseGoConstraint(">=1.21, <1.24")
+	require.NoError(t, err)
+	require.Equal(t, ">=1.21, <1.24", c.String())
+	require.True(t, c.Allows("go1.21.0"))
+	require.True(t, c.Allows("go1.23.9"))
+	require.False(t, c.Allows("go1.20.14"))
+	require.False(t, c.Allows("go1.24.0"))
+	require.False(t, c.Allows("go1.21rc2"))
+	require.False(t, c.Allows("devel"))
+
+	exact, err := semver.ParseGoConstraint("1.22.3")
+	require.NoError(t, err)
+	require.True(t, exact.Allows("go1.22.3"))
+	require.False(t, exact.Allows("go1.22.4"))
+
+	for _, raw := range []string{"", ">=", ">=1.21,", "~1.21"} {
+		_, err := semver.ParseGoConstraint(raw)
+		require.Error(t, err, raw)
+	}
+}
@@ -0,0 +1,75 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/output"
+)
+
+// cloneRetryDelays are the waits before each retry of a git clone that failed with a transient (network) error.
+var cloneRetryDelays = []time.Duration{2 * time.Second, 5 * time.Second}
+
+// transientCloneErrors are lowercased git error fragments for failures worth retrying: DNS, connection, and server
+// hiccups. Anything else (ex: auth failures, a missing repo) fails right away.
+var transientCloneErrors = []string{
+	"could not resolve host",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"connection refused",
+	"failed to connect",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"unexpected disconnect",
+	"rpc failed",
+	"temporarily unavailable",
+	"gnutls_handshake() failed",
+	"ssl_read",
+	"tls handshake timeout",
+	"requested url returned error: 429",
+	"requested url returned error: 500",
+	"requested url returned error: 502",
+	"requested url returned error: 503",
+	"requested url returned error: 504",
+}
+
+// cloneRepo clones repoURL into targetDir. A transient failure is retried after each of cloneRetryDelays, removing the
+// partial clone first; a fatal one (or the last attempt's) is returned with git's output.
+func cloneRepo(ctx context.Context, printer *output.Printer, repoURL, targetDir string) error {
+	for attempt := 0; ; attempt++ {
+		out, err := printer.RunCommand(ctx, "", "git", "clone", repoURL, targetDir)
+		if err == nil {
+			return nil
+		}
+		err = fmt.Errorf("git clone failed: %w", err)
+		if !isTransientCloneError(string(out)) || attempt >= len(cloneRetryDelays) || ctx.Err() != nil {
+			return err
+		}
+		if err := os.RemoveAll(targetDir); err != nil {
+			return err
+		}
+		delay := cloneRetryDelays[attempt]
+		if err := printer.Appf("git clone hit a transient error; retrying in %s (attempt %d of %d)", delay, attempt+2, len(cloneRetryDelays)+1); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func isTransientCloneError(output string) bool {
+	lower := strings.ToLower(output)
+	for _, fragment := range transientCloneErrors {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/output"
+)
+
+func TestIsTransientCloneError(t *testing.T) {
+	require.True(t, isTransientCloneError("fatal: unable to access 'https://github.com/x/y/': Could not resolve host: github.com"))
+	require.True(t, isTransientCloneError("error: RPC failed; curl 56 GnuTLS recv error (-54)\nfatal: early EOF"))
+	require.True(t, isTransientCloneError("fatal: unable to access 'https://github.com/x/y/': The requested URL returned error: 503"))
+	require.False(t, isTransientCloneError("remote: Repository not found.\nfatal: repository 'https://github.com/x/y/' not found"))
+	require.False(t, isTransientCloneError("fatal: Authentication failed for 'https://github.com/x/y/'"))
+	require.False(t, isTransientCloneError(""))
+}
+
+func TestCloneRepoRetriesTransientErrors(t *testing.T) {
+	origDelays := cloneRetryDelays
+	t.Cleanup(func() { cloneRetryDelays = origDelays })
+	cloneRetryDelays = []time.Duration{0, 0}
+
+	source := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", source}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	t.Run("transient", func(t *testing.T) {
+		countFile := installFlakyGit(t, 1, "fatal: unable to access: Could not resolve host: example.com")
+		target := filepath.Join(t.TempDir(), "clone")
+		require.NoError(t, cloneRepo(context.Background(), output.NewPrinter(io.Discard), source, target))
+		require.Equal(t, "2", readCount(t, countFile))
+		require.NoFileExists(t, filepath.Join(target, "partial.txt"))
+		require.DirExists(t, filepath.Join(target, ".git"))
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		countFile := installFlakyGit(t, 5, "fatal: the remote end hung up unexpectedly")
+		target := filepath.Join(t.TempDir(), "clone")
+		err := cloneRepo(context.Background(), output.NewPrinter(io.Discard), source, target)
+		require.ErrorContains(t, err, "git clone failed")
+		require.Equal(t, "3", readCount(t, countFile))
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		countFile := installFlakyGit(t, 1, "fatal: Authentication failed for 'https://example.com/x/y/'")
+		target := filepath.Join(t.TempDir(), "clone")
+		err := cloneRepo(context.Background(), output.NewPrinter(io.Discard), source, target)
+		require.ErrorContains(t, err, "git clone failed")
+		require.Equal(t, "1", readCount(t, countFile))
+	})
+}
+
+// installFlakyGit puts a git on PATH whose first failures clones leave a partial target dir behind and fail with
+// errOutput; everything else goes to the real git. It returns the file counting clone attempts.
+func installFlakyGit(t *testing.T, failures int, errOutput string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub git is a shell script")
+	}
+	realGit, err := exec.LookPath("git")
+	require.NoError(t, err)
+	binDir := t.TempDir()
+	countFile := filepath.Join(t.TempDir(), "clones")
+	script := fmt.Sprintf(`#!/bin/sh
+if [ "$1" = clone ]; then
+	n=$(cat %[1]q 2>/dev/null || echo 0)
+	echo $((n + 1)) > %[1]q
+	if [ "$n" -lt %[2]d ]; then
+		mkdir -p "$3" && echo partial > "$3/partial.txt"
+		echo %[3]q >&2
+		exit 128
+	fi
+fi
+exec %[4]q "$@"
+`, countFile, failures, errOutput, realGit)
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "git"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return countFile
+}
+
+func readCount(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(data))
+}
@@ -2,14 +2,19 @@ package setup
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/codalotl/goagentbench/internal/fsutil"
 	"github.com/codalotl/goagentbench/internal/output"
 	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
 	"github.com/codalotl/goagentbench/internal/workspace"
 )
 
@@ -19,6 +24,10 @@ func Run(ctx context.Context, printer *output.Printer, scenarioName, workspacePa
 	if err := scenario.Validate(sc, scenarioDir); err != nil {
 		return err
 	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git not found on PATH; install git")
+	}
+	setupStart := time.Now()
 	targetDir := workspace.WorkspaceScenarioDir(workspacePath, scenarioName)
 	if err := os.RemoveAll(targetDir); err != nil {
 		return err
@@ -30,8 +39,8 @@ func Run(ctx context.Context, printer *output.Printer, scenarioName, workspacePa
 	if err := printer.Appf("Cloning %s into %s", repoURL, targetDir); err != nil {
 		return err
 	}
-	if _, err := printer.RunCommand(ctx, "", "git", "clone", repoURL, targetDir); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+	if err := cloneRepo(ctx, printer, repoURL, targetDir); err != nil {
+		return err
 	}
 	if err := printer.Appf("Checking out %s", sc.Commit); err != nil {
 		return err
@@ -58,24 +67,58 @@ func Run(ctx context.Context, printer *output.Printer, scenarioName, workspacePa
 			}
 		}
 		for _, execStep := range sc.Setup.Exec {
-			cmd := strings.TrimSpace(execStep)
-			if cmd == "" {
-				return fmt.Errorf("setup.exec entries cannot be empty")
-			}
-			if err := printer.Appf("Running setup exec: %s", cmd); err != nil {
+			if err := runExecStep(ctx, printer, targetDir, execStep); err != nil {
 				return err
 			}
-			if _, err := printer.RunCommand(ctx, targetDir, "sh", "-c", cmd); err != nil {
-				return fmt.Errorf("setup exec %q failed: %w", cmd, err)
-			}
 		}
 	}
+	if err := writeSetupMeta(targetDir, setupStart); err != nil {
+		return err
+	}
 	if err := printer.App("Setup complete."); err != nil {
 		return err
 	}
 	return nil
 }
 
+// writeSetupMeta records how long setup took in .setup-meta.json, so verify can fold it into the report.
+func writeSetupMeta(targetDir string, start time.Time) error {
+	meta := types.SetupMeta{
+		StartedAt:    start,
+		SetupSeconds: time.Since(start).Seconds(),
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(targetDir, ".setup-meta.json"), data, 0o644)
+}
+
+// runExecStep runs a setup.exec command in targetDir, checking its stdout against ExpectStdoutContains if set.
+func runExecStep(ctx context.Context, printer *output.Printer, targetDir string, step scenario.ExecStep) error {
+	cmd := strings.TrimSpace(step.Cmd)
+	if cmd == "" {
+		return fmt.Errorf("setup.exec entries cannot be empty")
+	}
+	if err := printer.Appf("Running setup exec: %s", cmd); err != nil {
+		return err
+	}
+	if step.ExpectStdoutContains == "" {
+		if _, err := printer.RunCommand(ctx, targetDir, "sh", "-c", cmd); err != nil {
+			return fmt.Errorf("setup exec %q failed: %w", cmd, err)
+		}
+		return nil
+	}
+	out, err := printer.RunCommandStreamingSplit(ctx, targetDir, nil, "sh", "-c", cmd)
+	if err != nil {
+		return fmt.Errorf("setup exec %q failed: %w", cmd, err)
+	}
+	if !strings.Contains(string(out.Stdout), step.ExpectStdoutContains) {
+		return fmt.Errorf("setup exec %q: stdout does not contain %q", cmd, step.ExpectStdoutContains)
+	}
+	return nil
+}
+
 func applyCopy(targetDir, scenarioDir string, step scenario.CopyStep) error {
 	src := filepath.Join(scenarioDir, step.From)
 	dst, err := fsutil.SafeJoin(targetDir, step.To)
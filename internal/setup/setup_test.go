@@ -2,6 +2,7 @@ package setup_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"os/exec"
@@ -12,6 +13,7 @@ import (
 	"github.com/codalotl/goagentbench/internal/output"
 	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/codalotl/goagentbench/internal/setup"
+	"github.com/codalotl/goagentbench/internal/types"
 	"github.com/codalotl/goagentbench/internal/workspace"
 	"github.com/stretchr/testify/require"
 )
@@ -192,9 +194,9 @@ func TestRun_ExecSteps(t *testing.T) {
 		},
 		Setup: &scenario.SetupConfig{
 			Patch: scenario.StringList{"single.patch"},
-			Exec: scenario.StringList{
-				`grep -q "Patched content" file.txt`,
-				"echo exec-ran > exec.log",
+			Exec: scenario.ExecSteps{
+				{Cmd: `grep -q "Patched content" file.txt`},
+				{Cmd: "echo exec-ran > exec.log"},
 			},
 		},
 	}
@@ -208,6 +210,11 @@ func TestRun_ExecSteps(t *testing.T) {
 	require.Equal(t, "Patched content\n", content)
 	execLog := readFile(t, filepath.Join(targetDir, "exec.log"))
 	require.Equal(t, "exec-ran\n", execLog)
+
+	var meta types.SetupMeta
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, filepath.Join(targetDir, ".setup-meta.json"))), &meta))
+	require.Greater(t, meta.SetupSeconds, 0.0)
+	require.False(t, meta.StartedAt.IsZero())
 }
 
 func TestRun_ExecStepFailure(t *testing.T) {
@@ -230,7 +237,7 @@ func TestRun_ExecStepFailure(t *testing.T) {
 			Instructions: "do stuff",
 		},
 		Setup: &scenario.SetupConfig{
-			Exec: scenario.StringList{"exit 7"},
+			Exec: scenario.ExecSteps{{Cmd: "exit 7"}},
 		},
 	}
 
@@ -240,6 +247,37 @@ func TestRun_ExecStepFailure(t *testing.T) {
 	require.Contains(t, err.Error(), "setup exec")
 }
 
+func TestRun_ExecStepExpectStdout(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv(workspace.EnvVarScenarioRoot, t.TempDir())
+	ctx := context.Background()
+
+	repoPath, commit := createRepo(t)
+	scenarioName := filepath.Join("setup", "exec_expect")
+
+	run := func(step scenario.ExecStep) error {
+		sc := &scenario.Scenario{
+			Name:           "test-scenario",
+			Repo:           repoPath,
+			Commit:         commit,
+			Classification: scenario.Classification{Type: "build-package"},
+			Agent:          scenario.AgentConfig{Instructions: "do stuff"},
+			Setup:          &scenario.SetupConfig{Exec: scenario.ExecSteps{step}},
+		}
+		workspacePath := filepath.Join(t.TempDir(), "workspace")
+		return setup.Run(ctx, output.NewPrinter(io.Discard), scenarioName, workspacePath, sc)
+	}
+
+	require.NoError(t, run(scenario.ExecStep{Cmd: "echo bug reproduced", ExpectStdoutContains: "reproduced"}))
+
+	err := run(scenario.ExecStep{Cmd: "echo all good", ExpectStdoutContains: "reproduced"})
+	require.ErrorContains(t, err, `stdout does not contain "reproduced"`)
+
+	// Only stdout counts.
+	err = run(scenario.ExecStep{Cmd: "echo reproduced >&2", ExpectStdoutContains: "reproduced"})
+	require.ErrorContains(t, err, "stdout does not contain")
+}
+
 func runGit(t *testing.T, dir string, args ...string) string {
 	t.Helper()
 	cmd := exec.Command("git", args...)
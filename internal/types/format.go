@@ -0,0 +1,25 @@
+package types
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatFloat rounds v to 2 decimal places and trims trailing zeros (ex: 0.6666 -> "0.67", 0.5 -> "0.5", 1 -> "1").
+// verify and report both display scores with it, so a run's partial score reads the same in each.
+func FormatFloat(v float64) string {
+	// Compensate for common binary floating-point representation issues so values
+	// like 1.005 reliably round to 1.01 at 2 decimal places.
+	rounded := math.Round((v+math.Copysign(1e-9, v))*100) / 100
+	if rounded == 0 {
+		return "0"
+	}
+	s := strconv.FormatFloat(rounded, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimRight(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -9,14 +9,22 @@ type SystemInfo struct {
 }
 
 type RunStart struct {
-	RunID        string     `json:"run_id"`
-	Scenario     string     `json:"scenario"`
-	Workspace    string     `json:"workspace"`
-	Agent        string     `json:"agent"`
-	AgentVersion string     `json:"agent_version"`
-	Model        string     `json:"model,omitempty"`
-	StartedAt    time.Time  `json:"started_at"`
-	System       SystemInfo `json:"system"`
+	RunID          string     `json:"run_id"`
+	Scenario       string     `json:"scenario"`
+	Workspace      string     `json:"workspace"`
+	Agent          string     `json:"agent"`
+	AgentVersion   string     `json:"agent_version"`
+	Model          string     `json:"model,omitempty"`
+	ReasoningLevel string     `json:"reasoning_level,omitempty"` // effective reasoning level (after any --reasoning override)
+	StartedAt      time.Time  `json:"started_at"`
+	System         SystemInfo `json:"system"`
+}
+
+// SetupMeta is written to .setup-meta.json in the workspace by setup.
+type SetupMeta struct {
+	StartedAt time.Time `json:"started_at"`
+	// SetupSeconds is the wall time of clone, checkout, and setup copy/patch/exec steps.
+	SetupSeconds float64 `json:"setup_seconds"`
 }
 
 type TokenUsage struct {
@@ -35,19 +43,47 @@ type AgentMessage struct {
 }
 
 type RunProgress struct {
-	RunID           string     `json:"run_id"`
-	Scenario        string     `json:"scenario"`
-	Agent           string     `json:"agent"`
-	AgentVersion    string     `json:"agent_version"`
-	Model           string     `json:"model,omitempty"`
-	StartedAt       time.Time  `json:"started_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	Session         string     `json:"session,omitempty"`
-	EndedAt         *time.Time `json:"ended_at,omitempty"`
-	DurationSeconds float64    `json:"duration_seconds"`
-	TokenUsage      TokenUsage `json:"token_usage"`
-	Transcripts     []string   `json:"transcripts,omitempty"`
-	Notes           string     `json:"notes,omitempty"`
+	RunID          string `json:"run_id"`
+	Scenario       string `json:"scenario"`
+	Agent          string `json:"agent"`
+	AgentVersion   string `json:"agent_version"`
+	Model          string `json:"model,omitempty"`
+	ReasoningLevel string `json:"reasoning_level,omitempty"`
+	// ReasoningOverride is true when ReasoningLevel came from --reasoning rather than the LLM definition.
+	ReasoningOverride bool       `json:"reasoning_override,omitempty"`
+	StartedAt         time.Time  `json:"started_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	Session           string     `json:"session,omitempty"`
+	EndedAt           *time.Time `json:"ended_at,omitempty"`
+	DurationSeconds   float64    `json:"duration_seconds"`
+	// FirstOutputSeconds is the time from starting the agent to its first output, for the first turn (0 if unknown).
+	FirstOutputSeconds float64    `json:"first_output_seconds,omitempty"`
+	TokenUsage         TokenUsage `json:"token_usage"`
+	Transcripts        []string   `json:"transcripts,omitempty"`
+	// TranscriptBytes and TranscriptLines measure Transcripts, so the size survives after transcripts are dropped from
+	// the results.
+	TranscriptBytes int      `json:"transcript_bytes,omitempty"`
+	TranscriptLines int      `json:"transcript_lines,omitempty"`
+	Notes           string   `json:"notes,omitempty"`
+	NetworkHosts    []string `json:"network_hosts,omitempty"` // distinct hosts contacted, when agent.record-network is on
+	// Instructions are the first-turn instructions sent to the agent, including any base instructions.
+	Instructions string `json:"instructions,omitempty"`
+	// Stages are the outcomes of each completed stage of a staged scenario, in order.
+	Stages []StageResult `json:"stages,omitempty"`
+	// TokenBudgetExceeded is true if the run was stopped early by agent.token-budget.
+	TokenBudgetExceeded bool `json:"token_budget_exceeded,omitempty"`
+	// OverheadTokenUsage is the usage of a no-op turn run before the real run (run-agent --measure-overhead): the
+	// agent's fixed per-run cost. It's not included in TokenUsage.
+	OverheadTokenUsage *TokenUsage `json:"overhead_token_usage,omitempty"`
+}
+
+// StageResult is the verification outcome of one stage of a staged scenario.
+type StageResult struct {
+	Name         string       `json:"name"`
+	Success      bool         `json:"success"`
+	PartialScore *float64     `json:"partial_score,omitempty"`
+	Tests        []TestResult `json:"tests,omitempty"`
+	PartialTests []TestResult `json:"partial_tests,omitempty"`
 }
 
 type TestResult struct {
@@ -55,6 +91,24 @@ type TestResult struct {
 	Passed bool   `json:"passed"`
 	Output string `json:"output,omitempty"`
 	Error  string `json:"error,omitempty"`
+	// Command is the shell-quoted command line that ran (ex: "go test ./pkg -run Foo"), relative to the workspace.
+	Command string `json:"command,omitempty"`
+	// Attempts is how many times the entry ran, when verify.retries re-ran it. Flaky means it failed at least once
+	// before passing.
+	Attempts int  `json:"attempts,omitempty"`
+	Flaky    bool `json:"flaky,omitempty"`
+	// Races is how many data races the race detector reported in the entry's output (verify.race-mode warn or fail).
+	Races int `json:"races,omitempty"`
+}
+
+// Violation is one broken modification rule. Kind is the rule's verify key without the prefix ("no-modify",
+// "no-delete", "protect-tests", "must-modify", "must-modify-any-of", or "copy"), or "git-deleted" if the agent removed
+// the workspace's .git; Rule is the rule's entry (a must-modify-any-of group is comma-separated, and a copy rule is its
+// from); Path is the offending workspace file, empty for rules that nothing matched.
+type Violation struct {
+	Path string `json:"path,omitempty"`
+	Rule string `json:"rule,omitempty"`
+	Kind string `json:"kind"`
 }
 
 type VerificationReport struct {
@@ -68,6 +122,43 @@ type VerificationReport struct {
 	VerifiedAt   time.Time    `json:"verified_at"`
 	Success      bool         `json:"success"`
 	PartialScore *float64     `json:"partial_score,omitempty"`
-	Tests        []TestResult `json:"tests"`
-	PartialTests []TestResult `json:"partial_tests,omitempty"`
+	// LinesAdded and LinesDeleted measure the agent's diff vs the checked-out commit (untracked files count as added).
+	LinesAdded   *int `json:"lines_added,omitempty"`
+	LinesDeleted *int `json:"lines_deleted,omitempty"`
+	// Diff is the agent's unified diff vs the checked-out commit (untracked files as new files), if verify
+	// --capture-diff was given. Large diffs are truncated, ending with a "[diff truncated: ...]" line.
+	Diff string `json:"diff,omitempty"`
+	// GitStatus is the workspace's `git status --porcelain` (without run metadata files) before verify.copy, if verify
+	// --capture-status was given.
+	GitStatus string `json:"git_status,omitempty"`
+	// GoVersion is the Go toolchain tests ran with (ex: "go1.22.3"), if go reported it.
+	GoVersion string `json:"go_version,omitempty"`
+	// System is the machine the agent ran on, copied from .run-start.json (nil for runs without one).
+	System *SystemInfo `json:"system,omitempty"`
+	// ShuffleSeed is the go test -shuffle seed, if tests were shuffled.
+	ShuffleSeed *int64 `json:"shuffle_seed,omitempty"`
+	// Target is the verify.goos/goarch "goos/goarch", if set. BuildOnly means tests were compiled for it but not run.
+	Target    string `json:"target,omitempty"`
+	BuildOnly bool   `json:"build_only,omitempty"`
+	// AddedDeps lists direct go.mod requirements added by the agent, when verify.no-new-deps is on.
+	AddedDeps []string `json:"added_deps,omitempty"`
+	// Violations lists the broken modification rules, if any; the verify.modification-rules result's Output has them
+	// as text.
+	Violations []Violation `json:"violations,omitempty"`
+	// SetupSeconds is copied from the workspace's .setup-meta.json, if present.
+	SetupSeconds *float64 `json:"setup_seconds,omitempty"`
+	// RunFilter is the verify --run pattern that replaced the entries' -run patterns, if one was given.
+	RunFilter string `json:"run_filter,omitempty"`
+	// Stages holds per-stage outcomes for staged scenarios; Tests and PartialTests are then the final stage's. Success
+	// requires every stage to pass.
+	Stages       []StageResult `json:"stages,omitempty"`
+	Tests        []TestResult  `json:"tests"`
+	PartialTests []TestResult  `json:"partial_tests,omitempty"`
+	// Unverified marks a run recorded without verification (exec --no-verify): Success is false and Tests is empty, so
+	// report excludes it from success rates but still averages its cost, tokens, and time.
+	Unverified bool `json:"unverified,omitempty"`
+	// RaceMode is verify.race-mode, if tests ran with -race ("warn" or "fail"). Races is the total data races reported
+	// across Tests and PartialTests, counting an entry in both once.
+	RaceMode string `json:"race_mode,omitempty"`
+	Races    int    `json:"races,omitempty"`
 }
@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const modificationRulesTestName = "verify.modification-rules"
+
+// failureText is the message for failed result t: its Error, or its Output for verify.modification-rules, which lists
+// its problems there.
+func failureText(t types.TestResult) string {
+	if t.Name == modificationRulesTestName {
+		return strings.TrimSpace(t.Output)
+	}
+	return strings.TrimSpace(t.Error)
+}
+
+// goTestLocationRe matches the "file_test.go:12: message" lines that t.Error/t.Fatal print, indented under "--- FAIL".
+var goTestLocationRe = regexp.MustCompile(`^\s+([\w./-]+\.go):(\d+): (.*)$`)
+
+// modificationProblemRes extract the offending path from checkModificationRules problem strings.
+var modificationProblemRes = []*regexp.Regexp{
+	regexp.MustCompile(`^(.+) is blocked by verify\.no-modify$`),
+	regexp.MustCompile(`^(.+) in verify\.must-modify was not modified$`),
+	regexp.MustCompile(`^(.+) is protected by verify\.protect-tests$`),
+	regexp.MustCompile(`^(.+) already exists but verify\.copy from .+ does not overwrite$`),
+}
+
+// AnnotationsString returns GitHub Actions workflow commands (one "::error ...::message" line per failure) for the
+// failing checks and tests in report. File paths are relative to the scenario workspace.
+func AnnotationsString(report *types.VerificationReport) string {
+	if report == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, t := range report.Tests {
+		writeTestAnnotations(&b, t)
+	}
+	for _, t := range report.PartialTests {
+		writeTestAnnotations(&b, t)
+	}
+	return b.String()
+}
+
+func writeTestAnnotations(b *strings.Builder, t types.TestResult) {
+	if t.Passed {
+		return
+	}
+	if t.Name == modificationRulesTestName {
+		for _, problem := range strings.Split(t.Output, "\n") {
+			problem = strings.TrimSpace(problem)
+			if problem == "" {
+				continue
+			}
+			file := ""
+			for _, re := range modificationProblemRes {
+				if m := re.FindStringSubmatch(problem); m != nil {
+					file = m[1]
+					break
+				}
+			}
+			writeAnnotation(b, file, "", t.Name, problem)
+		}
+		return
+	}
+
+	pkgDir := testTargetDir(t.Name)
+	wrote := false
+	for _, line := range testOutputLines(t.Output) {
+		m := goTestLocationRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		file := m[1]
+		if pkgDir != "" && !strings.Contains(file, "/") {
+			file = path.Join(pkgDir, file)
+		}
+		writeAnnotation(b, file, m[2], t.Name, m[3])
+		wrote = true
+	}
+	if !wrote {
+		msg := strings.TrimSpace(t.Error)
+		if msg == "" {
+			msg = "failed"
+		}
+		writeAnnotation(b, "", "", t.Name, msg)
+	}
+}
+
+func writeAnnotation(b *strings.Builder, file, line, title, message string) {
+	var props []string
+	if file != "" {
+		props = append(props, "file="+escapeAnnotationProperty(file))
+	}
+	if line != "" {
+		props = append(props, "line="+line)
+	}
+	props = append(props, "title="+escapeAnnotationProperty(title))
+	fmt.Fprintf(b, "::error %s::%s\n", strings.Join(props, ","), escapeAnnotationData(message))
+}
+
+// testOutputLines splits go test output into lines, unwrapping the Output field of `go test -json` events.
+func testOutputLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "{") {
+			var ev struct {
+				Output string `json:"Output"`
+			}
+			if err := json.Unmarshal([]byte(line), &ev); err == nil {
+				lines = append(lines, strings.TrimRight(ev.Output, "\n"))
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// testTargetDir returns the workspace-relative package dir of a test entry like "./pkg -run TestX", or "" if the target
+// is not a single relative package.
+func testTargetDir(entry string) string {
+	entry = strings.TrimPrefix(strings.TrimSpace(entry), mustFailPrefix)
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(entry), "go test"))
+	if len(fields) == 0 {
+		return ""
+	}
+	target := fields[0]
+	if strings.HasPrefix(target, "-") || strings.Contains(target, "...") || path.IsAbs(target) {
+		return ""
+	}
+	dir := path.Clean(target)
+	if dir == "." || strings.HasPrefix(dir, "../") {
+		return ""
+	}
+	return dir
+}
+
+func escapeAnnotationData(s string) string {
+	r := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	return r.Replace(s)
+}
+
+func escapeAnnotationProperty(s string) string {
+	r := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	return r.Replace(s)
+}
@@ -0,0 +1,130 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// TestChange is one test whose outcome differs between a baseline report and a new one. Before or After is "" if the
+// test is only in one report; otherwise each is "PASS" or "FAIL".
+type TestChange struct {
+	Name   string
+	Before string
+	After  string
+}
+
+// BaselineDiff compares a new report against a known-good baseline (see verify --compare-baseline).
+type BaselineDiff struct {
+	Changes []TestChange
+	// BaselineScore and Score are the reports' PartialScore, if set.
+	BaselineScore *float64
+	Score         *float64
+}
+
+// ScoreChanged reports whether the partial score differs between the reports.
+func (d BaselineDiff) ScoreChanged() bool {
+	if d.BaselineScore == nil || d.Score == nil {
+		return (d.BaselineScore == nil) != (d.Score == nil)
+	}
+	return types.FormatFloat(*d.BaselineScore) != types.FormatFloat(*d.Score)
+}
+
+// LoadReport reads a verification report (ex: a .verify.json results file) from path.
+func LoadReport(path string) (*types.VerificationReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rep types.VerificationReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &rep, nil
+}
+
+// CompareBaseline diffs report's Tests and PartialTests against baseline's by name. Changes are in report's order,
+// then tests that are only in baseline.
+func CompareBaseline(baseline, report *types.VerificationReport) BaselineDiff {
+	diff := BaselineDiff{BaselineScore: baseline.PartialScore, Score: report.PartialScore}
+	before := map[string]string{}
+	var beforeOrder []string
+	for _, t := range baselineTests(baseline) {
+		if _, ok := before[t.name]; !ok {
+			beforeOrder = append(beforeOrder, t.name)
+		}
+		before[t.name] = t.status
+	}
+	seen := map[string]bool{}
+	for _, t := range baselineTests(report) {
+		if seen[t.name] {
+			continue
+		}
+		seen[t.name] = true
+		if before[t.name] != t.status {
+			diff.Changes = append(diff.Changes, TestChange{Name: t.name, Before: before[t.name], After: t.status})
+		}
+	}
+	for _, name := range beforeOrder {
+		if !seen[name] {
+			diff.Changes = append(diff.Changes, TestChange{Name: name, Before: before[name]})
+		}
+	}
+	return diff
+}
+
+type namedStatus struct {
+	name   string
+	status string
+}
+
+// baselineTests flattens report's tests, prefixing partial tests with "partial " as the summary does.
+func baselineTests(report *types.VerificationReport) []namedStatus {
+	var out []namedStatus
+	add := func(prefix string, tests []types.TestResult) {
+		for _, t := range tests {
+			status := "FAIL"
+			if t.Passed {
+				status = "PASS"
+			}
+			out = append(out, namedStatus{name: prefix + t.Name, status: status})
+		}
+	}
+	add("", report.Tests)
+	add("partial ", report.PartialTests)
+	return out
+}
+
+// String returns a human-readable listing of the changes.
+func (d BaselineDiff) String() string {
+	builder := strings.Builder{}
+	if len(d.Changes) == 0 && !d.ScoreChanged() {
+		builder.WriteString("Baseline: no test outcomes changed\n")
+		return builder.String()
+	}
+	builder.WriteString(fmt.Sprintf("Baseline: %d test outcome(s) changed\n", len(d.Changes)))
+	for _, c := range d.Changes {
+		builder.WriteString(fmt.Sprintf("- %s: %s -> %s\n", c.Name, baselineStatus(c.Before), baselineStatus(c.After)))
+	}
+	if d.ScoreChanged() {
+		builder.WriteString(fmt.Sprintf("Partial score: %s -> %s\n", baselineScore(d.BaselineScore), baselineScore(d.Score)))
+	}
+	return builder.String()
+}
+
+func baselineStatus(s string) string {
+	if s == "" {
+		return "(missing)"
+	}
+	return s
+}
+
+func baselineScore(score *float64) string {
+	if score == nil {
+		return "(none)"
+	}
+	return types.FormatFloat(*score)
+}
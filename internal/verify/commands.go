@@ -0,0 +1,67 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// runVerifyCommands runs each verify.commands entry with sh in workdir. An entry passes if its exit code is one of its
+// ok-exit codes (default: 0).
+func runVerifyCommands(ctx context.Context, workdir string, cmds []scenario.VerifyCommand, printer *output.Printer) []types.TestResult {
+	results := make([]types.TestResult, 0, len(cmds))
+	for _, c := range cmds {
+		cmd := strings.TrimSpace(c.Cmd)
+		if timedOut(ctx) {
+			results = append(results, notRunResult(ctx, cmd))
+			continue
+		}
+		out, err := runStreaming(ctx, printer, workdir, "sh", "-c", cmd)
+		result := types.TestResult{
+			Name:    cmd,
+			Output:  string(out),
+			Command: cmd,
+		}
+		code := 0
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr):
+			code = exitErr.ExitCode()
+		default:
+			result.Error = err.Error()
+			markIfTimedOut(ctx, &result)
+			results = append(results, result)
+			continue
+		}
+		result.Passed = c.Passes(code)
+		if !result.Passed {
+			result.Error = fmt.Sprintf("exit status %d (ok: %s)", code, okExitString(c))
+		}
+		if timedOut(ctx) {
+			// A killed command's exit code says nothing, even if ok-exit lists it.
+			result.Passed = false
+			markIfTimedOut(ctx, &result)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// okExitString lists the exit codes that pass c, for failure messages.
+func okExitString(c scenario.VerifyCommand) string {
+	if len(c.OkExit) == 0 {
+		return "0"
+	}
+	codes := make([]string, len(c.OkExit))
+	for i, code := range c.OkExit {
+		codes[i] = fmt.Sprint(code)
+	}
+	return strings.Join(codes, ", ")
+}
@@ -0,0 +1,158 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// diffStat is the size of the agent's change relative to the workspace's HEAD commit.
+type diffStat struct {
+	Added   int
+	Deleted int
+}
+
+// computeDiffStat counts lines added/deleted in tracked files (git diff --numstat HEAD) plus the lines of untracked files.
+// Root dotfiles (run metadata) and binary files are ignored.
+func computeDiffStat(workspaceDir string) (diffStat, error) {
+	var stat diffStat
+	out, err := runInWorkspace(workspaceDir, "git", "diff", "--numstat", "HEAD")
+	if err != nil {
+		return stat, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) != 3 || len(filterIgnoredChanges([]string{fields[2]})) == 0 {
+			continue
+		}
+		added, errA := strconv.Atoi(fields[0])
+		deleted, errD := strconv.Atoi(fields[1])
+		if errA != nil || errD != nil {
+			// Binary files report "-".
+			continue
+		}
+		stat.Added += added
+		stat.Deleted += deleted
+	}
+	if err := scanner.Err(); err != nil {
+		return stat, err
+	}
+
+	out, err = runInWorkspace(workspaceDir, "git", "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return stat, err
+	}
+	var untracked []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			untracked = append(untracked, line)
+		}
+	}
+	for _, p := range filterIgnoredChanges(untracked) {
+		data, err := os.ReadFile(filepath.Join(workspaceDir, p))
+		if err != nil {
+			return stat, err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		stat.Added += countLines(data)
+	}
+	return stat, nil
+}
+
+func countLines(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	n := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// maxCapturedDiffBytes caps the diff verify --capture-diff stores in the report.
+const maxCapturedDiffBytes = 256 << 10
+
+// captureDiff returns the agent's unified diff vs HEAD: git diff for tracked files plus untracked files as new files.
+// Root dotfiles (run metadata) are left out. A diff over maxBytes is cut at a line boundary, ending with a truncation
+// marker.
+func captureDiff(workspaceDir string, maxBytes int) (string, error) {
+	changes, _, err := listWorkspaceChanges(workspaceDir)
+	if err != nil {
+		return "", err
+	}
+	changes = filterIgnoredChanges(changes)
+	out, err := runInWorkspace(workspaceDir, "git", "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+	untracked := map[string]bool{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			untracked[line] = true
+		}
+	}
+	var tracked, added []string
+	for _, p := range changes {
+		if untracked[p] {
+			added = append(added, p)
+		} else {
+			tracked = append(tracked, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	if len(tracked) > 0 {
+		out, err := runInWorkspace(workspaceDir, append([]string{"git", "--literal-pathspecs", "diff", "HEAD", "--"}, tracked...)...)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(out)
+	}
+	for _, p := range added {
+		out, err := diffNewFile(workspaceDir, p)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(out)
+	}
+	return truncateDiff(buf.String(), maxBytes), nil
+}
+
+// diffNewFile returns the diff that adds the untracked file rel. git diff --no-index exits 1 when files differ, which
+// they always do here.
+func diffNewFile(workspaceDir, rel string) ([]byte, error) {
+	cmd := exec.Command("git", "diff", "--no-index", "--", os.DevNull, rel)
+	cmd.Dir = workspaceDir
+	out, err := cmd.Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() == 1 {
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git diff --no-index %s: %w", rel, err)
+	}
+	return out, nil
+}
+
+// truncateDiff cuts diff to at most maxBytes (backing up to a line boundary) and appends a marker saying how much was
+// kept.
+func truncateDiff(diff string, maxBytes int) string {
+	if len(diff) <= maxBytes {
+		return diff
+	}
+	cut := diff[:maxBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i >= 0 {
+		cut = cut[:i+1]
+	}
+	return cut + fmt.Sprintf("[diff truncated: showing %d of %d bytes]\n", len(cut), len(diff))
+}
@@ -0,0 +1,45 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/fsutil"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const mustBeExecutableTestName = "verify.must-be-executable"
+
+// checkMustBeExecutable fails unless every verify.must-be-executable path is a regular file in the workspace with at
+// least one execute bit set. Mode-only changes don't show up as content changes, so this checks the file itself.
+func checkMustBeExecutable(sc *scenario.Scenario, workspaceDir string) (types.TestResult, error) {
+	result := types.TestResult{Name: mustBeExecutableTestName}
+	var problems []string
+	for _, entry := range sc.Verify.MustBeExecutable {
+		rel := strings.TrimSpace(entry)
+		path, err := fsutil.SafeJoin(workspaceDir, rel)
+		if err != nil {
+			return result, err
+		}
+		info, err := os.Stat(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			problems = append(problems, fmt.Sprintf("%s does not exist", rel))
+		case err != nil:
+			return result, err
+		case !info.Mode().IsRegular():
+			problems = append(problems, fmt.Sprintf("%s is not a regular file", rel))
+		case info.Mode().Perm()&0o111 == 0:
+			problems = append(problems, fmt.Sprintf("%s is not executable (mode %s)", rel, info.Mode().Perm()))
+		}
+	}
+	if len(problems) > 0 {
+		result.Error = strings.Join(problems, "\n")
+		return result, nil
+	}
+	result.Passed = true
+	return result, nil
+}
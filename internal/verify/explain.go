@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/workspace"
+)
+
+// ExplainTargets prints how each verify.tests, partial-tests, and must-fail entry resolves, without running anything
+// (see verify --explain-targets): the raw entry, the parsed TestTarget, and the `go test` command verify would run in
+// the workspace. Targets are normalized against the workspace as it is now (ex: "pkg" becomes "./pkg" only if the
+// workspace has a pkg dir), so the output is most accurate after setup. opts.RunFilter and shuffle options apply as
+// they would in Run; a random shuffle seed is picked fresh, so it won't match a later run's.
+func ExplainTargets(printer *output.Printer, opts Options, sc *scenario.Scenario) error {
+	if printer == nil {
+		printer = output.NewPrinter(os.Stdout)
+	}
+	workspaceDir := workspace.WorkspaceScenarioDir(opts.WorkspacePath, opts.ScenarioName)
+	if stageScenarios := sc.StageScenarios(); len(stageScenarios) > 0 {
+		sc = stageScenarios[len(stageScenarios)-1]
+	}
+	if opts.RunFilter != "" {
+		filtered, err := withRunFilter(sc, opts.RunFilter)
+		if err != nil {
+			return err
+		}
+		sc = filtered
+	}
+	if _, err := os.Stat(workspaceDir); err != nil {
+		if err := printer.Appf("Warning: workspace not found at %s; targets are shown without ./ normalization.", workspaceDir); err != nil {
+			return err
+		}
+	}
+	_, buildOnly := verifyTarget(sc)
+	gt, _ := newGoTestConfig(sc, shuffleSeedFor(opts, sc), buildOnly)
+	fields := []struct {
+		name      string
+		entries   scenario.StringList
+		targets   func() ([]scenario.TestTarget, error)
+		forceJSON bool
+	}{
+		{"verify.tests", sc.Verify.Tests, sc.TestTargets, sc.Verify.NoStdoutNoise},
+		{"verify.partial-tests", sc.Verify.PartialTests, sc.PartialTestTargets, true},
+		{"verify.must-fail", sc.Verify.MustFail, sc.MustFailTestTargets, true},
+	}
+	explained := 0
+	for _, f := range fields {
+		targets, err := f.targets()
+		if err != nil {
+			return err
+		}
+		for i, entry := range f.entries {
+			args, err := parseTestArgs(workspaceDir, entry)
+			if err != nil {
+				return fmt.Errorf("%s entry %q: %w", f.name, entry, err)
+			}
+			if err := printer.Appf("%s[%d]: %s\n  target: %s\n  run:    %s\n  go test: %s",
+				f.name, i, entry, targets[i].Target, explainRun(targets[i].Run), commandLine(gt.env, "go", goTestArgs(args, f.forceJSON, gt))); err != nil {
+				return err
+			}
+			explained++
+		}
+	}
+	if explained == 0 {
+		return printer.App("No verify.tests, partial-tests, or must-fail entries.")
+	}
+	return printer.Appf("Commands run in %s.", workspaceDir)
+}
+
+func explainRun(run string) string {
+	if run == "" {
+		return "(all tests)"
+	}
+	return run
+}
@@ -0,0 +1,155 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const generateTestName = "verify.generate"
+
+// checkGenerate runs `go generate ./...` in a throwaway copy of the workspace (without .git) and fails if it changed,
+// added, or removed any file. Running in a copy keeps the workspace, and so change detection, untouched.
+func checkGenerate(ctx context.Context, workspaceDir string, printer *output.Printer) (types.TestResult, error) {
+	result := types.TestResult{Name: generateTestName, Command: "go generate ./..."}
+	tmp, err := os.MkdirTemp("", "goagentbench-generate-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyTree(workspaceDir, tmp); err != nil {
+		return result, fmt.Errorf("copy workspace for go generate: %w", err)
+	}
+
+	out, runErr := runStreaming(ctx, printer, tmp, "go", "generate", "./...")
+	result.Output = string(out)
+	if runErr != nil {
+		result.Error = fmt.Sprintf("go generate failed: %v", runErr)
+		return result, nil
+	}
+	changed, err := diffTrees(workspaceDir, tmp)
+	if err != nil {
+		return result, err
+	}
+	if len(changed) > 0 {
+		result.Error = "go generate would change: " + strings.Join(changed, ", ")
+		return result, nil
+	}
+	result.Passed = true
+	return result, nil
+}
+
+// copyTree copies the files of src into dst (which must exist), preserving modes and symlinks and skipping .git.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm()|0o700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// diffTrees returns the slash-separated paths of regular files (outside .git) that differ between dirs a and b,
+// including files only in one of them, sorted.
+func diffTrees(a, b string) ([]string, error) {
+	filesA, err := regularFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := regularFiles(b)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for rel := range filesA {
+		if !filesB[rel] {
+			changed = append(changed, rel+" (deleted)")
+			continue
+		}
+		same, err := sameContents(filepath.Join(a, rel), filepath.Join(b, rel))
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changed = append(changed, rel)
+		}
+	}
+	for rel := range filesB {
+		if !filesA[rel] {
+			changed = append(changed, rel+" (added)")
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func regularFiles(root string) (map[string]bool, error) {
+	files := map[string]bool{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
+
+func sameContents(a, b string) (bool, error) {
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
@@ -0,0 +1,43 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/semver"
+)
+
+// goToolchainVersion returns the version of the Go toolchain that runs in workspaceDir (ex: "go1.22.3"), which honors
+// any go.mod toolchain selection. It returns "" if go can't report it. Anything after the version (ex: the
+// " X:nocoverageredesign" a toolchain built with GOEXPERIMENT reports) is dropped, so the result parses as a version.
+func goToolchainVersion(ctx context.Context, workspaceDir string) string {
+	cmd := exec.CommandContext(ctx, "go", "env", "GOVERSION")
+	cmd.Dir = workspaceDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	return version
+}
+
+// checkAllowedGoVersions returns an error if sc.AllowedGoVersions is set and goVersion is outside it (or unknown).
+func checkAllowedGoVersions(sc *scenario.Scenario, goVersion string) error {
+	if sc.AllowedGoVersions == "" {
+		return nil
+	}
+	constraint, err := semver.ParseGoConstraint(sc.AllowedGoVersions)
+	if err != nil {
+		return fmt.Errorf("allowed-go-versions: %w", err)
+	}
+	if goVersion == "" {
+		return fmt.Errorf("can't determine the go version to check allowed-go-versions %q; not verifying", constraint)
+	}
+	if !constraint.Allows(goVersion) {
+		return fmt.Errorf("toolchain %s is outside allowed-go-versions %q; not verifying", goVersion, constraint)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package verify
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes report as a JUnit XML <testsuites> document. verify.tests (including check results like
+// verify.modification-rules) form one suite; partial-tests form another, with one testcase per go test result parsed
+// from the JSON output (falling back to one testcase per entry).
+func WriteJUnit(w io.Writer, report *types.VerificationReport) error {
+	doc := junitTestSuites{Name: report.Scenario}
+	timestamp := ""
+	if !report.VerifiedAt.IsZero() {
+		timestamp = report.VerifiedAt.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	suite := junitTestSuite{Name: report.Scenario, Timestamp: timestamp}
+	for _, t := range report.Tests {
+		suite.Cases = append(suite.Cases, junitCaseFromResult("verify", t))
+	}
+	doc.Suites = append(doc.Suites, suite)
+
+	if len(report.PartialTests) > 0 {
+		partial := junitTestSuite{Name: report.Scenario + " (partial)", Timestamp: timestamp}
+		for _, t := range report.PartialTests {
+			cases := junitCasesFromJSON(t)
+			if len(cases) == 0 {
+				cases = []junitTestCase{junitCaseFromResult("verify.partial", t)}
+			}
+			partial.Cases = append(partial.Cases, cases...)
+		}
+		doc.Suites = append(doc.Suites, partial)
+	}
+
+	for i := range doc.Suites {
+		s := &doc.Suites[i]
+		s.Tests = len(s.Cases)
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			}
+			if c.Skipped != nil {
+				s.Skipped++
+			}
+		}
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitCaseFromResult(classname string, t types.TestResult) junitTestCase {
+	c := junitTestCase{Name: t.Name, Classname: classname}
+	if !t.Passed {
+		msg := failureText(t)
+		if msg == "" {
+			msg = "failed"
+		}
+		if t.Name == modificationRulesTestName {
+			first, _, _ := strings.Cut(msg, "\n")
+			c.Failure = &junitFailure{Message: first, Body: t.Output}
+		} else if first, _, _ := strings.Cut(msg, "\n"); first != msg {
+			c.Failure = &junitFailure{Message: first, Body: msg + "\n" + t.Output}
+		} else {
+			c.Failure = &junitFailure{Message: msg, Body: t.Output}
+		}
+	} else if t.Output != "" {
+		c.SystemOut = t.Output
+	}
+	return c
+}
+
+// junitCasesFromJSON builds one testcase per test from `go test -json` output, or nil if the output isn't JSON.
+func junitCasesFromJSON(t types.TestResult) []junitTestCase {
+	type event struct {
+		Action  string `json:"Action"`
+		Package string `json:"Package"`
+		Test    string `json:"Test"`
+		Output  string `json:"Output"`
+	}
+	outputs := map[string]*strings.Builder{}
+	var cases []junitTestCase
+	scanner := bufio.NewScanner(strings.NewReader(t.Output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Test == "" {
+			continue
+		}
+		key := ev.Package + "\x00" + ev.Test
+		switch ev.Action {
+		case "output":
+			b := outputs[key]
+			if b == nil {
+				b = &strings.Builder{}
+				outputs[key] = b
+			}
+			b.WriteString(ev.Output)
+		case "pass", "fail", "skip":
+			classname := ev.Package
+			if classname == "" {
+				classname = t.Name
+			}
+			c := junitTestCase{Name: ev.Test, Classname: classname}
+			out := ""
+			if b := outputs[key]; b != nil {
+				out = b.String()
+			}
+			switch ev.Action {
+			case "fail":
+				c.Failure = &junitFailure{Message: "test failed", Body: out}
+			case "skip":
+				c.Skipped = &struct{}{}
+			}
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+func writeJUnitFile(path string, report *types.VerificationReport) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteJUnit(f, report); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
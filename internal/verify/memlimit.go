@@ -0,0 +1,28 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// oomMarkers are Go runtime messages printed when a test binary can't get more memory.
+var oomMarkers = []string{"fatal error: out of memory", "failed to reserve", "cannot allocate memory"}
+
+// markIfOutOfMemory notes on a failed result that the test binary ran out of memory under verify.memory-limit-mb.
+func markIfOutOfMemory(res *types.TestResult, limitMB int) {
+	if res.Passed || limitMB <= 0 {
+		return
+	}
+	for _, marker := range oomMarkers {
+		if strings.Contains(res.Output, marker) {
+			msg := fmt.Sprintf("out of memory: test exceeded verify.memory-limit-mb (%d MB)", limitMB)
+			if res.Error != "" {
+				msg += ": " + res.Error
+			}
+			res.Error = msg
+			return
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package verify
+
+import "fmt"
+
+// memoryLimitFlag returns the go test flag that runs each test binary under an address-space rlimit of limitMB: go test
+// -exec runs the binary via sh, which calls setrlimit (ulimit -v) before exec'ing it. Only the test binary is limited,
+// not the go command or the compiler.
+func memoryLimitFlag(limitMB int) (string, bool) {
+	return fmt.Sprintf(`-exec=sh -c 'ulimit -v %d && exec "$0" "$@"'`, limitMB*1024), true
+}
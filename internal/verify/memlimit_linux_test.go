@@ -0,0 +1,59 @@
+package verify_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/verify"
+)
+
+func TestRunMemoryLimit(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "memory-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "go.mod", "module example.com/m\n\ngo 1.21\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "add module")
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "small/small_test.go", "package small\n\nimport \"testing\"\n\nfunc TestSmall(t *testing.T) {}\n")
+	writeFile(t, repo, "hog/hog_test.go", `package hog
+
+import "testing"
+
+var keep [][]byte
+
+func TestHog(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		keep = append(keep, make([]byte, 64<<20))
+	}
+}
+`)
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./small", "./hog"}
+	sc.Verify.MemoryLimitMB = 1024
+
+	res, err := verify.Run(context.Background(), verify.Options{
+		ScenarioName:  scenarioName,
+		WorkspacePath: workspaceRoot,
+		RootPath:      workspaceRoot,
+		OnlyReport:    true,
+		Printer:       output.NewPrinter(nil),
+	}, sc)
+	require.NoError(t, err)
+
+	report := res.Report
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 2)
+	require.True(t, report.Tests[0].Passed, report.Tests[0].Error)
+	require.False(t, report.Tests[1].Passed)
+	require.Contains(t, report.Tests[1].Error, "out of memory: test exceeded verify.memory-limit-mb (1024 MB)")
+	require.Contains(t, report.Tests[1].Command, "ulimit -v 1048576")
+}
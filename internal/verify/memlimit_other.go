@@ -0,0 +1,8 @@
+//go:build !linux
+
+package verify
+
+// memoryLimitFlag reports that verify.memory-limit-mb isn't enforced on this platform.
+func memoryLimitFlag(limitMB int) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,99 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const modTidyTestName = "verify.mod-tidy"
+
+// modFileSnapshot records a module file's contents and mode so it can be restored after a mutating go command.
+type modFileSnapshot struct {
+	path   string
+	data   []byte
+	mode   os.FileMode
+	exists bool
+}
+
+func snapshotModFile(path string) (modFileSnapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return modFileSnapshot{path: path}, nil
+		}
+		return modFileSnapshot{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return modFileSnapshot{}, err
+	}
+	return modFileSnapshot{path: path, data: data, mode: info.Mode().Perm(), exists: true}, nil
+}
+
+func (s modFileSnapshot) restore() error {
+	if !s.exists {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.WriteFile(s.path, s.data, s.mode); err != nil {
+		return err
+	}
+	// WriteFile only applies the mode when it creates the file, so reset it in case the go command replaced or
+	// re-permissioned the original.
+	return os.Chmod(s.path, s.mode)
+}
+
+// checkModTidy runs `go mod tidy` in the workspace and fails if it changed go.mod or go.sum. The original files are
+// always restored afterward, so the check never shows up in change detection.
+func checkModTidy(ctx context.Context, workspaceDir string, printer *output.Printer) (types.TestResult, error) {
+	result := types.TestResult{Name: modTidyTestName}
+	goMod, err := snapshotModFile(filepath.Join(workspaceDir, "go.mod"))
+	if err != nil {
+		return result, err
+	}
+	if !goMod.exists {
+		result.Error = "go.mod not found in workspace root"
+		return result, nil
+	}
+	goSum, err := snapshotModFile(filepath.Join(workspaceDir, "go.sum"))
+	if err != nil {
+		return result, err
+	}
+
+	out, runErr := runStreaming(ctx, printer, workspaceDir, "go", "mod", "tidy")
+	result.Output = string(out)
+
+	var changed []string
+	for _, snap := range []modFileSnapshot{goMod, goSum} {
+		after, err := snapshotModFile(snap.path)
+		if err != nil {
+			return result, err
+		}
+		if after.exists != snap.exists || !bytes.Equal(after.data, snap.data) {
+			changed = append(changed, filepath.Base(snap.path))
+		}
+		if err := snap.restore(); err != nil {
+			return result, fmt.Errorf("restore %s after go mod tidy: %w", snap.path, err)
+		}
+	}
+
+	switch {
+	case runErr != nil:
+		result.Error = fmt.Sprintf("go mod tidy failed: %v", runErr)
+	case len(changed) > 0:
+		result.Error = fmt.Sprintf("go mod tidy would change %s", strings.Join(changed, ", "))
+	default:
+		result.Passed = true
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const noNewDepsTestName = "verify.no-new-deps"
+
+// checkNoNewDeps compares the direct requirements in the workspace's root go.mod against go.mod at the scenario's base
+// commit, not HEAD, so dependencies the agent committed are caught too. It returns every added direct dependency, and a
+// failing result if any of them is not in verify.no-new-deps-allow.
+func checkNoNewDeps(sc *scenario.Scenario, workspaceDir string) (types.TestResult, []string, error) {
+	result := types.TestResult{Name: noNewDepsTestName}
+	base, err := baseGoMod(workspaceDir, sc.Commit)
+	if err != nil {
+		return result, nil, err
+	}
+	current, err := os.ReadFile(filepath.Join(workspaceDir, "go.mod"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, nil, err
+	}
+	before := directRequires(base)
+	var added, disallowed []string
+	for _, mod := range directRequires(current) {
+		if slices.Contains(before, mod) {
+			continue
+		}
+		added = append(added, mod)
+		if !slices.Contains(sc.Verify.NoNewDepsAllow, mod) {
+			disallowed = append(disallowed, mod)
+		}
+	}
+	if len(disallowed) > 0 {
+		result.Error = fmt.Sprintf("go.mod adds direct dependencies: %s", strings.Join(disallowed, ", "))
+		return result, added, nil
+	}
+	result.Passed = true
+	return result, added, nil
+}
+
+// baseGoMod returns go.mod's contents at commit, or nil if commit has no root go.mod.
+func baseGoMod(workspaceDir, commit string) ([]byte, error) {
+	out, err := runInWorkspace(workspaceDir, "git", "ls-tree", "--name-only", commit, "--", "go.mod")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return nil, nil
+	}
+	return runInWorkspace(workspaceDir, "git", "show", commit+":go.mod")
+}
+
+// directRequires returns the module paths of go.mod's require directives that aren't marked "// indirect", sorted.
+func directRequires(goMod []byte) []string {
+	var mods []string
+	inBlock := false
+	for _, line := range strings.Split(string(goMod), "\n") {
+		code, comment, _ := strings.Cut(line, "//")
+		fields := strings.Fields(code)
+		if inBlock {
+			if len(fields) == 1 && fields[0] == ")" {
+				inBlock = false
+				continue
+			}
+		} else {
+			if len(fields) == 0 || fields[0] != "require" {
+				continue
+			}
+			fields = fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				inBlock = true
+				continue
+			}
+		}
+		if len(fields) < 2 || strings.TrimSpace(comment) == "indirect" || strings.HasPrefix(strings.TrimSpace(comment), "indirect;") {
+			continue
+		}
+		mods = append(mods, strings.Trim(fields[0], `"`))
+	}
+	slices.Sort(mods)
+	return slices.Compact(mods)
+}
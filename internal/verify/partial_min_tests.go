@@ -0,0 +1,34 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const partialMinTestsName = "verify.partial-min-tests"
+
+// partialNoTestsError marks a partial-tests result whose go test run passed without running any tests.
+const partialNoTestsError = "partial target matched no tests"
+
+// checkPartialMinTests fails if any partial-tests entry ran fewer than minTests tests, which usually means the entry is
+// misconfigured. An entry that failed without running any tests (ex: a build failure, or not run before
+// verify.timeout) is a real failure, already scored, so it isn't flagged.
+func checkPartialMinTests(results []types.TestResult, minTests int) types.TestResult {
+	result := types.TestResult{Name: partialMinTestsName}
+	var flagged []string
+	for _, res := range results {
+		_, total := parseJSONCounts(res.Output)
+		if total >= minTests || total == 0 && res.Error != partialNoTestsError {
+			continue
+		}
+		flagged = append(flagged, fmt.Sprintf("%s (%d)", res.Name, total))
+	}
+	if len(flagged) == 0 {
+		result.Passed = true
+		return result
+	}
+	result.Error = fmt.Sprintf("partial-tests entries ran fewer than %d test(s), so they are likely misconfigured: %s", minTests, strings.Join(flagged, ", "))
+	return result
+}
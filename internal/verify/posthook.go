@@ -0,0 +1,31 @@
+package verify
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+// postHookSuccessEnv tells verify.post-hook whether verification succeeded ("true" or "false").
+const postHookSuccessEnv = "GAB_SUCCESS"
+
+// runPostHook runs verify.post-hook (if any) in workspaceDir after the report is written. The hook is a side effect: a
+// failure is logged but never changes the verification result.
+func runPostHook(ctx context.Context, printer *output.Printer, workspaceDir string, sc *scenario.Scenario, report *types.VerificationReport) {
+	cmd := strings.TrimSpace(sc.Verify.PostHook)
+	if cmd == "" {
+		return
+	}
+	if printer == nil {
+		printer = output.NewPrinter(nil)
+	}
+	_ = printer.Appf("Running verify post-hook: %s", cmd)
+	env := []string{postHookSuccessEnv + "=" + strconv.FormatBool(report.Success)}
+	if _, err := printer.RunCommandStreamingEnv(ctx, workspaceDir, env, "sh", "-c", cmd); err != nil {
+		_ = printer.Appf("Warning: verify post-hook %q failed: %v", cmd, err)
+	}
+}
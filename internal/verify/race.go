@@ -0,0 +1,129 @@
+package verify
+
+import (
+	"bufio"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/scenario"
+)
+
+const (
+	// raceWarning starts each report the race detector prints.
+	raceWarning = "WARNING: DATA RACE"
+	// raceTestFailure is what the testing package fails a test with when a race was detected while it ran.
+	raceTestFailure = "race detected during execution of test"
+	// raceOutsideTests is what the testing package fails a package with when a race was detected outside any test.
+	raceOutsideTests = "race detected outside of test execution"
+)
+
+// testLogLine matches the "    file_test.go:12: ..." lines t.Log and t.Error print.
+var testLogLine = regexp.MustCompile(`^ {4}\S+\.go:\d+: `)
+
+// raceFlags returns the go test flags for a verify.race-mode.
+func raceFlags(mode string) []string {
+	if mode == scenario.RaceModeWarn || mode == scenario.RaceModeFail {
+		return []string{"-race"}
+	}
+	return nil
+}
+
+// countRaces returns how many race reports go test output has (plain or -json).
+func countRaces(out string) int {
+	return strings.Count(out, raceWarning)
+}
+
+// raceFailures looks at the go test -json stream out and returns how many failed tests only failed because of a data
+// race, and whether every failure in out is explained by races (so with verify.race-mode warn, the run passes). A test
+// is explained if its only failure output is the testing package's race line, or if it has no failure output of its
+// own and failed subtests that are all explained. A failed package is explained if it has no failure output of its own
+// and either reports a race outside any test or has failed tests that are all explained; otherwise (ex: a build
+// failure, a panic in init, or os.Exit in TestMain) it's a real failure.
+func raceFailures(out string) (int, bool) {
+	if countRaces(out) == 0 {
+		return 0, false
+	}
+	var events []goTestEvent
+	typed := false
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		typed = typed || ev.OutputType != ""
+		events = append(events, ev)
+	}
+
+	type testKey struct{ pkg, test string }
+	var failed, failedPkgs []testKey
+	raced := map[testKey]bool{}
+	otherFailure := map[testKey]bool{}
+	for _, ev := range events {
+		key := testKey{ev.Package, ev.Test}
+		switch ev.Action {
+		case "output":
+			switch {
+			case strings.Contains(ev.Output, raceTestFailure), ev.Test == "" && strings.Contains(ev.Output, raceOutsideTests):
+				raced[key] = true
+			case isFailureOutput(ev, typed):
+				otherFailure[key] = true
+			}
+		case "fail":
+			if ev.Test == "" {
+				failedPkgs = append(failedPkgs, key)
+			} else {
+				failed = append(failed, key)
+			}
+		}
+	}
+
+	// Subtests finish before their parents, so a parent's failed subtests have all been judged when it is.
+	isExplained := map[testKey]bool{}
+	failedChildrenExplained := func(parent testKey) (bool, bool) {
+		found := false
+		for _, key := range failed {
+			if key.pkg != parent.pkg || parent.test != "" && !strings.HasPrefix(key.test, parent.test+"/") {
+				continue
+			}
+			if !isExplained[key] {
+				return true, false
+			}
+			found = true
+		}
+		return found, found
+	}
+	explained := 0
+	all := len(failed)+len(failedPkgs) > 0
+	for _, key := range failed {
+		hasFailedSubtests, subtestsExplained := failedChildrenExplained(key)
+		if !otherFailure[key] && (raced[key] && !hasFailedSubtests || subtestsExplained) {
+			isExplained[key] = true
+			explained++
+		} else {
+			all = false
+		}
+	}
+	for _, key := range failedPkgs {
+		_, testsExplained := failedChildrenExplained(key)
+		if otherFailure[key] || !raced[key] && !testsExplained {
+			all = false
+		}
+	}
+	return explained, all
+}
+
+// isFailureOutput reports whether the output event ev reports a failure (other than a race): a panic, or a t.Error or
+// t.Fatal line. typed means the stream has go1.25+ output types; older toolchains don't tell t.Error lines from t.Log
+// lines, so any log line counts.
+func isFailureOutput(ev goTestEvent, typed bool) bool {
+	if strings.HasPrefix(ev.Output, "panic: ") || strings.HasPrefix(ev.Output, "fatal error: ") {
+		return true
+	}
+	if typed {
+		return ev.OutputType == "error" || ev.OutputType == "error-continue"
+	}
+	return ev.Test != "" && testLogLine.MatchString(ev.Output)
+}
@@ -0,0 +1,66 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/workspace"
+)
+
+// CheckReproduced checks that a freshly set up workspace doesn't already pass verification (see setup
+// --check-reproduced). It only applies when classification.sees-failing-tests is false, since the tests are then
+// hidden from the agent: it applies verify.copy, runs the verify.tests and partial-tests entries, removes the copies
+// again, and returns an error if every entry passed. Tests run as verify runs them (verify.goos/goarch, test-timeout,
+// memory-limit-mb, race-mode, shuffle, and timeout apply), except that a target that can't run on this host skips the
+// check. Staged scenarios are checked against their first stage.
+func CheckReproduced(ctx context.Context, printer *output.Printer, scenarioName, workspacePath string, sc *scenario.Scenario) error {
+	if printer == nil {
+		printer = output.NewPrinter(os.Stdout)
+	}
+	if sees := sc.Classification.SeesFailingTests; sees == nil || *sees {
+		return printer.App("Skipping reproduction check: classification.sees-failing-tests is not false.")
+	}
+	if stageScenarios := sc.StageScenarios(); len(stageScenarios) > 0 {
+		sc = stageScenarios[0]
+	}
+	entries := append(append(scenario.StringList{}, sc.Verify.Tests...), sc.Verify.PartialTests...)
+	if len(entries) == 0 {
+		return errors.New("reproduction check: no verify.tests or partial-tests to run")
+	}
+	target, buildOnly := verifyTarget(sc)
+	if buildOnly {
+		return printer.Appf("Skipping reproduction check: verify target %s can't run on this host.", target)
+	}
+	scenarioDir := workspace.ScenarioDir(scenarioName)
+	workspaceDir := workspace.WorkspaceScenarioDir(workspacePath, scenarioName)
+	if err := printer.App("Checking that the scenario's tests fail before the agent runs."); err != nil {
+		return err
+	}
+	cleanup, err := applyVerifyCopies(sc, scenarioDir, workspaceDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	gt, memoryLimited := newGoTestConfig(sc, shuffleSeedFor(Options{}, sc), false)
+	if sc.Verify.MemoryLimitMB > 0 && !memoryLimited {
+		if err := printer.Appf("Warning: verify.memory-limit-mb is only enforced on Linux; running tests without it."); err != nil {
+			return err
+		}
+	}
+	testCtx, cancelTests := withVerifyTimeout(ctx, sc.Verify.Timeout)
+	defer cancelTests()
+	results, err := runTestList(testCtx, workspaceDir, entries, 0, sc.Verify.RaceMode == scenario.RaceModeWarn, 0, gt, printer)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if !r.Passed {
+			return printer.Appf("Reproduced: %s fails before the agent runs.", r.Name)
+		}
+	}
+	return fmt.Errorf("reproduction check: all %d test entries already pass before the agent runs; the scenario's bug isn't reproduced", len(results))
+}
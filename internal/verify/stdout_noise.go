@@ -0,0 +1,86 @@
+package verify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const stdoutNoiseTestName = "verify.no-stdout-noise"
+
+// maxNoiseLines caps how many flagged lines the verify.no-stdout-noise result lists.
+const maxNoiseLines = 20
+
+// frameworkOutputPattern matches lines go test itself prints (ex: "=== RUN", "--- PASS:", "ok  \tpkg"). Lines indented by
+// at least 4 spaces are t.Log output (or its continuation lines), so they are never flagged either.
+var frameworkOutputPattern = regexp.MustCompile(`^(=== (RUN|PAUSE|CONT|NAME)\b|\s*--- (PASS|FAIL|SKIP|BENCH)\b|PASS$|FAIL$|ok\s|FAIL\s|\?\s|coverage:|    )`)
+
+// checkStdoutNoise looks at the `go test -json` output of each verify.tests result and flags output lines printed by
+// passing tests that go test didn't print itself (ex: leftover fmt.Println debugging). It is deliberately conservative:
+// output from failing or skipped tests, and output not attributed to a test, is ignored.
+func checkStdoutNoise(results []types.TestResult) types.TestResult {
+	result := types.TestResult{Name: stdoutNoiseTestName}
+	var flagged []string
+	for _, res := range results {
+		flagged = append(flagged, noiseLines(res.Output)...)
+	}
+	if len(flagged) == 0 {
+		result.Passed = true
+		return result
+	}
+	shown := flagged
+	if len(shown) > maxNoiseLines {
+		shown = shown[:maxNoiseLines]
+	}
+	result.Output = strings.Join(flagged, "\n")
+	result.Error = fmt.Sprintf("%d unexpected output line(s) from passing tests:\n%s", len(flagged), strings.Join(shown, "\n"))
+	if len(flagged) > len(shown) {
+		result.Error += fmt.Sprintf("\n... and %d more", len(flagged)-len(shown))
+	}
+	return result
+}
+
+// noiseLines returns "<package> <test>: <line>" for each non-framework output line of a passing test in the go test
+// -json stream out.
+func noiseLines(out string) []string {
+	type testKey struct{ pkg, test string }
+	var order []testKey
+	lines := map[testKey][]string{}
+	passed := map[testKey]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Test == "" {
+			continue
+		}
+		key := testKey{ev.Package, ev.Test}
+		switch ev.Action {
+		case "output":
+			line := strings.TrimRight(ev.Output, "\r\n")
+			if strings.TrimSpace(line) == "" || frameworkOutputPattern.MatchString(line) {
+				continue
+			}
+			if _, ok := lines[key]; !ok {
+				order = append(order, key)
+			}
+			lines[key] = append(lines[key], line)
+		case "pass":
+			passed[key] = true
+		}
+	}
+	var flagged []string
+	for _, key := range order {
+		if !passed[key] {
+			continue
+		}
+		for _, line := range lines[key] {
+			flagged = append(flagged, fmt.Sprintf("%s %s: %s", key.pkg, key.test, line))
+		}
+	}
+	return flagged
+}
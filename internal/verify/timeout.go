@@ -0,0 +1,83 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+)
+
+const timeoutTestName = "verify.timeout"
+
+// errVerifyTimeout is the context cause once verify.timeout passes.
+var errVerifyTimeout = errors.New("verify.timeout exceeded")
+
+// withVerifyTimeout returns ctx with a verify.timeout deadline, or ctx itself if timeout is 0.
+func withVerifyTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w (%s)", errVerifyTimeout, timeout))
+}
+
+// timedOut reports whether ctx's verify.timeout passed.
+func timedOut(ctx context.Context) bool {
+	return ctx.Err() != nil && errors.Is(context.Cause(ctx), errVerifyTimeout)
+}
+
+// errTestTimeout is the context cause once verify.test-timeout passes for a go test invocation.
+var errTestTimeout = errors.New("verify.test-timeout exceeded")
+
+// withTestTimeout returns ctx with a verify.test-timeout deadline, or ctx itself if timeout is 0.
+func withTestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, errTestTimeout)
+}
+
+// testTimedOut reports whether ctx's verify.test-timeout passed (not verify.timeout or a cancellation of its parent).
+func testTimedOut(ctx context.Context) bool {
+	return ctx.Err() != nil && errors.Is(context.Cause(ctx), errTestTimeout)
+}
+
+// shortDuration formats d without zero trailing units (ex: "5m" rather than "5m0s").
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// notRunResult is the failed result for an entry that wasn't started because verify.timeout passed.
+func notRunResult(ctx context.Context, name string) types.TestResult {
+	return types.TestResult{Name: name, Error: fmt.Sprintf("not run: %v", context.Cause(ctx))}
+}
+
+// markIfTimedOut notes on a failed result that verify.timeout passed while it ran (so it was killed).
+func markIfTimedOut(ctx context.Context, res *types.TestResult) {
+	if res.Passed || !timedOut(ctx) {
+		return
+	}
+	msg := fmt.Sprintf("killed: %v", context.Cause(ctx))
+	if res.Error != "" {
+		msg += " (" + res.Error + ")"
+	}
+	res.Error = msg
+}
+
+// timeoutResult is the verify.timeout entry added to the report when the timeout passed, so the run always fails
+// (ex: a partial score computed from only the entries that ran).
+func timeoutResult(ctx context.Context) types.TestResult {
+	return types.TestResult{
+		Name:  timeoutTestName,
+		Error: fmt.Sprintf("%v: unfinished entries failed", context.Cause(ctx)),
+	}
+}
@@ -0,0 +1,30 @@
+package verify
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/codalotl/goagentbench/internal/scenario"
+)
+
+var (
+	errGitNotFound = errors.New("git not found on PATH; install git")
+	errGoNotFound  = errors.New("go toolchain not found on PATH; install Go (https://go.dev/dl/)")
+)
+
+// checkTools returns a clear error if a tool that verifying sc needs isn't installed, rather than failing with cryptic
+// exec errors inside test results.
+func checkTools(sc *scenario.Scenario) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errGitNotFound
+	}
+	v := sc.Verify
+	needsGo := v.ModTidy || len(v.Tests) > 0 || len(v.MustFail) > 0 || len(v.PartialTests) > 0
+	if !needsGo {
+		return nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return errGoNotFound
+	}
+	return nil
+}
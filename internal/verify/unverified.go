@@ -0,0 +1,55 @@
+package verify
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/codalotl/goagentbench/internal/types"
+	"github.com/codalotl/goagentbench/internal/workspace"
+)
+
+// RecordUnverified writes a results file for the workspace's agent run without verifying it (exec --no-verify). The
+// report has the run's progress (tokens, cost, duration) and diff stat, no tests, Success false, and Unverified set, so
+// report can use the run's cost and time without counting it as a failure.
+func RecordUnverified(opts Options) (*types.VerificationReport, error) {
+	workspaceDir := workspace.WorkspaceScenarioDir(opts.WorkspacePath, opts.ScenarioName)
+	progressPath := filepath.Join(workspaceDir, ".run-progress.json")
+	progress, err := readRunProgress(progressPath)
+	if err != nil {
+		return nil, fmt.Errorf("no run progress at %s; run the agent first: %w", progressPath, err)
+	}
+	runStart, _ := readRunStart(filepath.Join(workspaceDir, ".run-start.json"))
+	if progress.RunID == "" && runStart != nil {
+		progress.RunID = runStart.RunID
+	}
+	var linesAdded, linesDeleted *int
+	if pathExists(filepath.Join(workspaceDir, ".git")) {
+		if stat, err := computeDiffStat(workspaceDir); err == nil {
+			linesAdded, linesDeleted = &stat.Added, &stat.Deleted
+		}
+	}
+	report := &types.VerificationReport{
+		RunID:        runID(runStart, progress),
+		Scenario:     opts.ScenarioName,
+		Agent:        agentName(runStart, progress),
+		AgentVersion: agentVersion(runStart, progress),
+		Model:        modelName(runStart, progress),
+		StartedAt:    startedAt(runStart),
+		Progress:     progress,
+		VerifiedAt:   time.Now(),
+		Success:      false,
+		LinesAdded:   linesAdded,
+		LinesDeleted: linesDeleted,
+		System:       systemInfo(runStart),
+		SetupSeconds: readSetupSeconds(filepath.Join(workspaceDir, ".setup-meta.json")),
+		Tests:        []types.TestResult{},
+		Unverified:   true,
+	}
+	if !opts.OnlyReport {
+		if err := writeReport(opts, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/codalotl/goagentbench/internal/fsutil"
 	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/results"
 	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/codalotl/goagentbench/internal/types"
 	"github.com/codalotl/goagentbench/internal/workspace"
@@ -29,14 +32,39 @@ type Options struct {
 	RootPath      string
 	OnlyReport    bool
 	CopyOnly      bool
-	Printer       *output.Printer
+	// GitHubAnnotations prints GitHub Actions "::error" annotations for failures instead of the normal summary.
+	GitHubAnnotations bool
+	// JUnitPath, when set, also writes the results as JUnit XML to this file.
+	JUnitPath string
+	// Shuffle runs tests with `go test -shuffle`, like verify.shuffle. ShuffleSeed (non-nil) fixes the seed and implies
+	// Shuffle; otherwise a seed is picked at random. The seed is recorded in the report either way.
+	Shuffle     bool
+	ShuffleSeed *int64
+	// RunFilter, when set, replaces the -run pattern of every verify.tests and partial-tests entry (see verify --run).
+	// It's recorded in the report as run_filter.
+	RunFilter string
+	// Baseline, when set, is a known-good report to diff the new Tests/PartialScore against. The diff is only printed;
+	// it doesn't affect success.
+	Baseline *types.VerificationReport
+	// Parallelism is how many verify.tests entries run at once (<= 1: one at a time). Results keep entry order, and
+	// each entry's output is printed as one block.
+	Parallelism int
+	// CaptureDiff stores the agent's unified diff vs HEAD in the report's Diff (truncated past a size cap), so the
+	// results file can be reviewed without the workspace.
+	CaptureDiff bool
+	// CaptureStatus stores `git status --porcelain` in the report's GitStatus, to show what the agent staged, modified,
+	// and added.
+	CaptureStatus bool
+	// Store receives the results file. Nil means the filesystem store at the results dir.
+	Store   results.Store
+	Printer *output.Printer
 }
 
 type Result struct {
 	Report *types.VerificationReport
 }
 
-const resultsEnvVar = "GOAGENTBENCH_RESULTS"
+const resultsEnvVar = results.EnvVar
 
 // Run executes verification: optional copies, go test runs, and writes report.
 func Run(ctx context.Context, opts Options, sc *scenario.Scenario) (*Result, error) {
@@ -53,6 +81,28 @@ func Run(ctx context.Context, opts Options, sc *scenario.Scenario) (*Result, err
 	if _, err := os.Stat(workspaceDir); err != nil {
 		return nil, fmt.Errorf("workspace for scenario not found at %s", workspaceDir)
 	}
+	if err := scenario.CheckSinglePackage(sc); err != nil {
+		if err := printer.Appf("Warning: %v", err); err != nil {
+			return nil, err
+		}
+	}
+	goVersion := goToolchainVersion(ctx, workspaceDir)
+	if err := checkAllowedGoVersions(sc, goVersion); err != nil {
+		return nil, err
+	}
+	// A staged scenario is verified by its last stage; earlier stages' outcomes come from the run progress.
+	var stages []scenario.Stage
+	if stageScenarios := sc.StageScenarios(); len(stageScenarios) > 0 {
+		stages = sc.Stages
+		sc = stageScenarios[len(stageScenarios)-1]
+	}
+	if opts.RunFilter != "" {
+		filtered, err := withRunFilter(sc, opts.RunFilter)
+		if err != nil {
+			return nil, err
+		}
+		sc = filtered
+	}
 
 	if opts.CopyOnly {
 		_, err := applyVerifyCopies(sc, scenarioDir, workspaceDir)
@@ -72,15 +122,60 @@ func Run(ctx context.Context, opts Options, sc *scenario.Scenario) (*Result, err
 		return &Result{Report: nil}, nil
 	}
 
+	if err := checkTools(sc); err != nil {
+		return nil, err
+	}
+
 	runStart, _ := readRunStart(filepath.Join(workspaceDir, ".run-start.json"))
 	progress, _ := readRunProgress(filepath.Join(workspaceDir, ".run-progress.json"))
+	setupSeconds := readSetupSeconds(filepath.Join(workspaceDir, ".setup-meta.json"))
 	if progress != nil && progress.RunID == "" && runStart != nil {
 		progress.RunID = runStart.RunID
 	}
-	problems, err := checkModificationRules(sc, workspaceDir)
+	var problems []modificationProblem
+	var status workspaceStatus
+	gitRepo := pathExists(filepath.Join(workspaceDir, ".git"))
+	if gitRepo {
+		var err error
+		if status, err = readWorkspaceStatus(workspaceDir); err != nil {
+			return nil, err
+		}
+		if problems, err = checkModificationRules(sc, workspaceDir, status); err != nil {
+			return nil, err
+		}
+	} else {
+		// Without .git, changes can't be listed. If setup or the agent ran, .git was there, so the agent deleted it.
+		if runStart == nil && progress == nil && setupSeconds == nil {
+			return nil, fmt.Errorf("workspace %s is not a git repository; run setup", workspaceDir)
+		}
+		problems = []modificationProblem{{
+			message:   ".git was deleted, so the agent's changes can't be checked",
+			violation: types.Violation{Path: ".git", Kind: "git-deleted"},
+		}}
+	}
+	copyProblems, err := checkCopyConflicts(sc, scenarioDir, workspaceDir)
 	if err != nil {
 		return nil, err
 	}
+	problems = append(problems, copyProblems...)
+	var linesAdded, linesDeleted *int
+	// Without .git, git would diff an enclosing repo, if any.
+	if gitRepo {
+		if stat, err := computeDiffStat(workspaceDir); err == nil {
+			linesAdded, linesDeleted = &stat.Added, &stat.Deleted
+		}
+	}
+	var gitStatus string
+	if opts.CaptureStatus {
+		gitStatus = status.porcelain
+	}
+	var diff string
+	if opts.CaptureDiff && gitRepo {
+		var err error
+		if diff, err = captureDiff(workspaceDir, maxCapturedDiffBytes); err != nil {
+			return nil, fmt.Errorf("capture diff: %w", err)
+		}
+	}
 	if len(problems) > 0 {
 		report := &types.VerificationReport{
 			RunID:        runID(runStart, progress),
@@ -92,36 +187,112 @@ func Run(ctx context.Context, opts Options, sc *scenario.Scenario) (*Result, err
 			Progress:     progress,
 			VerifiedAt:   time.Now(),
 			Success:      false,
+			LinesAdded:   linesAdded,
+			LinesDeleted: linesDeleted,
+			Diff:         diff,
+			GitStatus:    gitStatus,
+			GoVersion:    goVersion,
+			System:       systemInfo(runStart),
+			SetupSeconds: setupSeconds,
+			Violations:   problemViolations(problems),
 			Tests: []types.TestResult{
 				{
-					Name:   "verify.modification-rules",
+					Name:   modificationRulesTestName,
 					Passed: false,
-					Error:  strings.Join(problems, "\n"),
+					Output: problemsText(problems),
 				},
 			},
 		}
-		if !opts.OnlyReport {
-			if err := writeReport(opts, report); err != nil {
-				return nil, err
-			}
+		foldStages(report, stages, progress)
+		if err := writeOutputs(opts, report); err != nil {
+			return nil, err
 		}
-		printSummary(printer, report)
+		printResult(opts, printer, report)
+		runPostHook(ctx, printer, workspaceDir, sc, report)
 		return &Result{Report: report}, nil
 	}
+	// Gates inspect the agent's work as-is, so they run before verify.copy adds hidden files.
+	var gateResults []types.TestResult
+	if sc.Verify.ModTidy {
+		res, err := checkModTidy(ctx, workspaceDir, printer)
+		if err != nil {
+			return nil, err
+		}
+		gateResults = append(gateResults, res)
+	}
+	if sc.Verify.Generate {
+		res, err := checkGenerate(ctx, workspaceDir, printer)
+		if err != nil {
+			return nil, err
+		}
+		gateResults = append(gateResults, res)
+	}
+	var addedDeps []string
+	if sc.Verify.NoNewDeps {
+		res, added, err := checkNoNewDeps(sc, workspaceDir)
+		if err != nil {
+			return nil, err
+		}
+		gateResults = append(gateResults, res)
+		addedDeps = added
+	}
+	if len(sc.Verify.MustBeExecutable) > 0 {
+		res, err := checkMustBeExecutable(sc, workspaceDir)
+		if err != nil {
+			return nil, err
+		}
+		gateResults = append(gateResults, res)
+	}
 	cleanup, err := applyVerifyCopies(sc, scenarioDir, workspaceDir)
 	if err != nil {
 		return nil, err
 	}
 	defer cleanup()
 
-	testResults, err := runTestList(ctx, workspaceDir, sc.Verify.Tests, printer)
+	target, buildOnly := verifyTarget(sc)
+	if buildOnly && (len(sc.Verify.MustFail) > 0 || len(sc.Verify.PartialTests) > 0) {
+		return nil, fmt.Errorf("verify target %s can't run on %s/%s; must-fail and partial-tests need to run tests", target, runtime.GOOS, runtime.GOARCH)
+	}
+	shuffleSeed := shuffleSeedFor(opts, sc)
+	gt, memoryLimited := newGoTestConfig(sc, shuffleSeed, buildOnly)
+	if buildOnly {
+		if err := printer.Appf("Target %s can't run on this host: tests are built but not run.", target); err != nil {
+			return nil, err
+		}
+	}
+	if sc.Verify.MemoryLimitMB > 0 && !buildOnly && !memoryLimited {
+		if err := printer.Appf("Warning: verify.memory-limit-mb is only enforced on Linux; running tests without it."); err != nil {
+			return nil, err
+		}
+	}
+	testCtx, cancelTests := withVerifyTimeout(ctx, sc.Verify.Timeout)
+	defer cancelTests()
+	// verify.no-stdout-noise and race-mode warn need the -json stream to tell which test printed what.
+	forceJSON := sc.Verify.NoStdoutNoise || sc.Verify.RaceMode == scenario.RaceModeWarn
+	testResults, err := runTestList(testCtx, workspaceDir, sc.Verify.Tests, sc.Verify.Retries, forceJSON, opts.Parallelism, gt, printer)
+	if err != nil {
+		return nil, err
+	}
+	if sc.Verify.NoStdoutNoise {
+		testResults = append(testResults, checkStdoutNoise(testResults))
+	}
+	testResults = append(gateResults, testResults...)
+	testResults = append(testResults, runVerifyCommands(testCtx, workspaceDir, sc.Verify.Commands, printer)...)
+	mustFailResults, err := runMustFail(testCtx, workspaceDir, sc.Verify.MustFail, gt, printer)
 	if err != nil {
 		return nil, err
 	}
-	partialResults, partialScore, err := runPartial(ctx, workspaceDir, sc.Verify.PartialTests, printer)
+	testResults = append(testResults, mustFailResults...)
+	partialResults, partialScore, err := runPartial(testCtx, workspaceDir, sc.Verify, gt, printer)
 	if err != nil {
 		return nil, err
 	}
+	if sc.Verify.PartialMinTests > 0 {
+		testResults = append(testResults, checkPartialMinTests(partialResults, sc.Verify.PartialMinTests))
+	}
+	if timedOut(testCtx) {
+		testResults = append(testResults, timeoutResult(testCtx))
+	}
 	allRequiredPassed := allPassed(testResults)
 	partialPassed := partialScore == nil || *partialScore == 1
 	success := allRequiredPassed && partialPassed
@@ -137,83 +308,270 @@ func Run(ctx context.Context, opts Options, sc *scenario.Scenario) (*Result, err
 		VerifiedAt:   time.Now(),
 		Success:      success,
 		PartialScore: partialScore,
+		LinesAdded:   linesAdded,
+		LinesDeleted: linesDeleted,
+		Diff:         diff,
+		GitStatus:    gitStatus,
+		GoVersion:    goVersion,
+		System:       systemInfo(runStart),
+		ShuffleSeed:  shuffleSeed,
+		Target:       target,
+		BuildOnly:    buildOnly,
+		AddedDeps:    addedDeps,
+		SetupSeconds: setupSeconds,
+		RunFilter:    opts.RunFilter,
 		Tests:        testResults,
 		PartialTests: partialResults,
 	}
-
-	if !opts.OnlyReport {
-		if err := writeReport(opts, report); err != nil {
-			return nil, err
+	if len(raceFlags(sc.Verify.RaceMode)) > 0 {
+		report.RaceMode = sc.Verify.RaceMode
+		// An entry in both tests and partial-tests runs the same tests twice; count its races once.
+		inTests := map[string]bool{}
+		for _, res := range testResults {
+			report.Races += res.Races
+			inTests[res.Name] = true
+		}
+		for _, res := range partialResults {
+			if !inTests[res.Name] {
+				report.Races += res.Races
+			}
 		}
 	}
-	printSummary(printer, report)
+
+	foldStages(report, stages, progress)
+	if err := writeOutputs(opts, report); err != nil {
+		return nil, err
+	}
+	printResult(opts, printer, report)
+	runPostHook(ctx, printer, workspaceDir, sc, report)
 	return &Result{Report: report}, nil
 }
 
-func checkModificationRules(sc *scenario.Scenario, workspaceDir string) ([]string, error) {
-	changes, err := listWorkspaceChanges(workspaceDir)
-	if err != nil {
-		return nil, err
+// foldStages records per-stage outcomes on a staged scenario's report: earlier stages from the run progress (a stage the
+// run never finished fails) and the final stage from report itself. Success then requires every stage to pass.
+func foldStages(report *types.VerificationReport, stages []scenario.Stage, progress *types.RunProgress) {
+	if len(stages) == 0 {
+		return
 	}
-	changes = filterIgnoredChanges(changes)
+	recorded := map[string]types.StageResult{}
+	if progress != nil {
+		for _, r := range progress.Stages {
+			recorded[r.Name] = r
+		}
+	}
+	last := len(stages) - 1
+	for _, st := range stages[:last] {
+		r, ok := recorded[st.Name]
+		if !ok {
+			r = types.StageResult{Name: st.Name}
+		}
+		report.Stages = append(report.Stages, r)
+	}
+	// The final stage's tests are the report's own Tests and PartialTests.
+	report.Stages = append(report.Stages, types.StageResult{
+		Name:         stages[last].Name,
+		Success:      report.Success,
+		PartialScore: report.PartialScore,
+	})
+	for _, r := range report.Stages {
+		report.Success = report.Success && r.Success
+	}
+}
+
+// modificationProblem is a broken modification rule and its human-readable message.
+type modificationProblem struct {
+	message   string
+	violation types.Violation
+}
+
+// problemsText joins problems' messages, one per line. Problems sharing a message (ex: every must-modify rule of a
+// workspace with no changes) are listed once.
+func problemsText(problems []modificationProblem) string {
+	var lines []string
+	for _, p := range problems {
+		if len(lines) == 0 || lines[len(lines)-1] != p.message {
+			lines = append(lines, p.message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func problemViolations(problems []modificationProblem) []types.Violation {
+	violations := make([]types.Violation, 0, len(problems))
+	for _, p := range problems {
+		violations = append(violations, p.violation)
+	}
+	return violations
+}
+
+func checkModificationRules(sc *scenario.Scenario, workspaceDir string, status workspaceStatus) ([]modificationProblem, error) {
+	changes := filterIgnoredChanges(status.changes)
+	deleted := filterIgnoredChanges(status.deleted)
 	if len(changes) == 0 {
-		if len(sc.Verify.MustModify) == 0 {
-			return nil, nil
+		var problems []modificationProblem
+		if len(sc.Verify.MustModify) > 0 {
+			for _, rule := range sc.Verify.MustModify {
+				problems = append(problems, modificationProblem{
+					message:   "workspace has no changes but verify.must-modify requires modifications",
+					violation: types.Violation{Rule: rule, Kind: "must-modify"},
+				})
+			}
+		} else {
+			for _, group := range sc.Verify.MustModifyAnyOf {
+				problems = append(problems, modificationProblem{
+					message:   "workspace has no changes but verify.must-modify-any-of requires modifications",
+					violation: types.Violation{Rule: strings.Join(group, ", "), Kind: "must-modify-any-of"},
+				})
+			}
 		}
-		return []string{"workspace has no changes but verify.must-modify requires modifications"}, nil
+		return problems, nil
 	}
 
-	var problems []string
+	var problems []modificationProblem
+	if sc.Verify.ProtectTests {
+		touched, err := listTouchedTestFiles(workspaceDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range touched {
+			problems = append(problems, modificationProblem{
+				message:   fmt.Sprintf("%s is protected by verify.protect-tests", path),
+				violation: types.Violation{Path: path, Kind: "protect-tests"},
+			})
+		}
+	}
 	for _, path := range changes {
-		if matchesPathRule(path, sc.Verify.NoModify, workspaceDir) {
-			problems = append(problems, fmt.Sprintf("%s is blocked by verify.no-modify", path))
+		if rule, ok := matchingPathRule(path, sc.Verify.NoModify, workspaceDir); ok {
+			problems = append(problems, modificationProblem{
+				message:   fmt.Sprintf("%s is blocked by verify.no-modify", path),
+				violation: types.Violation{Path: path, Rule: rule, Kind: "no-modify"},
+			})
+		}
+	}
+	for _, path := range deleted {
+		if rule, ok := matchingNoDeleteRule(path, sc.Verify.NoDelete, workspaceDir); ok {
+			problems = append(problems, modificationProblem{
+				message:   fmt.Sprintf("%s was deleted but verify.no-delete forbids it", path),
+				violation: types.Violation{Path: path, Rule: rule, Kind: "no-delete"},
+			})
 		}
 	}
 
 	if len(sc.Verify.MustModify) > 0 {
 		for _, rule := range sc.Verify.MustModify {
 			if !anyChangeMatchesRule(changes, rule, workspaceDir) {
-				problems = append(problems, fmt.Sprintf("%s in verify.must-modify was not modified", rule))
+				problems = append(problems, modificationProblem{
+					message:   fmt.Sprintf("%s in verify.must-modify was not modified", rule),
+					violation: types.Violation{Rule: rule, Kind: "must-modify"},
+				})
 			}
 		}
 	}
 
+	for _, group := range sc.Verify.MustModifyAnyOf {
+		matched := false
+		for _, rule := range group {
+			if anyChangeMatchesRule(changes, rule, workspaceDir) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			joined := strings.Join(group, ", ")
+			problems = append(problems, modificationProblem{
+				message:   fmt.Sprintf("none of [%s] in verify.must-modify-any-of was modified", joined),
+				violation: types.Violation{Rule: joined, Kind: "must-modify-any-of"},
+			})
+		}
+	}
+
 	if len(problems) == 0 {
 		return nil, nil
 	}
-	sort.Strings(problems)
+	sort.Slice(problems, func(i, j int) bool { return problems[i].message < problems[j].message })
 	return problems, nil
 }
 
-func listWorkspaceChanges(workspaceDir string) ([]string, error) {
-	cmds := [][]string{
-		{"git", "diff", "--name-only", "--diff-filter=ACDMRTUXB"},
-		{"git", "diff", "--name-only", "--diff-filter=ACDMRTUXB", "--cached"},
-		{"git", "ls-files", "--others", "--exclude-standard"},
-	}
-	paths := map[string]struct{}{}
-	for _, args := range cmds {
-		out, err := runInWorkspace(workspaceDir, args...)
-		if err != nil {
-			return nil, err
+// workspaceStatus is one `git status` snapshot of the workspace. The modification rules are checked against it and
+// --capture-status reports it, so the two always agree.
+type workspaceStatus struct {
+	// changes are the changed paths (modified, added, deleted, or untracked; staged or not), sorted. deleted is the
+	// subset that were deleted, counting a renamed file's old path.
+	changes, deleted []string
+	// porcelain is the `git status --porcelain` listing (untracked files listed individually), without root dotfiles.
+	porcelain string
+}
+
+// readWorkspaceStatus runs git status in workspaceDir and returns the snapshot.
+func readWorkspaceStatus(workspaceDir string) (workspaceStatus, error) {
+	out, err := runInWorkspace(workspaceDir, "git", "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return workspaceStatus{}, err
+	}
+	changes := map[string]struct{}{}
+	deleted := map[string]struct{}{}
+	var porcelain strings.Builder
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
 		}
-		scanner := bufio.NewScanner(bytes.NewReader(out))
-		for scanner.Scan() {
-			p := strings.TrimSpace(scanner.Text())
-			if p == "" {
-				continue
+		code, path := entry[:2], filepath.Clean(entry[3:])
+		line := entry
+		changes[path] = struct{}{}
+		if code[0] == 'D' || code[1] == 'D' {
+			deleted[path] = struct{}{}
+		}
+		// A rename or copy entry is followed by the original path.
+		if (code[0] == 'R' || code[0] == 'C') && i+1 < len(fields) {
+			i++
+			line = code + " " + fields[i] + " -> " + entry[3:]
+			if code[0] == 'R' {
+				deleted[filepath.Clean(fields[i])] = struct{}{}
 			}
-			paths[filepath.Clean(p)] = struct{}{}
 		}
-		if err := scanner.Err(); err != nil {
-			return nil, err
+		if len(filterIgnoredChanges([]string{path})) > 0 {
+			porcelain.WriteString(line)
+			porcelain.WriteString("\n")
 		}
 	}
+	return workspaceStatus{changes: sortedPaths(changes), deleted: sortedPaths(deleted), porcelain: porcelain.String()}, nil
+}
+
+// listWorkspaceChanges returns the changes and deletions of the workspace's readWorkspaceStatus.
+func listWorkspaceChanges(workspaceDir string) ([]string, []string, error) {
+	status, err := readWorkspaceStatus(workspaceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return status.changes, status.deleted, nil
+}
+
+func sortedPaths(paths map[string]struct{}) []string {
 	list := make([]string, 0, len(paths))
 	for p := range paths {
 		list = append(list, p)
 	}
 	sort.Strings(list)
+	return list
+}
+
+// listTouchedTestFiles returns the committed _test.go files that were modified or deleted in the workspace (staged or
+// not). New test files are not included.
+func listTouchedTestFiles(workspaceDir string) ([]string, error) {
+	out, err := runInWorkspace(workspaceDir, "git", "diff", "--name-only", "--no-renames", "--diff-filter=DMT", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, line := range strings.Split(string(out), "\n") {
+		p := strings.TrimSpace(line)
+		if strings.HasSuffix(p, "_test.go") {
+			list = append(list, filepath.Clean(p))
+		}
+	}
+	sort.Strings(list)
 	return list, nil
 }
 
@@ -255,13 +613,14 @@ func anyChangeMatchesRule(changes []string, rule, workspaceDir string) bool {
 	return false
 }
 
-func matchesPathRule(path string, rules []string, workspaceDir string) bool {
+// matchingPathRule returns the first of rules that path matches.
+func matchingPathRule(path string, rules []string, workspaceDir string) (string, bool) {
 	for _, rule := range rules {
 		if pathMatchesRule(path, rule, workspaceDir) {
-			return true
+			return rule, true
 		}
 	}
-	return false
+	return "", false
 }
 
 func pathMatchesRule(path, rule, workspaceDir string) bool {
@@ -283,6 +642,27 @@ func pathMatchesRule(path, rule, workspaceDir string) bool {
 	return false
 }
 
+// matchingNoDeleteRule is matchingPathRule for the deleted path: a dir rule (like "testdata", without a trailing slash)
+// whose dir was deleted can't be recognized on disk, so dirs in the workspace's HEAD count too.
+func matchingNoDeleteRule(path string, rules []string, workspaceDir string) (string, bool) {
+	for _, rule := range rules {
+		matchRule := rule
+		if !looksLikeDirRule(rule, workspaceDir) && !strings.ContainsAny(rule, "*?[") && isDirInHEAD(rule, workspaceDir) {
+			matchRule = filepath.Clean(rule) + string(filepath.Separator)
+		}
+		if pathMatchesRule(path, matchRule, workspaceDir) {
+			return rule, true
+		}
+	}
+	return "", false
+}
+
+// isDirInHEAD reports whether rule names a directory in the workspace's HEAD commit.
+func isDirInHEAD(rule, workspaceDir string) bool {
+	out, err := runInWorkspace(workspaceDir, "git", "ls-tree", "HEAD", "--", filepath.ToSlash(filepath.Clean(rule)))
+	return err == nil && strings.Contains(string(out), " tree ")
+}
+
 func looksLikeDirRule(rule, workspaceDir string) bool {
 	if strings.HasSuffix(rule, string(filepath.Separator)) {
 		return true
@@ -297,29 +677,11 @@ func applyVerifyCopies(sc *scenario.Scenario, scenarioDir, workspaceDir string)
 	}
 	undos := make([]func(), 0, len(sc.Verify.Copy))
 	for _, c := range sc.Verify.Copy {
-		src := filepath.Join(scenarioDir, c.From)
-		info, err := os.Stat(src)
-		if err != nil {
-			return nil, err
-		}
-		var dstDir string
-		if info.IsDir() {
-			dstDir, err = fsutil.SafeJoin(workspaceDir, c.To)
-		} else {
-			target := filepath.Clean(c.To)
-			// If the target looks like a directory (no extension or trailing slash),
-			// copy into that directory. Otherwise, treat c.To as a file path and use
-			// its parent directory.
-			if strings.HasSuffix(c.To, string(filepath.Separator)) || filepath.Ext(target) == "" {
-				dstDir, err = fsutil.SafeJoin(workspaceDir, target)
-			} else {
-				dstDir, err = fsutil.SafeJoin(workspaceDir, filepath.Dir(target))
-			}
-		}
+		src, dstDir, err := verifyCopyPaths(c, scenarioDir, workspaceDir)
 		if err != nil {
 			return nil, err
 		}
-		undo, err := fsutil.CopyToDir(src, dstDir, true)
+		undo, err := fsutil.CopyToDir(src, dstDir, c.OverwriteEnabled())
 		if err != nil {
 			return nil, err
 		}
@@ -334,87 +696,457 @@ func applyVerifyCopies(sc *scenario.Scenario, scenarioDir, workspaceDir string)
 	}, nil
 }
 
-func runTestList(ctx context.Context, workdir string, entries scenario.StringList, printer *output.Printer) ([]types.TestResult, error) {
+// verifyCopyPaths returns the source path and destination directory of a verify.copy step.
+func verifyCopyPaths(c scenario.CopyStep, scenarioDir, workspaceDir string) (string, string, error) {
+	src := filepath.Join(scenarioDir, c.From)
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", "", err
+	}
+	var dstDir string
+	if info.IsDir() {
+		dstDir, err = fsutil.SafeJoin(workspaceDir, c.To)
+	} else {
+		target := filepath.Clean(c.To)
+		// If the target looks like a directory (no extension or trailing slash),
+		// copy into that directory. Otherwise, treat c.To as a file path and use
+		// its parent directory.
+		if strings.HasSuffix(c.To, string(filepath.Separator)) || filepath.Ext(target) == "" {
+			dstDir, err = fsutil.SafeJoin(workspaceDir, target)
+		} else {
+			dstDir, err = fsutil.SafeJoin(workspaceDir, filepath.Dir(target))
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return src, dstDir, nil
+}
+
+// checkCopyConflicts returns a problem for each file that a verify.copy step with overwrite: false would copy over
+// (ex: the agent already created it).
+func checkCopyConflicts(sc *scenario.Scenario, scenarioDir, workspaceDir string) ([]modificationProblem, error) {
+	var problems []modificationProblem
+	for _, c := range sc.Verify.Copy {
+		if c.OverwriteEnabled() {
+			continue
+		}
+		src, dstDir, err := verifyCopyPaths(c, scenarioDir, workspaceDir)
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil || d.IsDir() {
+				return walkErr
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			if rel == "." {
+				rel = filepath.Base(src)
+			}
+			dst := filepath.Join(dstDir, rel)
+			if _, err := os.Lstat(dst); err == nil {
+				wsRel, err := filepath.Rel(workspaceDir, dst)
+				if err != nil {
+					return err
+				}
+				wsRel = filepath.ToSlash(wsRel)
+				problems = append(problems, modificationProblem{
+					message:   fmt.Sprintf("%s already exists but verify.copy from %s does not overwrite", wsRel, c.From),
+					violation: types.Violation{Path: wsRel, Rule: c.From, Kind: "copy"},
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// runTestList runs each verify.tests entry (with -json if forceJSON), up to parallelism entries at a time (<= 1 runs
+// them one by one). A failing entry is re-run up to retries more times, and passes (marked Flaky) if any attempt
+// passes. Results are in entry order either way. Parallel entries print to their own buffer, flushed in entry order,
+// so each entry's output stays in one block.
+func runTestList(ctx context.Context, workdir string, entries scenario.StringList, retries int, forceJSON bool, parallelism int, gt goTestConfig, printer *output.Printer) ([]types.TestResult, error) {
+	if parallelism <= 1 || len(entries) <= 1 {
+		var results []types.TestResult
+		for _, entry := range entries {
+			res, err := runTestEntry(ctx, workdir, entry, retries, forceJSON, gt, printer)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res)
+		}
+		return results, nil
+	}
+	if printer == nil {
+		printer = output.NewPrinter(os.Stdout)
+	}
+	// Canceled on the first error, killing the other in-flight go test processes.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	type entryRun struct {
+		printer *output.Printer
+		flush   func() error
+		res     types.TestResult
+		err     error
+		done    chan struct{}
+	}
+	runs := make([]entryRun, len(entries))
+	next := make(chan int, len(entries))
+	for i := range entries {
+		runs[i].printer, runs[i].flush = printer.Buffered()
+		runs[i].done = make(chan struct{})
+		next <- i
+	}
+	close(next)
+	for range min(parallelism, len(entries)) {
+		go func() {
+			for i := range next {
+				runs[i].res, runs[i].err = runTestEntry(ctx, workdir, entries[i], retries, forceJSON, gt, runs[i].printer)
+				if runs[i].err != nil {
+					cancel(runs[i].err)
+				}
+				close(runs[i].done)
+			}
+		}()
+	}
+	results := make([]types.TestResult, 0, len(entries))
+	var firstErr error
+	for i := range runs {
+		<-runs[i].done
+		if err := runs[i].flush(); err != nil && firstErr == nil {
+			firstErr = err
+			cancel(err)
+		}
+		if runs[i].err != nil && firstErr == nil {
+			firstErr = runs[i].err
+		}
+		results = append(results, runs[i].res)
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// runTestEntry runs one verify.tests entry for runTestList, with its retries.
+func runTestEntry(ctx context.Context, workdir, entry string, retries int, forceJSON bool, gt goTestConfig, printer *output.Printer) (types.TestResult, error) {
+	if timedOut(ctx) {
+		return notRunResult(ctx, entry), nil
+	}
+	res, err := runGoTest(ctx, workdir, entry, forceJSON, gt, printer)
+	if err != nil {
+		return types.TestResult{}, err
+	}
+	for attempt := 2; !res.Passed && attempt <= retries+1 && !timedOut(ctx); attempt++ {
+		if printer != nil {
+			if err := printer.Appf("Retrying %s (attempt %d of %d)", entry, attempt, retries+1); err != nil {
+				return types.TestResult{}, err
+			}
+		}
+		res, err = runGoTest(ctx, workdir, entry, forceJSON, gt, printer)
+		if err != nil {
+			return types.TestResult{}, err
+		}
+		res.Attempts = attempt
+		res.Flaky = res.Passed
+	}
+	markIfTimedOut(ctx, &res)
+	return res, nil
+}
+
+// mustFailPrefix prefixes verify.must-fail entries in TestResult names.
+const mustFailPrefix = "must-fail "
+
+// runMustFail runs each verify.must-fail entry and passes it only if at least one test in it failed. A run where no test
+// failed (including build failures, which report no test results) does not count.
+func runMustFail(ctx context.Context, workdir string, entries scenario.StringList, gt goTestConfig, printer *output.Printer) ([]types.TestResult, error) {
 	var results []types.TestResult
 	for _, entry := range entries {
-		res, err := runGoTest(ctx, workdir, entry, false, printer)
+		if timedOut(ctx) {
+			results = append(results, notRunResult(ctx, mustFailPrefix+entry))
+			continue
+		}
+		res, passed, total, err := runGoTestJSON(ctx, workdir, entry, gt, printer)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, res)
+		inverted := invertMustFail(res, passed, total)
+		if timedOut(ctx) {
+			// A killed run's failures don't count as the expected ones.
+			inverted.Passed = false
+			markIfTimedOut(ctx, &inverted)
+		}
+		results = append(results, inverted)
 	}
 	return results, nil
 }
 
-func runPartial(ctx context.Context, workdir string, entries scenario.StringList, printer *output.Printer) ([]types.TestResult, *float64, error) {
-	if len(entries) == 0 {
+func invertMustFail(res types.TestResult, passed, total int) types.TestResult {
+	out := types.TestResult{Name: mustFailPrefix + res.Name, Output: res.Output, Command: res.Command}
+	switch failed := total - passed; {
+	case failed > 0:
+		out.Passed = true
+	case total == 0:
+		out.Error = "expected failing tests, but no tests ran"
+		if res.Error != "" {
+			out.Error += " (" + res.Error + ")"
+		}
+	default:
+		out.Error = fmt.Sprintf("expected failing tests, but all %d passed", total)
+	}
+	return out
+}
+
+func runPartial(ctx context.Context, workdir string, v scenario.VerifyConfig, gt goTestConfig, printer *output.Printer) ([]types.TestResult, *float64, error) {
+	if len(v.PartialTests) == 0 {
 		return nil, nil, nil
 	}
 	var results []types.TestResult
-	totalTests := 0
-	totalPassed := 0
-	for _, entry := range entries {
-		res, passed, total, err := runGoTestJSON(ctx, workdir, entry, printer)
+	var counts []partialCount
+	for i, entry := range v.PartialTests {
+		if timedOut(ctx) {
+			results = append(results, notRunResult(ctx, entry))
+			counts = append(counts, partialCount{weight: v.PartialWeight(i)})
+			continue
+		}
+		res, passed, total, err := runGoTestJSON(ctx, workdir, entry, gt, printer)
 		if err != nil {
 			return nil, nil, err
 		}
+		markIfTimedOut(ctx, &res)
+		if total == 0 && res.Passed {
+			// go test passes when nothing matches, which would otherwise score like a real failure (or, per-test, not
+			// count at all).
+			res.Passed = false
+			res.Error = partialNoTestsError
+			if printer != nil {
+				if err := printer.Appf("Warning: partial-tests entry %s matched no tests", entry); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
 		results = append(results, res)
-		totalTests += total
-		totalPassed += passed
+		counts = append(counts, partialCount{passed: passed, total: total, weight: v.PartialWeight(i)})
 	}
-	var score *float64
-	if totalTests > 0 {
-		s := float64(totalPassed) / float64(totalTests)
-		score = &s
-	} else {
-		zero := 0.0
-		score = &zero
+	score := scorePartial(v.PartialMode, counts)
+	return results, &score, nil
+}
+
+// partialCount is the number of passed and total tests run by one partial-tests entry, and the entry's weight.
+type partialCount struct {
+	passed int
+	total  int
+	weight float64
+}
+
+// scorePartial computes the partial score for mode (see scenario.PartialModePerTest/PartialModePerEntry), weighing
+// each entry by its weight: per-test, an entry's tests each count weight times; per-entry, its pass fraction does.
+// Entries (or runs) with no tests, or no total weight, score 0.
+func scorePartial(mode string, counts []partialCount) float64 {
+	if mode == scenario.PartialModePerEntry {
+		sum, totalWeight := 0.0, 0.0
+		for _, c := range counts {
+			totalWeight += c.weight
+			if c.total > 0 {
+				sum += c.weight * float64(c.passed) / float64(c.total)
+			}
+		}
+		if totalWeight == 0 {
+			return 0
+		}
+		return sum / totalWeight
+	}
+	totalTests, totalPassed := 0.0, 0.0
+	for _, c := range counts {
+		totalTests += c.weight * float64(c.total)
+		totalPassed += c.weight * float64(c.passed)
 	}
-	return results, score, nil
+	if totalTests == 0 {
+		return 0
+	}
+	return totalPassed / totalTests
 }
 
-func runGoTest(ctx context.Context, workdir, entry string, forceJSON bool, printer *output.Printer) (types.TestResult, error) {
-	args, err := parseTestArgs(workdir, entry)
-	if err != nil {
-		return types.TestResult{Name: entry, Passed: false, Error: err.Error()}, nil
+// goTestConfig applies to every go test invocation in a verification.
+type goTestConfig struct {
+	flags         []string      // passed before each entry's own args (ex: -shuffle)
+	env           []string      // KEY=VALUE entries added to the environment (ex: GOOS)
+	memoryLimitMB int           // verify.memory-limit-mb, when enforced
+	testTimeout   time.Duration // verify.test-timeout; 0 means no limit
+	raceMode      string        // verify.race-mode
+}
+
+// newGoTestConfig returns the go test flags and env for verifying sc, and whether verify.memory-limit-mb is enforced.
+// buildOnly (see verifyTarget) compiles the test binaries without running them.
+func newGoTestConfig(sc *scenario.Scenario, shuffleSeed *int64, buildOnly bool) (goTestConfig, bool) {
+	gt := goTestConfig{testTimeout: sc.Verify.TestTimeout, raceMode: sc.Verify.RaceMode}
+	gt.flags = append(gt.flags, raceFlags(sc.Verify.RaceMode)...)
+	if shuffleSeed != nil {
+		gt.flags = append(gt.flags, fmt.Sprintf("-shuffle=%d", *shuffleSeed))
+	}
+	if sc.Verify.GOOS != "" {
+		gt.env = append(gt.env, "GOOS="+sc.Verify.GOOS)
+	}
+	if sc.Verify.GOARCH != "" {
+		gt.env = append(gt.env, "GOARCH="+sc.Verify.GOARCH)
+	}
+	if buildOnly {
+		gt.flags = append(gt.flags, "-exec=true")
+	}
+	if sc.Verify.MemoryLimitMB > 0 && !buildOnly {
+		flag, ok := memoryLimitFlag(sc.Verify.MemoryLimitMB)
+		if !ok {
+			return gt, false
+		}
+		gt.flags = append(gt.flags, flag)
+		gt.memoryLimitMB = sc.Verify.MemoryLimitMB
 	}
+	return gt, true
+}
+
+// goTestArgs returns the full `go test` args for an entry whose own args (see parseTestArgs) are args.
+func goTestArgs(args []string, forceJSON bool, gt goTestConfig) []string {
 	cmdArgs := []string{"test"}
 	if forceJSON {
 		cmdArgs = append(cmdArgs, "-json")
 	}
-	cmdArgs = append(cmdArgs, args...)
-	var outputBytes []byte
-	if printer != nil {
-		outputBytes, err = printer.RunCommandStreaming(ctx, workdir, "go", cmdArgs...)
-	} else {
-		cmd := exec.CommandContext(ctx, "go", cmdArgs...)
-		cmd.Dir = workdir
-		var buf bytes.Buffer
-		cmd.Stdout = io.MultiWriter(&buf, os.Stdout)
-		cmd.Stderr = io.MultiWriter(&buf, os.Stderr)
-		err = cmd.Run()
-		outputBytes = buf.Bytes()
+	cmdArgs = append(cmdArgs, gt.flags...)
+	return append(cmdArgs, args...)
+}
+
+// runGoTest runs `go test` for entry.
+func runGoTest(ctx context.Context, workdir, entry string, forceJSON bool, gt goTestConfig, printer *output.Printer) (types.TestResult, error) {
+	args, err := parseTestArgs(workdir, entry)
+	if err != nil {
+		return types.TestResult{Name: entry, Passed: false, Error: err.Error()}, nil
 	}
+	cmdArgs := goTestArgs(args, forceJSON, gt)
+	runCtx, cancel := withTestTimeout(ctx, gt.testTimeout)
+	defer cancel()
+	outputBytes, err := runStreamingEnv(runCtx, printer, workdir, gt.env, "go", cmdArgs...)
 	result := types.TestResult{
-		Name:   entry,
-		Passed: err == nil,
-		Output: string(outputBytes),
+		Name:    entry,
+		Passed:  err == nil,
+		Output:  string(outputBytes),
+		Command: commandLine(gt.env, "go", cmdArgs),
 	}
 	if err != nil {
 		result.Error = err.Error()
 	}
+	if testTimedOut(runCtx) {
+		result.Passed = false
+		result.Error = fmt.Sprintf("test timed out after %s", shortDuration(gt.testTimeout))
+	}
+	markIfOutOfMemory(&result, gt.memoryLimitMB)
+	if gt.raceMode == scenario.RaceModeWarn || gt.raceMode == scenario.RaceModeFail {
+		result.Races = countRaces(result.Output)
+	}
+	if gt.raceMode == scenario.RaceModeWarn && !result.Passed && !testTimedOut(runCtx) {
+		if _, onlyRaces := raceFailures(result.Output); onlyRaces {
+			result.Passed = true
+			result.Error = ""
+		}
+	}
 	return result, nil
 }
 
-func runGoTestJSON(ctx context.Context, workdir, entry string, printer *output.Printer) (types.TestResult, int, int, error) {
-	res, err := runGoTest(ctx, workdir, entry, true, printer)
+// commandLine returns a copy-pasteable shell command for running name with args and the KEY=VALUE env entries.
+func commandLine(env []string, name string, args []string) string {
+	words := make([]string, 0, len(env)+1+len(args))
+	for _, e := range env {
+		words = append(words, shellQuote(e))
+	}
+	words = append(words, shellQuote(name))
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote single-quotes s for sh, unless it only has characters that never need quoting.
+func shellQuote(s string) string {
+	needsQuote := func(r rune) bool {
+		safe := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:,=+@%", r)
+		return !safe
+	}
+	if s != "" && strings.IndexFunc(s, needsQuote) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runStreaming runs name with args in workdir, streaming output through printer (or directly to stdout/stderr when
+// printer is nil), and returns the combined output.
+func runStreaming(ctx context.Context, printer *output.Printer, workdir, name string, args ...string) ([]byte, error) {
+	return runStreamingEnv(ctx, printer, workdir, nil, name, args...)
+}
+
+// runStreamingEnv is runStreaming with extra KEY=VALUE env entries.
+func runStreamingEnv(ctx context.Context, printer *output.Printer, workdir string, env []string, name string, args ...string) ([]byte, error) {
+	if printer != nil {
+		return printer.RunCommandStreamingEnv(ctx, workdir, env, name, args...)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = workdir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var buf bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&buf, os.Stdout)
+	cmd.Stderr = io.MultiWriter(&buf, os.Stderr)
+	err := cmd.Run()
+	return buf.Bytes(), err
+}
+
+func runGoTestJSON(ctx context.Context, workdir, entry string, gt goTestConfig, printer *output.Printer) (types.TestResult, int, int, error) {
+	res, err := runGoTest(ctx, workdir, entry, true, gt, printer)
 	if err != nil {
 		return types.TestResult{}, 0, 0, err
 	}
 	passed, total := parseJSONCounts(res.Output)
+	if gt.raceMode == scenario.RaceModeWarn {
+		// Tests that only failed because of a race count as passed.
+		explained, _ := raceFailures(res.Output)
+		passed += explained
+	}
 	return res, passed, total, nil
 }
 
+// verifyTarget returns the "goos/goarch" that verify.goos/goarch select ("" if neither is set), and whether tests for it
+// can only be built, not run, on this host.
+func verifyTarget(sc *scenario.Scenario) (string, bool) {
+	if sc.Verify.GOOS == "" && sc.Verify.GOARCH == "" {
+		return "", false
+	}
+	goos, goarch := sc.Verify.GOOS, sc.Verify.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos + "/" + goarch, goos != runtime.GOOS || goarch != runtime.GOARCH
+}
+
+// shuffleSeedFor returns the -shuffle seed for this verification, or nil if tests run in order.
+func shuffleSeedFor(opts Options, sc *scenario.Scenario) *int64 {
+	if opts.ShuffleSeed != nil {
+		seed := *opts.ShuffleSeed
+		return &seed
+	}
+	if !opts.Shuffle && !sc.Verify.Shuffle {
+		return nil
+	}
+	seed := time.Now().UnixNano()
+	return &seed
+}
+
 func pathExists(p string) bool {
 	_, err := os.Stat(p)
 	return err == nil
@@ -461,14 +1193,21 @@ func normalizeTestTargetArg(target, workdir string) string {
 }
 
 type goTestEvent struct {
-	Action string `json:"Action"`
-	Test   string `json:"Test"`
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+	// OutputType is "error" for t.Error/t.Fatal lines and "frame" for "=== RUN"/"--- FAIL" lines (go1.25+).
+	OutputType string `json:"OutputType"`
 }
 
+// parseJSONCounts returns how many tests passed, and how many ran, in go test -json output. A test that started but
+// never finished (ex: killed by verify.test-timeout) counts as failed.
 func parseJSONCounts(output string) (int, int) {
 	scanner := bufio.NewScanner(strings.NewReader(output))
 	passed := 0
 	total := 0
+	unfinished := map[string]bool{}
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		var ev goTestEvent
@@ -478,15 +1217,22 @@ func parseJSONCounts(output string) (int, int) {
 		if ev.Test == "" {
 			continue
 		}
+		key := ev.Package + "\x00" + ev.Test
 		switch ev.Action {
+		case "run":
+			unfinished[key] = true
 		case "pass":
 			passed++
 			total++
+			delete(unfinished, key)
 		case "fail":
 			total++
+			delete(unfinished, key)
+		case "skip":
+			delete(unfinished, key)
 		}
 	}
-	return passed, total
+	return passed, total + len(unfinished)
 }
 
 func runID(start *types.RunStart, prog *types.RunProgress) string {
@@ -529,6 +1275,14 @@ func modelName(start *types.RunStart, prog *types.RunProgress) string {
 	return ""
 }
 
+func systemInfo(start *types.RunStart) *types.SystemInfo {
+	if start == nil || start.System == (types.SystemInfo{}) {
+		return nil
+	}
+	system := start.System
+	return &system
+}
+
 func startedAt(start *types.RunStart) *time.Time {
 	if start == nil {
 		return nil
@@ -548,6 +1302,20 @@ func readRunStart(path string) (*types.RunStart, error) {
 	return &rs, nil
 }
 
+// readSetupSeconds returns the setup duration recorded in the .setup-meta.json at path, or nil if it's missing (ex: the
+// workspace was set up by an older version).
+func readSetupSeconds(path string) *float64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var meta types.SetupMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil
+	}
+	return &meta.SetupSeconds
+}
+
 func readRunProgress(path string) (*types.RunProgress, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -560,6 +1328,19 @@ func readRunProgress(path string) (*types.RunProgress, error) {
 	return &rp, nil
 }
 
+// withRunFilter returns a copy of sc whose verify.tests and partial-tests run only tests matching pattern.
+func withRunFilter(sc *scenario.Scenario, pattern string) (*scenario.Scenario, error) {
+	filtered := *sc
+	var err error
+	if filtered.Verify.Tests, err = scenario.WithRunFilter("verify.tests", sc.Verify.Tests, pattern); err != nil {
+		return nil, fmt.Errorf("--run: %w", err)
+	}
+	if filtered.Verify.PartialTests, err = scenario.WithRunFilter("verify.partial-tests", sc.Verify.PartialTests, pattern); err != nil {
+		return nil, fmt.Errorf("--run: %w", err)
+	}
+	return &filtered, nil
+}
+
 func progressWithoutTranscripts(progress *types.RunProgress) *types.RunProgress {
 	if progress == nil {
 		return nil
@@ -578,42 +1359,27 @@ func reportWithoutTranscripts(report *types.VerificationReport) *types.Verificat
 	return &clone
 }
 
-func writeReport(opts Options, report *types.VerificationReport) error {
-	cleanReport := reportWithoutTranscripts(report)
-	filename := fmt.Sprintf("%s-%s-%s-%s.verify.json",
-		cleanReport.VerifiedAt.Format("2006-01-02"),
-		safePart(cleanReport.RunID, "run"),
-		safePart(cleanReport.Agent, "agent"),
-		safePart(cleanReport.Model, "model"))
-	outDir := filepath.Join(resultsDir(opts.RootPath), opts.ScenarioName)
-	if err := os.MkdirAll(outDir, 0o755); err != nil {
-		return err
-	}
-	outPath := filepath.Join(outDir, filename)
-	data, err := json.MarshalIndent(cleanReport, "", "  ")
-	if err != nil {
-		return err
+// writeOutputs writes the results file (unless OnlyReport) and the JUnit file (if requested).
+func writeOutputs(opts Options, report *types.VerificationReport) error {
+	if !opts.OnlyReport {
+		if err := writeReport(opts, report); err != nil {
+			return err
+		}
 	}
-	return os.WriteFile(outPath, data, 0o644)
-}
-
-func safePart(value, fallback string) string {
-	val := strings.TrimSpace(value)
-	if val == "" {
-		return fallback
+	if opts.JUnitPath != "" {
+		if err := writeJUnitFile(opts.JUnitPath, report); err != nil {
+			return fmt.Errorf("write junit: %w", err)
+		}
 	}
-	val = strings.ReplaceAll(val, string(os.PathSeparator), "_")
-	return val
+	return nil
 }
 
-func resultsDir(rootPath string) string {
-	if env := strings.TrimSpace(os.Getenv(resultsEnvVar)); env != "" {
-		if filepath.IsAbs(env) {
-			return filepath.Clean(env)
-		}
-		return filepath.Join(rootPath, filepath.Clean(env))
+func writeReport(opts Options, report *types.VerificationReport) error {
+	store := opts.Store
+	if store == nil {
+		store = results.NewFSStore(results.Dir(opts.RootPath))
 	}
-	return filepath.Join(rootPath, "results")
+	return store.Write(reportWithoutTranscripts(report))
 }
 
 func allPassed(results []types.TestResult) bool {
@@ -625,6 +1391,29 @@ func allPassed(results []types.TestResult) bool {
 	return true
 }
 
+func printResult(opts Options, printer *output.Printer, report *types.VerificationReport) {
+	if opts.GitHubAnnotations {
+		// Workflow commands must be unstyled and start at column 0, so bypass the printer.
+		fmt.Print(AnnotationsString(report))
+		printBaseline(opts, printer, report)
+		return
+	}
+	printSummary(printer, report)
+	printBaseline(opts, printer, report)
+}
+
+func printBaseline(opts Options, printer *output.Printer, report *types.VerificationReport) {
+	if opts.Baseline == nil {
+		return
+	}
+	text := CompareBaseline(opts.Baseline, report).String()
+	if printer == nil {
+		fmt.Print(text)
+		return
+	}
+	_ = printer.App(text)
+}
+
 func printSummary(printer *output.Printer, report *types.VerificationReport) {
 	summary := SummaryString(report)
 	if summary == "" {
@@ -649,11 +1438,17 @@ func SummaryString(report *types.VerificationReport) string {
 		if t.Passed {
 			status = "PASS"
 		}
+		if t.Flaky {
+			status += fmt.Sprintf(" (flaky: passed on attempt %d)", t.Attempts)
+		}
+		if t.Races > 0 {
+			status += fmt.Sprintf(" (%d data race(s))", t.Races)
+		}
 		builder.WriteString(fmt.Sprintf("- %s%s: %s\n", prefix, t.Name, status))
 		if t.Passed {
 			return
 		}
-		if errText := strings.TrimSpace(t.Error); errText != "" {
+		if errText := failureText(t); errText != "" {
 			for _, line := range strings.Split(errText, "\n") {
 				line = strings.TrimSpace(line)
 				if line == "" {
@@ -669,8 +1464,21 @@ func SummaryString(report *types.VerificationReport) string {
 	for _, t := range report.PartialTests {
 		appendTest("partial ", t)
 	}
+	for _, st := range report.Stages {
+		status := "FAIL"
+		if st.Success {
+			status = "PASS"
+		}
+		builder.WriteString(fmt.Sprintf("- stage %s: %s\n", st.Name, status))
+	}
+	if report.RunFilter != "" {
+		builder.WriteString(fmt.Sprintf("Run filter: -run '%s' (only matching tests ran)\n", report.RunFilter))
+	}
+	if report.Races > 0 && report.RaceMode == scenario.RaceModeWarn {
+		builder.WriteString(fmt.Sprintf("Data races: %d (race-mode warn: races don't fail tests)\n", report.Races))
+	}
 	if report.PartialScore != nil && *report.PartialScore < 1 {
-		builder.WriteString(fmt.Sprintf("Partial success: %.2f\n", *report.PartialScore))
+		builder.WriteString(fmt.Sprintf("Partial success: %s\n", types.FormatFloat(*report.PartialScore)))
 	}
 	if report.Success {
 		builder.WriteString("Result: success\n")
@@ -699,6 +1507,9 @@ func DetailedString(report *types.VerificationReport) string {
 				continue
 			}
 			builder.WriteString(fmt.Sprintf("%s%s output:\n", prefix, t.Name))
+			if t.Command != "" {
+				builder.WriteString("ran: " + t.Command + "\n")
+			}
 			if t.Output != "" {
 				builder.WriteString(strings.TrimSpace(t.Output))
 				builder.WriteString("\n")
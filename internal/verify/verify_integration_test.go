@@ -1,33 +1,46 @@
 package verify_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/results"
 	"github.com/codalotl/goagentbench/internal/scenario"
+	"github.com/codalotl/goagentbench/internal/types"
 	"github.com/codalotl/goagentbench/internal/verify"
+	"github.com/codalotl/goagentbench/internal/workspace"
 )
 
 func TestRunEnforcesModificationRules(t *testing.T) {
 	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
 
 	tests := []struct {
-		name        string
-		apply       func(t *testing.T, repo string)
-		wantSuccess bool
+		name           string
+		apply          func(t *testing.T, repo string)
+		noDelete       []string
+		noModify       []string // replaces baseScenario's no-modify, if set
+		wantSuccess    bool
+		wantViolations []types.Violation
 	}{
 		{
 			name: "requiresMustModifyChanges",
 			apply: func(t *testing.T, repo string) {
 				// keep repo clean so must-modify fails
 			},
-			wantSuccess: false,
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Rule: "allowed", Kind: "must-modify"}},
 		},
 		{
 			name: "failsOnNoModifyChanges",
@@ -35,14 +48,16 @@ func TestRunEnforcesModificationRules(t *testing.T) {
 				writeFile(t, repo, "allowed/base.txt", "changed")
 				writeFile(t, repo, "forbidden/secret.txt", "leaked")
 			},
-			wantSuccess: false,
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Path: "forbidden/secret.txt", Rule: "forbidden/secret.txt", Kind: "no-modify"}},
 		},
 		{
 			name: "mustModifyRuleNotSatisfied",
 			apply: func(t *testing.T, repo string) {
 				writeFile(t, repo, "other/change.txt", "update")
 			},
-			wantSuccess: false,
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Rule: "allowed", Kind: "must-modify"}},
 		},
 		{
 			name: "directoryRuleIsNotRecursive",
@@ -50,7 +65,33 @@ func TestRunEnforcesModificationRules(t *testing.T) {
 				require.NoError(t, os.MkdirAll(filepath.Join(repo, "allowed/sub"), 0o755))
 				writeFile(t, repo, "allowed/sub/nested.txt", "nested change")
 			},
-			wantSuccess: false,
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Rule: "allowed", Kind: "must-modify"}},
+		},
+		{
+			name: "failsOnNoDeleteDeletions",
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+				// Modifying a no-delete file is fine; deleting one (even via a staged rename) isn't.
+				writeFile(t, repo, "allowed/sub/sub1.txt", "changed")
+				runGit(t, repo, "mv", "forbidden/secret.txt", "allowed/secret.txt")
+			},
+			noDelete:       []string{"allowed/sub/", "forbidden/*.txt"},
+			noModify:       []string{},
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Path: "forbidden/secret.txt", Rule: "forbidden/*.txt", Kind: "no-delete"}},
+		},
+		{
+			name: "failsOnDeletedNoDeleteDir",
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+				// Once the whole dir is gone, "allowed/sub" (no trailing slash) is only a dir in git.
+				require.NoError(t, os.RemoveAll(filepath.Join(repo, "allowed", "sub")))
+			},
+			noDelete:       []string{"allowed/sub"},
+			noModify:       []string{},
+			wantSuccess:    false,
+			wantViolations: []types.Violation{{Path: "allowed/sub/sub1.txt", Rule: "allowed/sub", Kind: "no-delete"}},
 		},
 		{
 			name: "passesWithAllowedChangesAndIgnoresMetadata",
@@ -70,32 +111,730 @@ func TestRunEnforcesModificationRules(t *testing.T) {
 			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
 			tt.apply(t, repo)
 
-			opts := verify.Options{
-				ScenarioName:  scenarioName,
-				WorkspacePath: workspaceRoot,
-				RootPath:      workspaceRoot,
-				OnlyReport:    true,
-				Printer:       output.NewPrinter(nil),
+			sc := baseScenario(scenarioName)
+			sc.Verify.NoDelete = tt.noDelete
+			if tt.noModify != nil {
+				sc.Verify.NoModify = tt.noModify
 			}
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, tt.wantSuccess, report.Success)
 
-			res, err := verify.Run(context.Background(), opts, baseScenario(scenarioName))
-			require.NoError(t, err)
-			require.NotNil(t, res)
-			require.NotNil(t, res.Report)
-			require.Equal(t, tt.wantSuccess, res.Report.Success)
+			require.Equal(t, tt.wantViolations, report.Violations)
+			if tt.wantSuccess {
+				require.Empty(t, report.Tests)
+				return
+			}
+
+			require.Len(t, report.Tests, 1)
+			require.False(t, report.Tests[0].Passed)
+			require.NotEmpty(t, report.Tests[0].Output)
+			require.Empty(t, report.Tests[0].Error)
+		})
+	}
+}
+
+func TestRunReportsViolations(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	run := func(t *testing.T, apply func(repo string)) *types.VerificationReport {
+		t.Helper()
+		workspaceRoot := t.TempDir()
+		scenarioName := "violations-scenario"
+		repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+		apply(repo)
+
+		sc := baseScenario(scenarioName)
+		sc.Verify.MustModify = scenario.StringList{"allowed", "docs"}
+		report := runVerify(t, workspaceRoot, scenarioName, sc)
+		require.False(t, report.Success)
+		require.Len(t, report.Tests, 1)
+		require.Empty(t, report.Tests[0].Error)
+		return report
+	}
+
+	t.Run("noChanges", func(t *testing.T) {
+		report := run(t, func(repo string) {})
+		require.Equal(t, []types.Violation{
+			{Rule: "allowed", Kind: "must-modify"},
+			{Rule: "docs", Kind: "must-modify"},
+		}, report.Violations)
+		require.Equal(t, "workspace has no changes but verify.must-modify requires modifications", report.Tests[0].Output)
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		report := run(t, func(repo string) {
+			writeFile(t, repo, "allowed/base.txt", "changed")
+			writeFile(t, repo, "forbidden/secret.txt", "leaked")
+		})
+		require.Equal(t, []types.Violation{
+			{Rule: "docs", Kind: "must-modify"},
+			{Path: "forbidden/secret.txt", Rule: "forbidden/secret.txt", Kind: "no-modify"},
+		}, report.Violations)
+		require.Equal(t, "docs in verify.must-modify was not modified\nforbidden/secret.txt is blocked by verify.no-modify", report.Tests[0].Output)
+
+		data, err := json.Marshal(report.Violations)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"rule":"docs","kind":"must-modify"},{"path":"forbidden/secret.txt","rule":"forbidden/secret.txt","kind":"no-modify"}]`, string(data))
+	})
+}
+
+func TestRunRequiresGitRepo(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	t.Run("notSetUp", func(t *testing.T) {
+		workspaceRoot := t.TempDir()
+		scenarioName := "no-git-scenario"
+		dir := filepath.Join(workspaceRoot, scenarioName)
+		writeFile(t, dir, "allowed/base.txt", "changed")
+
+		_, err := verify.Run(context.Background(), verifyOptions(workspaceRoot, scenarioName), baseScenario(scenarioName))
+		require.EqualError(t, err, fmt.Sprintf("workspace %s is not a git repository; run setup", dir))
+	})
+
+	t.Run("agentDeletedGit", func(t *testing.T) {
+		workspaceRoot := t.TempDir()
+		scenarioName := "deleted-git-scenario"
+		repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+		writeFile(t, repo, ".run-start.json", `{"run_id":"run_1"}`)
+		writeFile(t, repo, "allowed/base.txt", "changed")
+		require.NoError(t, os.RemoveAll(filepath.Join(repo, ".git")))
+
+		report := runVerify(t, workspaceRoot, scenarioName, baseScenario(scenarioName))
+		require.False(t, report.Success)
+		require.Equal(t, []types.Violation{{Path: ".git", Kind: "git-deleted"}}, report.Violations)
+		require.Len(t, report.Tests, 1)
+		require.Equal(t, ".git was deleted, so the agent's changes can't be checked", report.Tests[0].Output)
+		require.Nil(t, report.LinesAdded)
+	})
+}
+
+func TestRecordUnverified(t *testing.T) {
+	workspaceRoot := t.TempDir()
+	scenarioName := "unverified-scenario"
+	store := &results.MemoryStore{}
+	opts := verify.Options{ScenarioName: scenarioName, WorkspacePath: workspaceRoot, RootPath: workspaceRoot, Store: store}
+
+	_, err := verify.RecordUnverified(opts)
+	require.ErrorContains(t, err, "no run progress at")
+
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, ".run-start.json", `{"run_id":"run_1","agent":"codex","model":"gpt-5.2-high"}`)
+	writeFile(t, repo, ".run-progress.json", `{"duration_seconds":42,"token_usage":{"input":100,"output":20,"total":120,"cost":0.5}}`)
+	writeFile(t, repo, "allowed/new.txt", "one\ntwo\n")
+
+	rep, err := verify.RecordUnverified(opts)
+	require.NoError(t, err)
+	require.True(t, rep.Unverified)
+	require.False(t, rep.Success)
+	require.Empty(t, rep.Tests)
+	require.Equal(t, "run_1", rep.RunID)
+	require.Equal(t, "codex", rep.Agent)
+	require.Equal(t, 0.5, rep.Progress.TokenUsage.Cost)
+	require.Equal(t, 2, *rep.LinesAdded)
+
+	records, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.True(t, records[0].Report.Unverified)
+}
+
+func TestRunEnforcesMustModifyAnyOf(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	tests := []struct {
+		name        string
+		apply       func(t *testing.T, repo string)
+		wantSuccess bool
+		wantError   string
+	}{
+		{
+			name: "noChanges",
+			apply: func(t *testing.T, repo string) {
+			},
+			wantError: "workspace has no changes but verify.must-modify-any-of requires modifications",
+		},
+		{
+			name: "groupSatisfiedByEitherEntry",
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/sub/sub1.txt", "changed")
+				writeFile(t, repo, "docs/notes.md", "notes")
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "groupNotSatisfied",
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+				writeFile(t, repo, "docs/notes.md", "notes")
+			},
+			wantError: "none of [allowed/sub, other] in verify.must-modify-any-of was modified",
+		},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "integration-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			tt.apply(t, repo)
+
+			sc := baseScenario(scenarioName)
+			sc.Verify.MustModify = nil
+			sc.Verify.MustModifyAnyOf = []scenario.StringList{{"allowed/sub", "other"}, {"docs", "README.md"}}
+
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, tt.wantSuccess, report.Success)
 			if tt.wantSuccess {
-				require.Empty(t, res.Report.Tests)
 				return
 			}
+			require.Len(t, report.Tests, 1)
+			require.Equal(t, tt.wantError, report.Tests[0].Output)
+		})
+	}
+}
+
+func TestRunCaptureStatus(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	run := func(t *testing.T, captureStatus bool) *types.VerificationReport {
+		t.Helper()
+		workspaceRoot := t.TempDir()
+		scenarioName := "status-scenario"
+		repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+		writeFile(t, repo, "allowed/old.txt", "renamed")
+		runGit(t, repo, "add", ".")
+		runGit(t, repo, "commit", "-m", "add old.txt")
+		runGit(t, repo, "mv", "allowed/old.txt", "allowed/renamed.txt")
+		writeFile(t, repo, "allowed/base.txt", "staged")
+		runGit(t, repo, "add", "allowed/base.txt")
+		writeFile(t, repo, "allowed/sub/sub1.txt", "unstaged")
+		writeFile(t, repo, "allowed/new/added.txt", "new")
+		writeFile(t, repo, ".run-start.json", "{}")
+
+		opts := verifyOptions(workspaceRoot, scenarioName)
+		opts.CaptureStatus = captureStatus
+		res, err := verify.Run(context.Background(), opts, baseScenario(scenarioName))
+		require.NoError(t, err)
+		return res.Report
+	}
+
+	report := run(t, true)
+	require.True(t, report.Success)
+	require.Equal(t, "M  allowed/base.txt\nR  allowed/old.txt -> allowed/renamed.txt\n M allowed/sub/sub1.txt\n?? allowed/new/added.txt\n", report.GitStatus)
+
+	require.Empty(t, run(t, false).GitStatus)
+}
+
+func TestRunCaptureDiff(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	run := func(t *testing.T, apply func(repo string), captureDiff bool) string {
+		t.Helper()
+		workspaceRoot := t.TempDir()
+		scenarioName := "diff-scenario"
+		repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+		apply(repo)
+		opts := verifyOptions(workspaceRoot, scenarioName)
+		opts.CaptureDiff = captureDiff
+		res, err := verify.Run(context.Background(), opts, baseScenario(scenarioName))
+		require.NoError(t, err)
+		require.True(t, res.Report.Success)
+		return res.Report.Diff
+	}
+
+	t.Run("captured", func(t *testing.T) {
+		diff := run(t, func(repo string) {
+			writeFile(t, repo, "allowed/base.txt", "changed\n")
+			writeFile(t, repo, "allowed/new.txt", "brand new\n")
+			writeFile(t, repo, ".run-progress.json", "{}")
+		}, true)
+		require.Contains(t, diff, "diff --git a/allowed/base.txt b/allowed/base.txt")
+		require.Contains(t, diff, "-original")
+		require.Contains(t, diff, "+changed")
+		require.Contains(t, diff, "+++ b/allowed/new.txt")
+		require.Contains(t, diff, "+brand new")
+		require.NotContains(t, diff, ".run-progress.json")
+		require.NotContains(t, diff, "diff truncated")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		diff := run(t, func(repo string) {
+			writeFile(t, repo, "allowed/base.txt", strings.Repeat("a long line of generated output\n", 20000))
+		}, true)
+		require.Less(t, len(diff), 260<<10)
+		require.True(t, strings.HasSuffix(diff, " bytes]\n"), diff[len(diff)-100:])
+		require.Contains(t, diff, "[diff truncated: showing ")
+	})
+
+	t.Run("optIn", func(t *testing.T) {
+		diff := run(t, func(repo string) {
+			writeFile(t, repo, "allowed/base.txt", "changed\n")
+		}, false)
+		require.Empty(t, diff)
+	})
+}
+
+func TestRunAllowedGoVersions(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
 
-			require.Len(t, res.Report.Tests, 1)
-			require.False(t, res.Report.Tests[0].Passed)
-			require.NotEmpty(t, res.Report.Tests[0].Error)
+	for _, tt := range []struct {
+		name       string
+		constraint string
+		wantErr    string
+	}{
+		{name: "unconstrained"},
+		{name: "inRange", constraint: ">=1.0,<100.0"},
+		{name: "outOfRange", constraint: "<1.0", wantErr: `is outside allowed-go-versions "<1.0"; not verifying`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "go-version-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			writeFile(t, repo, "allowed/base.txt", "changed")
+			sc := baseScenario(scenarioName)
+			sc.AllowedGoVersions = tt.constraint
+
+			res, err := verify.Run(context.Background(), verifyOptions(workspaceRoot, scenarioName), sc)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Nil(t, res)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, res.Report.Success)
+			require.True(t, strings.HasPrefix(res.Report.GoVersion, "go1."), res.Report.GoVersion)
 		})
 	}
 }
 
+func TestRunModTidyGate(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	tests := []struct {
+		name        string
+		goMod       string
+		wantSuccess bool
+	}{
+		{
+			name:        "tidy",
+			goMod:       "module example.com/m\n\ngo 1.21\n",
+			wantSuccess: true,
+		},
+		{
+			name:        "untidy",
+			goMod:       "module example.com/m\n\n\n\ngo 1.21\n",
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "mod-tidy-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			writeFile(t, repo, "go.mod", tt.goMod)
+			writeFile(t, repo, "m.go", "package m\n")
+			runGit(t, repo, "add", ".")
+			runGit(t, repo, "commit", "-m", "add module")
+			writeFile(t, repo, "allowed/base.txt", "changed")
+			require.NoError(t, os.Chmod(filepath.Join(repo, "go.mod"), 0o600))
+
+			sc := baseScenario(scenarioName)
+			sc.Verify.ModTidy = true
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, tt.wantSuccess, report.Success)
+			require.Len(t, report.Tests, 1)
+			require.Equal(t, "verify.mod-tidy", report.Tests[0].Name)
+			if !tt.wantSuccess {
+				require.Contains(t, report.Tests[0].Error, "go.mod")
+			}
+
+			// The check must restore go.mod, including its mode, so it never counts as an agent change.
+			got, err := os.ReadFile(filepath.Join(repo, "go.mod"))
+			require.NoError(t, err)
+			require.Equal(t, tt.goMod, string(got))
+			info, err := os.Stat(filepath.Join(repo, "go.mod"))
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			require.NoFileExists(t, filepath.Join(repo, "go.sum"))
+		})
+	}
+}
+
+func TestRunNoNewDepsGate(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	const baseGoMod = "module example.com/m\n\ngo 1.21\n\nrequire example.com/a v1.0.0\n"
+	tests := []struct {
+		name          string
+		goMod         string
+		commit        bool // the agent commits its go.mod
+		wantSuccess   bool
+		wantAddedDeps []string
+		wantError     string
+	}{
+		{
+			name:        "unchanged",
+			goMod:       baseGoMod,
+			wantSuccess: true,
+		},
+		{
+			name: "addedDirectDep",
+			goMod: `module example.com/m
+
+go 1.21
+
+require (
+	example.com/a v1.1.0
+	example.com/allowed v0.1.0
+	example.com/b v0.2.0
+	example.com/c v0.3.0 // indirect
+)
+`,
+			wantAddedDeps: []string{"example.com/allowed", "example.com/b"},
+			wantError:     "go.mod adds direct dependencies: example.com/b",
+		},
+		{
+			name:          "onlyAllowedDep",
+			goMod:         baseGoMod + "require example.com/allowed v0.1.0\n",
+			wantSuccess:   true,
+			wantAddedDeps: []string{"example.com/allowed"},
+		},
+		{
+			name:          "committedDep",
+			goMod:         baseGoMod + "require example.com/b v0.2.0\n",
+			commit:        true,
+			wantAddedDeps: []string{"example.com/b"},
+			wantError:     "go.mod adds direct dependencies: example.com/b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "no-new-deps-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			writeFile(t, repo, "go.mod", baseGoMod)
+			runGit(t, repo, "add", ".")
+			runGit(t, repo, "commit", "-m", "add module")
+			sc := baseScenario(scenarioName)
+			sc.Commit = gitOutput(t, repo, "rev-parse", "HEAD")
+			writeFile(t, repo, "allowed/base.txt", "changed")
+			writeFile(t, repo, "go.mod", tt.goMod)
+			if tt.commit {
+				runGit(t, repo, "commit", "-m", "add dependency", "go.mod")
+			}
+
+			sc.Verify.NoNewDeps = true
+			sc.Verify.NoNewDepsAllow = []string{"example.com/allowed"}
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, tt.wantSuccess, report.Success)
+			require.Equal(t, tt.wantAddedDeps, report.AddedDeps)
+			require.Len(t, report.Tests, 1)
+			require.Equal(t, "verify.no-new-deps", report.Tests[0].Name)
+			require.Equal(t, tt.wantError, report.Tests[0].Error)
+		})
+	}
+}
+
+func TestRunRecordsDiffSize(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "diff-size-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "allowed/base.txt", "line1\nline2\n") // 1 deleted, 2 added
+	writeFile(t, repo, "allowed/new.txt", "a\nb\nc")         // untracked: 3 added
+	writeFile(t, repo, ".run-progress.json", "{\n}\n")       // metadata: ignored
+
+	report := runVerify(t, workspaceRoot, scenarioName, baseScenario(scenarioName))
+	require.NotNil(t, report.LinesAdded)
+	require.NotNil(t, report.LinesDeleted)
+	require.Equal(t, 5, *report.LinesAdded)
+	require.Equal(t, 1, *report.LinesDeleted)
+}
+
+func TestRunFoldsSetupSeconds(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "setup-meta-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, ".setup-meta.json", `{"started_at":"2026-01-02T03:04:05Z","setup_seconds":4.5}`)
+
+	report := runVerify(t, workspaceRoot, scenarioName, baseScenario(scenarioName))
+	require.True(t, report.Success)
+	require.NotNil(t, report.SetupSeconds)
+	require.InDelta(t, 4.5, *report.SetupSeconds, 1e-9)
+}
+
+func TestRunMustFailInvertsResults(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "must-fail-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"guard/guard_test.go":   "package guard\n\nimport \"testing\"\n\nfunc TestGuard(t *testing.T) { t.Fatal(\"still failing\") }\n\nfunc TestOK(t *testing.T) {}\n",
+		"broken/broken_test.go": "package broken\n\nfunc TestBroken(t *testing.T) {}\n", // does not compile
+	})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.MustFail = scenario.StringList{"./guard -run TestGuard", "./guard -run TestOK", "./broken"}
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 3)
+
+	require.Equal(t, "must-fail ./guard -run TestGuard", report.Tests[0].Name)
+	require.True(t, report.Tests[0].Passed)
+
+	require.False(t, report.Tests[1].Passed)
+	require.Contains(t, report.Tests[1].Error, "all 1 passed")
+
+	// A build failure is not an expected failure.
+	require.False(t, report.Tests[2].Passed)
+	require.Contains(t, report.Tests[2].Error, "no tests ran")
+
+	sc.Verify.MustFail = scenario.StringList{"./guard -run TestGuard"}
+	require.True(t, runVerify(t, workspaceRoot, scenarioName, sc).Success)
+}
+
+func TestRunProtectTests(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	tests := []struct {
+		name         string
+		protect      bool
+		apply        func(t *testing.T, repo string)
+		wantSuccess  bool
+		wantProblems string
+	}{
+		{
+			name:    "deletedTestFails",
+			protect: true,
+			apply: func(t *testing.T, repo string) {
+				require.NoError(t, os.Remove(filepath.Join(repo, "allowed/base_test.go")))
+			},
+			wantProblems: "allowed/base_test.go is protected by verify.protect-tests",
+		},
+		{
+			name:    "modifiedTestFails",
+			protect: true,
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+				writeFile(t, repo, "other/other_test.go", "package other\n")
+			},
+			wantProblems: "other/other_test.go is protected by verify.protect-tests",
+		},
+		{
+			name:    "newTestAllowed",
+			protect: true,
+			apply: func(t *testing.T, repo string) {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+				writeFile(t, repo, "allowed/new_test.go", "package allowed\n")
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "deletedTestAllowedWithoutProtect",
+			apply: func(t *testing.T, repo string) {
+				require.NoError(t, os.Remove(filepath.Join(repo, "allowed/base_test.go")))
+			},
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "protect-tests-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			writeFile(t, repo, "allowed/base_test.go", "package allowed\n")
+			writeFile(t, repo, "other/other_test.go", "package other // original\n")
+			runGit(t, repo, "add", ".")
+			runGit(t, repo, "commit", "-m", "add tests")
+			tt.apply(t, repo)
+
+			sc := baseScenario(scenarioName)
+			sc.Verify.ProtectTests = tt.protect
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, tt.wantSuccess, report.Success)
+			if tt.wantProblems != "" {
+				require.Len(t, report.Tests, 1)
+				require.Equal(t, tt.wantProblems, report.Tests[0].Output)
+			}
+		})
+	}
+}
+
+func TestRunShuffleRecordsSeed(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "shuffle-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"p/p_test.go": "package p\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n\nfunc TestB(t *testing.T) {}\n",
+	})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./p"}
+	sc.Verify.PartialTests = scenario.StringList{"./p"}
+	seed := int64(42)
+	opts := verifyOptions(workspaceRoot, scenarioName)
+	opts.ShuffleSeed = &seed
+	res, err := verify.Run(context.Background(), opts, sc)
+	require.NoError(t, err)
+	require.True(t, res.Report.Success)
+	require.NotNil(t, res.Report.ShuffleSeed)
+	require.Equal(t, int64(42), *res.Report.ShuffleSeed)
+	require.Contains(t, res.Report.PartialTests[0].Output, "-test.shuffle 42")
+	// JSON counting still sees both tests.
+	require.NotNil(t, res.Report.PartialScore)
+	require.Equal(t, 1.0, *res.Report.PartialScore)
+
+	sc.Verify.Shuffle = true
+	require.NotNil(t, runVerify(t, workspaceRoot, scenarioName, sc).ShuffleSeed)
+}
+
+func TestRunRetriesFlakyTests(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	for _, retries := range []int{0, 2} {
+		t.Run(fmt.Sprintf("retries=%d", retries), func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "retries-scenario"
+			// The test fails on its first run only: it leaves a marker outside the workspace and passes once it exists.
+			marker := filepath.Join(t.TempDir(), "ran-once")
+			repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{"p/p_test.go": fmt.Sprintf(`package p
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFlaky(t *testing.T) {
+	if _, err := os.Stat(%q); err != nil {
+		_ = os.WriteFile(%q, nil, 0o644)
+		t.Fatal("first run fails")
+	}
+}
+`, marker, marker)})
+			writeFile(t, repo, "allowed/base.txt", "changed")
+
+			sc := baseScenario(scenarioName)
+			sc.Verify.Tests = scenario.StringList{"./p"}
+			sc.Verify.Retries = retries
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Len(t, report.Tests, 1)
+			got := report.Tests[0]
+			if retries == 0 {
+				require.False(t, report.Success)
+				require.False(t, got.Flaky)
+				require.Zero(t, got.Attempts)
+				return
+			}
+			require.True(t, report.Success)
+			require.True(t, got.Passed)
+			require.True(t, got.Flaky)
+			require.Equal(t, 2, got.Attempts)
+			require.Contains(t, verify.SummaryString(report), "./p: PASS (flaky: passed on attempt 2)")
+		})
+	}
+}
+
+func TestRunRecordsGoTestCommand(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "command-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"p/p_test.go": "package p\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) { t.Fatal(\"boom\") }\n",
+	})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"p -run 'TestA|TestB'"}
+	sc.Verify.PartialTests = scenario.StringList{"./p"}
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.Len(t, report.Tests, 1)
+	require.Equal(t, "go test ./p -run 'TestA|TestB'", report.Tests[0].Command)
+	require.Equal(t, "go test -json ./p", report.PartialTests[0].Command)
+	require.Contains(t, verify.DetailedString(report), "ran: go test ./p -run 'TestA|TestB'\n")
+}
+
+func TestRunCrossTargetBuildsOnly(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	goos := "windows"
+	if runtime.GOOS == "windows" {
+		goos = "linux"
+	}
+	workspaceRoot := t.TempDir()
+	scenarioName := "cross-target-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"p/p_test.go":         "package p\n\nimport \"testing\"\n\nfunc TestFails(t *testing.T) { t.Fatal(\"runs on host only\") }\n",
+		"p/p_" + goos + ".go": "package p\n\nvar _ = undefinedOnTarget\n",
+	})
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.MustModify = nil
+	sc.Verify.Tests = scenario.StringList{"./p"}
+	sc.Verify.GOOS = goos
+
+	// The target-only file doesn't compile.
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Equal(t, goos+"/"+runtime.GOARCH, report.Target)
+	require.True(t, report.BuildOnly)
+	require.Contains(t, report.Tests[0].Output, "undefinedOnTarget")
+
+	// Once it compiles, the test binary is built but not run, so the failing test doesn't matter.
+	writeFile(t, repo, "p/p_"+goos+".go", "package p\n")
+	require.True(t, runVerify(t, workspaceRoot, scenarioName, sc).Success)
+
+	sc.Verify.PartialTests = scenario.StringList{"./p"}
+	_, err := verify.Run(context.Background(), verifyOptions(workspaceRoot, scenarioName), sc)
+	require.ErrorContains(t, err, "partial-tests need to run tests")
+}
+
+func TestRunReportsMissingTools(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "missing-tools-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	gitPath, err := exec.LookPath("git")
+	require.NoError(t, err)
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./..."}
+	opts := verifyOptions(workspaceRoot, scenarioName)
+
+	// PATH with git but no go.
+	binDir := t.TempDir()
+	require.NoError(t, os.Symlink(gitPath, filepath.Join(binDir, "git")))
+	t.Setenv("PATH", binDir)
+	_, err = verify.Run(context.Background(), opts, sc)
+	require.ErrorContains(t, err, "go toolchain not found")
+
+	// Scenarios without go checks only need git.
+	sc.Verify.Tests = nil
+	require.True(t, runVerify(t, workspaceRoot, scenarioName, sc).Success)
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = verify.Run(context.Background(), opts, sc)
+	require.ErrorContains(t, err, "git not found")
+}
+
 func baseScenario(name string) *scenario.Scenario {
 	return &scenario.Scenario{
 		Name:   name,
@@ -114,6 +853,39 @@ func baseScenario(name string) *scenario.Scenario {
 	}
 }
 
+// verifyOptions returns the Options the integration tests verify scenarioName's workspace under workspaceRoot with.
+func verifyOptions(workspaceRoot, scenarioName string) verify.Options {
+	return verify.Options{
+		ScenarioName:  scenarioName,
+		WorkspacePath: workspaceRoot,
+		RootPath:      workspaceRoot,
+		OnlyReport:    true,
+		Printer:       output.NewPrinter(nil),
+	}
+}
+
+// runVerify verifies scenarioName's workspace under workspaceRoot against sc with verifyOptions and returns the report.
+func runVerify(t *testing.T, workspaceRoot, scenarioName string, sc *scenario.Scenario) *types.VerificationReport {
+	t.Helper()
+	res, err := verify.Run(context.Background(), verifyOptions(workspaceRoot, scenarioName), sc)
+	require.NoError(t, err)
+	require.NotNil(t, res.Report)
+	return res.Report
+}
+
+// initGoRepo is initIntegrationRepo plus a committed example.com/m go.mod and files (relative path to content).
+func initGoRepo(t *testing.T, workspaceRoot, scenarioName string, files map[string]string) string {
+	t.Helper()
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "go.mod", "module example.com/m\n\ngo 1.21\n")
+	for name, content := range files {
+		writeFile(t, repo, name, content)
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "add module")
+	return repo
+}
+
 func initIntegrationRepo(t *testing.T, workspaceRoot, scenarioName string) string {
 	t.Helper()
 	dir := filepath.Join(workspaceRoot, scenarioName)
@@ -140,6 +912,12 @@ func writeFile(t *testing.T, dir, relative, content string) {
 }
 
 func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	gitOutput(t, dir, args...)
+}
+
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
 	t.Helper()
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
@@ -147,4 +925,552 @@ func runGit(t *testing.T, dir string, args ...string) {
 	if err != nil {
 		t.Fatalf("git %v failed: %v\n%s", args, err, string(output))
 	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestRunVerifyCopyOverwrite(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+
+	for _, overwrite := range []bool{true, false} {
+		t.Run(fmt.Sprintf("overwrite=%v", overwrite), func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "copy-overwrite-scenario"
+			writeFile(t, filepath.Join(scenarioRoot, scenarioName), "extra.txt", "from scenario")
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			writeFile(t, repo, "allowed/base.txt", "changed")
+			writeFile(t, repo, "allowed/extra.txt", "from agent")
+
+			sc := baseScenario(scenarioName)
+			sc.Verify.Copy = []scenario.CopyStep{{From: "extra.txt", To: "allowed/extra.txt", Overwrite: &overwrite}}
+			report := runVerify(t, workspaceRoot, scenarioName, sc)
+			require.Equal(t, overwrite, report.Success)
+			if !overwrite {
+				require.Len(t, report.Tests, 1)
+				require.Equal(t, "allowed/extra.txt already exists but verify.copy from extra.txt does not overwrite", report.Tests[0].Output)
+				require.Equal(t, []types.Violation{{Path: "allowed/extra.txt", Rule: "extra.txt", Kind: "copy"}}, report.Violations)
+			}
+			data, err := os.ReadFile(filepath.Join(repo, "allowed/extra.txt"))
+			require.NoError(t, err)
+			require.Equal(t, "from agent", string(data))
+		})
+	}
+}
+
+func TestRunPostHook(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	for _, wantSuccess := range []bool{true, false} {
+		t.Run(fmt.Sprintf("success=%v", wantSuccess), func(t *testing.T) {
+			workspaceRoot := t.TempDir()
+			scenarioName := "post-hook-scenario"
+			repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+			if wantSuccess {
+				writeFile(t, repo, "allowed/base.txt", "changed")
+			}
+			outFile := filepath.Join(t.TempDir(), "hook.txt")
+
+			sc := baseScenario(scenarioName)
+			// The hook fails, which must not change the result.
+			sc.Verify.PostHook = fmt.Sprintf("echo $GAB_SUCCESS > %s; exit 3", outFile)
+			require.Equal(t, wantSuccess, runVerify(t, workspaceRoot, scenarioName, sc).Success)
+
+			data, err := os.ReadFile(outFile)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("%v\n", wantSuccess), string(data))
+		})
+	}
+}
+
+func TestRunVerifyCommandsOkExit(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "commands-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Commands = []scenario.VerifyCommand{
+		{Cmd: "exit 2", OkExit: []int{0, 2}},
+		{Cmd: "exit 0"},
+	}
+
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success)
+	require.Len(t, report.Tests, 2)
+	require.True(t, report.Tests[0].Passed)
+
+	sc.Verify.Commands = []scenario.VerifyCommand{{Cmd: "exit 2"}}
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Equal(t, "exit status 2 (ok: 0)", report.Tests[0].Error)
+}
+
+func TestRunMustBeExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes have no execute bit on windows")
+	}
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "executable-scenario"
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "allowed/run.sh", "#!/bin/sh\necho hi\n")
+	script := filepath.Join(repo, "allowed", "run.sh")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.MustBeExecutable = scenario.StringList{"allowed/run.sh"}
+
+	require.NoError(t, os.Chmod(script, 0o644))
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 1)
+	require.Equal(t, "verify.must-be-executable", report.Tests[0].Name)
+	require.Equal(t, "allowed/run.sh is not executable (mode -rw-r--r--)", report.Tests[0].Error)
+
+	require.NoError(t, os.Chmod(script, 0o755))
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success)
+	require.True(t, report.Tests[0].Passed)
+
+	sc.Verify.MustBeExecutable = scenario.StringList{"allowed/missing.sh"}
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Equal(t, "allowed/missing.sh does not exist", report.Tests[0].Error)
+}
+
+func TestRunNoStdoutNoise(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "stdout-noise-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "p/p.go", "package p\n\nimport \"fmt\"\n\nfunc Add(a, b int) int {\n\tfmt.Println(\"debug: adding\", a, b)\n\treturn a + b\n}\n")
+	writeFile(t, repo, "p/p_test.go", "package p\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"bad sum\")\n\t}\n}\n\nfunc TestLogs(t *testing.T) { t.Log(\"t.Log output is fine\") }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./p"}
+	sc.Verify.NoStdoutNoise = true
+
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 2)
+	require.True(t, report.Tests[0].Passed)
+	require.Equal(t, "go test -json ./p", report.Tests[0].Command)
+	noise := report.Tests[1]
+	require.Equal(t, "verify.no-stdout-noise", noise.Name)
+	require.False(t, noise.Passed)
+	require.Equal(t, "1 unexpected output line(s) from passing tests:\nexample.com/m/p TestAdd: debug: adding 1 2", noise.Error)
+
+	writeFile(t, repo, "p/p.go", "package p\n\nfunc Add(a, b int) int { return a + b }\n")
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success)
+	require.True(t, report.Tests[1].Passed)
+}
+
+func TestRunRaceMode(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "race-mode-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{"p/p_test.go": `package p
+
+import "testing"
+
+func TestRace(t *testing.T) {
+	x := 0
+	done := make(chan bool)
+	go func() {
+		x = 1
+		done <- true
+	}()
+	x = 2
+	<-done
+	_ = x
+}
+
+func TestOK(t *testing.T) {}
+`})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	run := func(mode string, entries ...string) *types.VerificationReport {
+		if len(entries) == 0 {
+			entries = []string{"./p"}
+		}
+		sc := baseScenario(scenarioName)
+		sc.Verify.Tests = scenario.StringList(entries)
+		sc.Verify.PartialTests = scenario.StringList(entries)
+		sc.Verify.RaceMode = mode
+		return runVerify(t, workspaceRoot, scenarioName, sc)
+	}
+
+	report := run(scenario.RaceModeOff)
+	require.True(t, report.Success)
+	require.Empty(t, report.RaceMode)
+	require.Zero(t, report.Races)
+	require.Equal(t, "go test ./p", report.Tests[0].Command)
+
+	report = run(scenario.RaceModeWarn)
+	require.True(t, report.Success)
+	require.Equal(t, "warn", report.RaceMode)
+	require.Equal(t, 1, report.Races)
+	require.True(t, report.Tests[0].Passed)
+	require.Equal(t, 1, report.Tests[0].Races)
+	require.Equal(t, "go test -json -race ./p", report.Tests[0].Command)
+	require.NotNil(t, report.PartialScore)
+	require.Equal(t, 1.0, *report.PartialScore)
+	require.Contains(t, verify.SummaryString(report), "./p: PASS (1 data race(s))")
+	require.Contains(t, verify.SummaryString(report), "Data races: 1")
+
+	report = run(scenario.RaceModeFail)
+	require.False(t, report.Success)
+	require.Equal(t, "fail", report.RaceMode)
+	require.Equal(t, 1, report.Races)
+	require.False(t, report.Tests[0].Passed)
+	require.Equal(t, "go test -race ./p", report.Tests[0].Command)
+	require.NotNil(t, report.PartialScore)
+	require.Equal(t, 0.5, *report.PartialScore)
+
+	// With warn, a raced test that also fails an assertion still fails.
+	writeFile(t, repo, "p/assert_test.go", `package p
+
+import "testing"
+
+func TestRaceAndAssert(t *testing.T) {
+	TestRace(t)
+	t.Error("bad sum")
+}
+`)
+	report = run(scenario.RaceModeWarn)
+	require.False(t, report.Success)
+	require.False(t, report.Tests[0].Passed)
+	require.InDelta(t, 2.0/3, *report.PartialScore, 1e-9)
+	require.NoError(t, os.Remove(filepath.Join(repo, "p", "assert_test.go")))
+
+	// So does another package's build failure, even with a race in ./p.
+	writeFile(t, repo, "q/q.go", "package q\n\nfunc F() { undefined() }\n")
+	report = run(scenario.RaceModeWarn, "./...")
+	require.False(t, report.Success)
+	require.False(t, report.Tests[0].Passed)
+	require.Equal(t, 1, report.Races)
+}
+
+func TestRunPartialMatchedNoTests(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "partial-no-tests-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"p/p_test.go": "package p\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n\nfunc TestB(t *testing.T) {}\n",
+	})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.PartialTests = scenario.StringList{"./p -run TestA", "./p -run TestTypo"}
+
+	// Per-test scoring doesn't count the empty entry, so it only warns.
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success)
+	require.Len(t, report.PartialTests, 2)
+	require.True(t, report.PartialTests[0].Passed)
+	require.False(t, report.PartialTests[1].Passed)
+	require.Equal(t, "partial target matched no tests", report.PartialTests[1].Error)
+	require.Contains(t, verify.SummaryString(report), "- partial ./p -run TestTypo: FAIL\n  partial target matched no tests\n")
+
+	sc.Verify.PartialMinTests = 1
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	gate := report.Tests[len(report.Tests)-1]
+	require.Equal(t, "verify.partial-min-tests", gate.Name)
+	require.False(t, gate.Passed)
+	require.Equal(t, "partial-tests entries ran fewer than 1 test(s), so they are likely misconfigured: ./p -run TestTypo (0)", gate.Error)
+
+	sc.Verify.PartialTests = scenario.StringList{"./p"}
+	sc.Verify.PartialMinTests = 2
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success)
+	require.True(t, report.Tests[len(report.Tests)-1].Passed)
+}
+
+func TestRunGenerateGate(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "generate-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, map[string]string{
+		"m.go":            "package m\n\n//go:generate cp version.txt version_gen.txt\n",
+		"version.txt":     "1\n",
+		"version_gen.txt": "1\n",
+	})
+	writeFile(t, repo, "allowed/base.txt", "changed")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Generate = true
+
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.True(t, report.Success, verify.DetailedString(report))
+	require.Equal(t, "verify.generate", report.Tests[0].Name)
+
+	// The agent changed the generator's input without regenerating.
+	writeFile(t, repo, "version.txt", "2\n")
+	report = runVerify(t, workspaceRoot, scenarioName, sc)
+	require.False(t, report.Success)
+	require.Equal(t, "go generate would change: version_gen.txt", report.Tests[0].Error)
+	got, err := os.ReadFile(filepath.Join(repo, "version_gen.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1\n", string(got), "go generate must not touch the workspace")
+}
+
+func TestRunWithRunFilter(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "run-filter-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "p/p_test.go", "package p\n\nimport \"testing\"\n\nfunc TestGood(t *testing.T) {}\n\nfunc TestBroken(t *testing.T) { t.Fatal(\"broken\") }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./p -run TestBroken"}
+	run := func(filter string) (*types.VerificationReport, error) {
+		opts := verifyOptions(workspaceRoot, scenarioName)
+		opts.RunFilter = filter
+		res, err := verify.Run(context.Background(), opts, sc)
+		if err != nil {
+			return nil, err
+		}
+		return res.Report, nil
+	}
+
+	report, err := run("")
+	require.NoError(t, err)
+	require.False(t, report.Success)
+	require.Empty(t, report.RunFilter)
+
+	report, err = run("TestGood|TestMissing")
+	require.NoError(t, err)
+	require.True(t, report.Success)
+	require.Equal(t, "TestGood|TestMissing", report.RunFilter)
+	require.Equal(t, "go test ./p -run 'TestGood|TestMissing'", report.Tests[0].Command)
+	require.Contains(t, verify.SummaryString(report), "Run filter: -run 'TestGood|TestMissing' (only matching tests ran)")
+
+	sc.Verify.Tests = scenario.StringList{"./..."}
+	_, err = run("TestGood")
+	require.ErrorContains(t, err, `--run: verify.tests entry "./...": package pattern "./..." cannot be combined with -run`)
+}
+
+func TestCheckReproduced(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+	scenarioRoot := t.TempDir()
+	t.Setenv(workspace.EnvVarScenarioRoot, scenarioRoot)
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "reproduced-scenario"
+	writeFile(t, filepath.Join(scenarioRoot, scenarioName), "hidden_test.go", "package p\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"bad sum\")\n\t}\n}\n")
+	repo := initIntegrationRepo(t, workspaceRoot, scenarioName)
+	writeFile(t, repo, "go.mod", "module example.com/m\n\ngo 1.21\n")
+	writeFile(t, repo, "p/p.go", "package p\n\nfunc Add(a, b int) int { return a - b }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Copy = []scenario.CopyStep{{From: "hidden_test.go", To: "p"}}
+	sc.Verify.Tests = scenario.StringList{"./p"}
+	check := func() error {
+		return verify.CheckReproduced(context.Background(), output.NewPrinter(nil), scenarioName, workspaceRoot, sc)
+	}
+
+	// Without sees-failing-tests: false the check is skipped, even though the tests would pass.
+	writeFile(t, repo, "p/p.go", "package p\n\nfunc Add(a, b int) int { return a + b }\n")
+	require.NoError(t, check())
+
+	hidden := false
+	sc.Classification.SeesFailingTests = &hidden
+	require.ErrorContains(t, check(), "all 1 test entries already pass before the agent runs")
+
+	writeFile(t, repo, "p/p.go", "package p\n\nfunc Add(a, b int) int { return a - b }\n")
+	require.NoError(t, check())
+	require.NoFileExists(t, filepath.Join(repo, "p", "hidden_test.go"))
+
+	// Tests run with verify's go test config: a fixed but slow package is reproduced by verify.test-timeout.
+	writeFile(t, repo, "p/p.go", "package p\n\nimport \"time\"\n\nfunc Add(a, b int) int {\n\ttime.Sleep(10 * time.Second)\n\treturn a + b\n}\n")
+	sc.Verify.TestTimeout = time.Second
+	require.NoError(t, check())
+
+	// A target that can't run here would only build the tests, so the check is skipped.
+	sc.Verify.GOOS = "windows"
+	if runtime.GOOS == "windows" {
+		sc.Verify.GOOS = "linux"
+	}
+	writeFile(t, repo, "p/p.go", "package p\n\nfunc Add(a, b int) int { return a + b }\n")
+	require.NoError(t, check())
+}
+
+func TestRunVerifyTimeout(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv(output.EnvVarKillGrace, "200ms")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "timeout-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "fast/fast_test.go", "package fast\n\nimport \"testing\"\n\nfunc TestFast(t *testing.T) {}\n")
+	writeFile(t, repo, "slow/slow_test.go", "package slow\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nfunc TestSlow(t *testing.T) { time.Sleep(time.Minute) }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./fast", "./slow", "./fast -run TestFast"}
+	sc.Verify.Commands = []scenario.VerifyCommand{{Cmd: "true"}}
+	sc.Verify.Timeout = 5 * time.Second
+
+	start := time.Now()
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.Less(t, time.Since(start), 30*time.Second)
+
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 5)
+	require.True(t, report.Tests[0].Passed, report.Tests[0].Error)
+	require.False(t, report.Tests[1].Passed)
+	require.Contains(t, report.Tests[1].Error, "killed: verify.timeout exceeded (5s)")
+	require.Equal(t, "not run: verify.timeout exceeded (5s)", report.Tests[2].Error)
+	require.Equal(t, "true", report.Tests[3].Name)
+	require.Equal(t, "not run: verify.timeout exceeded (5s)", report.Tests[3].Error)
+	require.Equal(t, "verify.timeout", report.Tests[4].Name)
+	require.False(t, report.Tests[4].Passed)
+}
+
+func TestRunTestTimeout(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv(output.EnvVarKillGrace, "200ms")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "test-timeout-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	writeFile(t, repo, "fast/fast_test.go", "package fast\n\nimport \"testing\"\n\nfunc TestFast(t *testing.T) {}\n")
+	writeFile(t, repo, "slow/slow_test.go", "package slow\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nfunc TestOK(t *testing.T) {}\n\nfunc TestSlow(t *testing.T) { time.Sleep(time.Minute) }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./slow", "./fast"}
+	sc.Verify.PartialTests = scenario.StringList{"./slow"}
+	sc.Verify.TestTimeout = 3 * time.Second
+
+	start := time.Now()
+	report := runVerify(t, workspaceRoot, scenarioName, sc)
+	require.Less(t, time.Since(start), 30*time.Second)
+
+	require.False(t, report.Success)
+	require.Len(t, report.Tests, 2)
+	require.False(t, report.Tests[0].Passed)
+	require.Equal(t, "test timed out after 3s", report.Tests[0].Error)
+	// Unlike verify.timeout, later entries still run.
+	require.True(t, report.Tests[1].Passed, report.Tests[1].Error)
+
+	require.Len(t, report.PartialTests, 1)
+	require.Equal(t, "test timed out after 3s", report.PartialTests[0].Error)
+	require.NotNil(t, report.PartialScore)
+	require.InDelta(t, 0.5, *report.PartialScore, 1e-9)
+}
+
+func TestRunParallelism(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "parallel-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	// a and b each wait for the other to start, so they only pass when run at the same time.
+	markers := t.TempDir()
+	rendezvous := func(pkg, self, peer string) string {
+		return fmt.Sprintf(`package %s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRendezvous(t *testing.T) {
+	if err := os.WriteFile(filepath.Join(%q, %q), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(filepath.Join(%q, %q)); err == nil {
+			return
+		}
+	}
+	t.Fatal("peer never started")
+}
+`, pkg, markers, self, markers, peer)
+	}
+	writeFile(t, repo, "a/a_test.go", rendezvous("a", "a", "b"))
+	writeFile(t, repo, "b/b_test.go", rendezvous("b", "b", "a"))
+	writeFile(t, repo, "c/c_test.go", "package c\n\nimport \"testing\"\n\nfunc TestFails(t *testing.T) { t.Fatal(\"boom\") }\n")
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./a", "./b", "./c"}
+
+	var out bytes.Buffer
+	opts := verifyOptions(workspaceRoot, scenarioName)
+	opts.Parallelism = 3
+	opts.Printer = output.NewPrinter(&out)
+	res, err := verify.Run(context.Background(), opts, sc)
+	require.NoError(t, err)
+
+	tests := res.Report.Tests
+	require.Len(t, tests, 3)
+	require.Equal(t, []string{"./a", "./b", "./c"}, []string{tests[0].Name, tests[1].Name, tests[2].Name})
+	require.True(t, tests[0].Passed, tests[0].Output)
+	require.True(t, tests[1].Passed, tests[1].Output)
+	require.False(t, tests[2].Passed)
+
+	// Each entry's command and output are printed together, in entry order.
+	printed := out.String()
+	var positions []int
+	for _, s := range []string{"go test ./a", "example.com/m/a", "go test ./b", "example.com/m/b", "go test ./c", "boom"} {
+		i := strings.Index(printed, s)
+		require.GreaterOrEqual(t, i, 0, "%q not printed", s)
+		positions = append(positions, i)
+	}
+	require.IsIncreasing(t, positions, printed)
+}
+
+func TestRunParallelismCancel(t *testing.T) {
+	t.Setenv("GOAGENTBENCH_SKIP_REMOTE", "1")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv(output.EnvVarKillGrace, "200ms")
+
+	workspaceRoot := t.TempDir()
+	scenarioName := "parallel-cancel-scenario"
+	repo := initGoRepo(t, workspaceRoot, scenarioName, nil)
+	writeFile(t, repo, "allowed/base.txt", "changed")
+	slow := "package %s\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nfunc TestSlow(t *testing.T) { time.Sleep(time.Minute) }\n"
+	writeFile(t, repo, "slow1/slow_test.go", fmt.Sprintf(slow, "slow1"))
+	writeFile(t, repo, "slow2/slow_test.go", fmt.Sprintf(slow, "slow2"))
+
+	sc := baseScenario(scenarioName)
+	sc.Verify.Tests = scenario.StringList{"./slow1", "./slow2"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	opts := verifyOptions(workspaceRoot, scenarioName)
+	opts.Parallelism = 2
+	res, err := verify.Run(ctx, opts, sc)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 30*time.Second)
+	require.Len(t, res.Report.Tests, 2)
+	require.False(t, res.Report.Tests[0].Passed)
+	require.False(t, res.Report.Tests[1].Passed)
 }
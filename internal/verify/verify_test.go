@@ -1,15 +1,22 @@
 package verify
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/codalotl/goagentbench/internal/output"
+	"github.com/codalotl/goagentbench/internal/scenario"
 	"github.com/codalotl/goagentbench/internal/types"
 )
 
@@ -87,3 +94,352 @@ func TestWriteReportRespectsResultsEnv(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, os.IsNotExist(err))
 }
+
+func TestAnnotationsString(t *testing.T) {
+	report := &types.VerificationReport{
+		Tests: []types.TestResult{
+			{
+				Name:   modificationRulesTestName,
+				Passed: false,
+				Output: "go.mod is blocked by verify.no-modify\nworkspace has no changes but verify.must-modify requires modifications",
+			},
+			{
+				Name:   "./pkg/foo -run TestFoo",
+				Passed: false,
+				Output: "--- FAIL: TestFoo (0.00s)\n    foo_test.go:12: want 1, got 2\nFAIL\n",
+				Error:  "exit status 1",
+			},
+			{Name: "./pkg/ok", Passed: true},
+			{Name: "./pkg/...", Passed: false, Error: "exit status 2"},
+		},
+		PartialTests: []types.TestResult{
+			{
+				Name:   "./bar",
+				Passed: false,
+				Output: `{"Action":"output","Test":"TestBar","Output":"    bar_test.go:7: 50% off, not: ok\n"}` + "\n",
+			},
+		},
+	}
+
+	require.Equal(t, ""+
+		"::error file=go.mod,title=verify.modification-rules::go.mod is blocked by verify.no-modify\n"+
+		"::error title=verify.modification-rules::workspace has no changes but verify.must-modify requires modifications\n"+
+		"::error file=pkg/foo/foo_test.go,line=12,title=./pkg/foo -run TestFoo::want 1, got 2\n"+
+		"::error title=./pkg/...::exit status 2\n"+
+		"::error file=bar/bar_test.go,line=7,title=./bar::50%25 off, not: ok\n",
+		AnnotationsString(report))
+	require.Empty(t, AnnotationsString(&types.VerificationReport{Tests: []types.TestResult{{Name: "./ok", Passed: true}}}))
+}
+
+func TestScorePartialModes(t *testing.T) {
+	// One entry with many subtests (9/10) and one with a single failing test (0/1).
+	counts := []partialCount{{passed: 9, total: 10, weight: 1}, {passed: 0, total: 1, weight: 1}}
+
+	assert.InDelta(t, 9.0/11.0, scorePartial("", counts), 1e-9)
+	assert.InDelta(t, 9.0/11.0, scorePartial(scenario.PartialModePerTest, counts), 1e-9)
+	assert.InDelta(t, 0.45, scorePartial(scenario.PartialModePerEntry, counts), 1e-9)
+
+	// Entries that ran no tests count as 0 in per-entry mode.
+	counts = []partialCount{{passed: 2, total: 2, weight: 1}, {passed: 0, total: 0, weight: 1}}
+	assert.InDelta(t, 1, scorePartial(scenario.PartialModePerTest, counts), 1e-9)
+	assert.InDelta(t, 0.5, scorePartial(scenario.PartialModePerEntry, counts), 1e-9)
+
+	// Weights scale an entry's tests (per-test) or its fraction (per-entry); weight 0 ignores the entry.
+	counts = []partialCount{{passed: 9, total: 10, weight: 1}, {passed: 0, total: 1, weight: 9}}
+	assert.InDelta(t, 9.0/19.0, scorePartial(scenario.PartialModePerTest, counts), 1e-9)
+	assert.InDelta(t, 0.09, scorePartial(scenario.PartialModePerEntry, counts), 1e-9)
+	counts = []partialCount{{passed: 9, total: 10, weight: 1}, {passed: 0, total: 1, weight: 0}}
+	assert.InDelta(t, 0.9, scorePartial(scenario.PartialModePerTest, counts), 1e-9)
+	assert.InDelta(t, 0.9, scorePartial(scenario.PartialModePerEntry, counts), 1e-9)
+	assert.Zero(t, scorePartial(scenario.PartialModePerEntry, []partialCount{{passed: 1, total: 1, weight: 0}}))
+
+	assert.Zero(t, scorePartial(scenario.PartialModePerTest, nil))
+	assert.Zero(t, scorePartial(scenario.PartialModePerEntry, nil))
+}
+
+func TestParseJSONCountsUnfinishedTests(t *testing.T) {
+	output := `{"Action":"run","Package":"ex/p","Test":"TestA"}
+{"Action":"pass","Package":"ex/p","Test":"TestA"}
+{"Action":"run","Package":"ex/p","Test":"TestB"}
+{"Action":"skip","Package":"ex/p","Test":"TestB"}
+{"Action":"run","Package":"ex/p","Test":"TestHang"}
+{"Action":"output","Package":"ex/p","Test":"TestHang","Output":"=== RUN   TestHang\n"}
+`
+	passed, total := parseJSONCounts(output)
+	assert.Equal(t, 1, passed)
+	assert.Equal(t, 2, total)
+}
+
+func TestRaceFailures(t *testing.T) {
+	// TestRace failed for a race, TestParent only through its raced subtest, and TestBroken on its own.
+	output := `{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"    testing.go:1617: race detected during execution of test\n"}
+{"Action":"fail","Package":"ex/p","Test":"TestRace"}
+{"Action":"output","Package":"ex/p","Test":"TestParent/sub","Output":"    testing.go:1617: race detected during execution of test\n"}
+{"Action":"fail","Package":"ex/p","Test":"TestParent/sub"}
+{"Action":"fail","Package":"ex/p","Test":"TestParent"}
+{"Action":"pass","Package":"ex/p","Test":"TestOK"}
+{"Action":"fail","Package":"ex/p"}
+`
+	explained, onlyRaces := raceFailures(output)
+	assert.Equal(t, 3, explained)
+	assert.True(t, onlyRaces)
+
+	broken := output + `{"Action":"output","Package":"ex/p","Test":"TestBroken","Output":"    p_test.go:9: bad sum\n"}
+{"Action":"fail","Package":"ex/p","Test":"TestBroken"}
+`
+	explained, onlyRaces = raceFailures(broken)
+	assert.Equal(t, 3, explained)
+	assert.False(t, onlyRaces)
+
+	// Without a race report (ex: a build failure), nothing is explained.
+	explained, onlyRaces = raceFailures(`{"Action":"fail","Package":"ex/p"}` + "\n")
+	assert.Zero(t, explained)
+	assert.False(t, onlyRaces)
+
+	// A failed package without failed tests or a race outside tests (ex: another package's build failure, or os.Exit in
+	// TestMain) isn't explained by a race elsewhere.
+	explained, onlyRaces = raceFailures(output + `{"Action":"output","Package":"ex/q","Output":"FAIL\tex/q [build failed]\n","OutputType":"frame"}
+{"Action":"fail","Package":"ex/q","FailedBuild":"ex/q"}
+`)
+	assert.Equal(t, 3, explained)
+	assert.False(t, onlyRaces)
+
+	explained, onlyRaces = raceFailures(output + `{"Action":"output","Package":"ex/q","Output":"panic: init failed\n"}
+{"Action":"output","Package":"ex/q","Output":"testing: race detected outside of test execution\n"}
+{"Action":"fail","Package":"ex/q"}
+`)
+	assert.Equal(t, 3, explained)
+	assert.False(t, onlyRaces)
+
+	explained, onlyRaces = raceFailures(output + `{"Action":"output","Package":"ex/q","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"ex/q","Output":"testing: race detected outside of test execution\n"}
+{"Action":"fail","Package":"ex/q"}
+`)
+	assert.Equal(t, 3, explained)
+	assert.True(t, onlyRaces)
+}
+
+func TestRaceFailuresTypedOutput(t *testing.T) {
+	// With go1.25+ output types, t.Log lines don't count as failures, but t.Error lines do.
+	output := `{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"=== RUN   TestRace\n","OutputType":"frame"}
+{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"    p_test.go:4: just logging\n"}
+{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"    testing.go:1865: race detected during execution of test\n","OutputType":"error"}
+{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"--- FAIL: TestRace (0.00s)\n","OutputType":"frame"}
+{"Action":"fail","Package":"ex/p","Test":"TestRace"}
+{"Action":"output","Package":"ex/p","Output":"FAIL\tex/p\t0.01s\n","OutputType":"frame"}
+{"Action":"fail","Package":"ex/p"}
+`
+	explained, onlyRaces := raceFailures(output)
+	assert.Equal(t, 1, explained)
+	assert.True(t, onlyRaces)
+
+	// An assertion failure in a test that also raced is a real failure.
+	asserted := strings.Replace(output, `{"Action":"fail","Package":"ex/p","Test":"TestRace"}`,
+		`{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"    p_test.go:9: bad sum\n","OutputType":"error"}
+{"Action":"fail","Package":"ex/p","Test":"TestRace"}`, 1)
+	explained, onlyRaces = raceFailures(asserted)
+	assert.Zero(t, explained)
+	assert.False(t, onlyRaces)
+
+	// So is a panic.
+	panicked := strings.Replace(output, `{"Action":"fail","Package":"ex/p","Test":"TestRace"}`,
+		`{"Action":"output","Package":"ex/p","Test":"TestRace","Output":"panic: boom [recovered]\n"}
+{"Action":"fail","Package":"ex/p","Test":"TestRace"}`, 1)
+	explained, onlyRaces = raceFailures(panicked)
+	assert.Zero(t, explained)
+	assert.False(t, onlyRaces)
+}
+
+func TestShortDuration(t *testing.T) {
+	assert.Equal(t, "5m", shortDuration(5*time.Minute))
+	assert.Equal(t, "1h", shortDuration(time.Hour))
+	assert.Equal(t, "1h30m", shortDuration(90*time.Minute))
+	assert.Equal(t, "1m30s", shortDuration(90*time.Second))
+	assert.Equal(t, "3s", shortDuration(3*time.Second))
+}
+
+func TestGoToolchainVersionDropsExperiments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stub go is a shell script")
+	}
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "go"), []byte("#!/bin/sh\necho 'go1.22.3 X:nocoverageredesign'\n"), 0o755))
+	t.Setenv("PATH", binDir)
+
+	version := goToolchainVersion(context.Background(), t.TempDir())
+	require.Equal(t, "go1.22.3", version)
+	sc := &scenario.Scenario{AllowedGoVersions: ">=1.22,<1.23"}
+	require.NoError(t, checkAllowedGoVersions(sc, version))
+}
+
+func TestModFileSnapshotRestoresMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte("module example.com/m\n"), 0o600))
+	snap, err := snapshotModFile(path)
+	require.NoError(t, err)
+
+	// Simulate a go command that replaced the file with default permissions.
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.WriteFile(path, []byte("module example.com/other\n"), 0o644))
+	require.NoError(t, snap.restore())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/m\n", string(got))
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := &types.VerificationReport{
+		Scenario:   "demo",
+		VerifiedAt: time.Date(2025, 12, 3, 10, 0, 0, 0, time.UTC),
+		Tests: []types.TestResult{
+			{Name: modificationRulesTestName, Passed: false, Output: "a.go is blocked by verify.no-modify\nb.go is blocked by verify.no-modify"},
+			{Name: "./pkg", Passed: true, Output: "ok pkg\n"},
+		},
+		PartialTests: []types.TestResult{
+			{
+				Name:   "./partial",
+				Passed: false,
+				Output: `{"Action":"run","Package":"ex/partial","Test":"TestA"}
+{"Action":"output","Package":"ex/partial","Test":"TestA","Output":"    a_test.go:3: boom\n"}
+{"Action":"fail","Package":"ex/partial","Test":"TestA"}
+{"Action":"pass","Package":"ex/partial","Test":"TestB"}
+{"Action":"skip","Package":"ex/partial","Test":"TestC"}
+{"Action":"fail","Package":"ex/partial"}
+`,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, report))
+
+	var doc junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 5, doc.Tests)
+	assert.Equal(t, 2, doc.Failures)
+	require.Len(t, doc.Suites, 2)
+
+	main := doc.Suites[0]
+	assert.Equal(t, "2025-12-03T10:00:00", main.Timestamp)
+	require.Len(t, main.Cases, 2)
+	require.NotNil(t, main.Cases[0].Failure)
+	assert.Equal(t, "a.go is blocked by verify.no-modify", main.Cases[0].Failure.Message)
+	assert.Contains(t, main.Cases[0].Failure.Body, "b.go is blocked")
+	assert.Nil(t, main.Cases[1].Failure)
+
+	partial := doc.Suites[1]
+	require.Len(t, partial.Cases, 3)
+	assert.Equal(t, 1, partial.Skipped)
+	assert.Equal(t, "TestA", partial.Cases[0].Name)
+	assert.Equal(t, "ex/partial", partial.Cases[0].Classname)
+	require.NotNil(t, partial.Cases[0].Failure)
+	assert.Contains(t, partial.Cases[0].Failure.Body, "boom")
+	assert.Nil(t, partial.Cases[1].Failure)
+	assert.NotNil(t, partial.Cases[2].Skipped)
+}
+
+func TestFoldStages(t *testing.T) {
+	stages := []scenario.Stage{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	progress := &types.RunProgress{Stages: []types.StageResult{{Name: "a", Success: true}}}
+
+	report := &types.VerificationReport{Success: true}
+	foldStages(report, stages, progress)
+	// Stage b never finished, so the run fails even though the final stage passed.
+	require.False(t, report.Success)
+	require.Equal(t, []types.StageResult{{Name: "a", Success: true}, {Name: "b"}, {Name: "c", Success: true}}, report.Stages)
+	require.Contains(t, SummaryString(report), "- stage b: FAIL\n- stage c: PASS\n")
+
+	progress.Stages = append(progress.Stages, types.StageResult{Name: "b", Success: true})
+	report = &types.VerificationReport{Success: true}
+	foldStages(report, stages, progress)
+	require.True(t, report.Success)
+
+	report = &types.VerificationReport{Success: true}
+	foldStages(report, nil, progress)
+	require.Empty(t, report.Stages)
+}
+
+func TestCompareBaseline(t *testing.T) {
+	before, after := 1.0, 0.5
+	baseline := &types.VerificationReport{
+		PartialScore: &before,
+		Tests: []types.TestResult{
+			{Name: "./a", Passed: true},
+			{Name: "./b", Passed: true},
+			{Name: "./gone", Passed: true},
+		},
+		PartialTests: []types.TestResult{{Name: "TestX", Passed: true}},
+	}
+	report := &types.VerificationReport{
+		PartialScore: &after,
+		Tests: []types.TestResult{
+			{Name: "./a", Passed: true},
+			{Name: "./b", Passed: false},
+			{Name: "./new", Passed: true},
+		},
+		PartialTests: []types.TestResult{{Name: "TestX", Passed: false}},
+	}
+
+	diff := CompareBaseline(baseline, report)
+	require.Equal(t, []TestChange{
+		{Name: "./b", Before: "PASS", After: "FAIL"},
+		{Name: "./new", After: "PASS"},
+		{Name: "partial TestX", Before: "PASS", After: "FAIL"},
+		{Name: "./gone", Before: "PASS"},
+	}, diff.Changes)
+	require.True(t, diff.ScoreChanged())
+	require.Equal(t, "Baseline: 4 test outcome(s) changed\n"+
+		"- ./b: PASS -> FAIL\n"+
+		"- ./new: (missing) -> PASS\n"+
+		"- partial TestX: PASS -> FAIL\n"+
+		"- ./gone: PASS -> (missing)\n"+
+		"Partial score: 1 -> 0.5\n", diff.String())
+
+	same := CompareBaseline(baseline, baseline)
+	require.Empty(t, same.Changes)
+	require.Equal(t, "Baseline: no test outcomes changed\n", same.String())
+
+	path := filepath.Join(t.TempDir(), "base.verify.json")
+	data, err := json.Marshal(baseline)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	loaded, err := LoadReport(path)
+	require.NoError(t, err)
+	require.Empty(t, CompareBaseline(loaded, baseline).Changes)
+}
+
+func TestExplainTargets(t *testing.T) {
+	workspacePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspacePath, "demo", "pkg", "foo"), 0o755))
+	sc := &scenario.Scenario{Verify: scenario.VerifyConfig{
+		Tests: scenario.StringList{
+			"pkg/foo",
+			"pkg/...",
+			"pkg/foo -run 'TestA|TestB'",
+			"./pkg/foo -run=TestX",
+			"example.com/mod/other",
+		},
+		PartialTests: scenario.StringList{"pkg/foo/*_test.go"},
+		MustFail:     scenario.StringList{"pkg/foo -run TestBroken"},
+	}}
+	seed := int64(7)
+	var out bytes.Buffer
+	err := ExplainTargets(output.NewPrinter(&out), Options{ScenarioName: "demo", WorkspacePath: workspacePath, ShuffleSeed: &seed}, sc)
+	require.NoError(t, err)
+	got := out.String()
+
+	assert.Contains(t, got, "verify.tests[0]: pkg/foo\n  target: pkg/foo\n  run:    (all tests)\n  go test: go test -shuffle=7 ./pkg/foo\n")
+	assert.Contains(t, got, "verify.tests[1]: pkg/...\n  target: pkg/...\n  run:    (all tests)\n  go test: go test -shuffle=7 ./pkg/...\n")
+	assert.Contains(t, got, "verify.tests[2]: pkg/foo -run 'TestA|TestB'\n  target: pkg/foo\n  run:    TestA|TestB\n  go test: go test -shuffle=7 ./pkg/foo -run 'TestA|TestB'\n")
+	assert.Contains(t, got, "verify.tests[3]: ./pkg/foo -run=TestX\n  target: ./pkg/foo\n  run:    TestX\n  go test: go test -shuffle=7 ./pkg/foo -run=TestX\n")
+	assert.Contains(t, got, "verify.tests[4]: example.com/mod/other\n  target: example.com/mod/other\n  run:    (all tests)\n  go test: go test -shuffle=7 example.com/mod/other\n")
+	assert.Contains(t, got, "verify.partial-tests[0]: pkg/foo/*_test.go\n  target: pkg/foo/*_test.go\n  run:    (all tests)\n  go test: go test -json -shuffle=7 './pkg/foo/*_test.go'\n")
+	assert.Contains(t, got, "verify.must-fail[0]: pkg/foo -run TestBroken\n  target: pkg/foo\n  run:    TestBroken\n  go test: go test -json -shuffle=7 ./pkg/foo -run TestBroken\n")
+	assert.Contains(t, got, "Commands run in "+filepath.Join(workspacePath, "demo"))
+}
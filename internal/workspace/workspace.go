@@ -37,8 +37,20 @@ func CleanScenario(name string) (string, error) {
 	return clean, nil
 }
 
+// InstanceSep separates a scenario from one of its matrix instance IDs in a scenario name (ex: "mathx@func=Add"). An
+// instance shares its scenario's dir (scenario.yml and data files) but has its own workspace dir and results.
+const InstanceSep = "@"
+
+// SplitInstance splits name into its scenario and matrix instance ID. The ID is "" if name isn't an instance.
+func SplitInstance(name string) (string, string) {
+	scenario, instance, _ := strings.Cut(name, InstanceSep)
+	return scenario, instance
+}
+
+// ScenarioDir returns the dir holding name's scenario.yml. For a matrix instance, that's its scenario's dir.
 func ScenarioDir(name string) string {
-	return filepath.Join(scenarioRoot(), name)
+	scenario, _ := SplitInstance(name)
+	return filepath.Join(scenarioRoot(), scenario)
 }
 
 func ScenarioFile(name string) string {